@@ -1,11 +1,13 @@
 package command
 
 import (
+	"fmt"
 	"github.com/jmatsu/splitter/internal/config"
 	"github.com/pkg/errors"
 	"github.com/urfave/cli/v2"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // AddDeploymentConfig command create a template config file for deployment.
@@ -54,6 +56,11 @@ func AddDeploymentConfig(name string, aliases []string) *cli.Command {
 
 			conf := *config.CurrentConfig()
 
+			// the merged values would be written into one file
+			if sources := conf.Sources(); len(sources) > 1 {
+				return errors.New(fmt.Sprintf("this command does not support layered config files: %s", strings.Join(sources, ", ")))
+			}
+
 			if err := conf.AddDeployment(name, serviceName); err != nil {
 				return errors.Wrapf(err, "couldn't add a deployment configuration")
 			}
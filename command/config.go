@@ -0,0 +1,79 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/jmatsu/splitter/internal/config"
+	"github.com/jmatsu/splitter/task"
+	"github.com/urfave/cli/v2"
+)
+
+// Config command provides the utilities of the config file.
+func Config(name string, aliases []string) *cli.Command {
+	return &cli.Command{
+		Name:        name,
+		Aliases:     aliases,
+		Usage:       "Inspect your config file.",
+		Description: "You can get the schema of the config file and the values that splitter actually uses.",
+		Subcommands: []*cli.Command{
+			configSchema("schema", []string{}),
+			configShow("show", []string{}),
+		},
+	}
+}
+
+// configSchema command prints the JSON Schema of the config file that is generated from the config structs.
+func configSchema(name string, aliases []string) *cli.Command {
+	return &cli.Command{
+		Name:        name,
+		Aliases:     aliases,
+		Usage:       "Print the JSON Schema of the config file.",
+		Description: "The schema is generated from the code so that editors can validate your config file with the latest definitions.",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:     "document",
+				Usage:    "Print a commented YAML reference instead of JSON Schema.",
+				Required: false,
+				Value:    false,
+			},
+		},
+		Action: func(context *cli.Context) error {
+			schema := config.GenerateJSONSchema()
+
+			if context.Bool("document") {
+				_, err := fmt.Fprint(context.App.Writer, config.RenderSchemaDocument(schema))
+				return err
+			}
+
+			if bytes, err := json.MarshalIndent(schema, "", "  "); err != nil {
+				panic(err)
+			} else {
+				_, err := fmt.Fprintln(context.App.Writer, string(bytes))
+				return err
+			}
+		},
+	}
+}
+
+// configShow command prints the resolved values of the config file and where each value comes from.
+func configShow(name string, aliases []string) *cli.Command {
+	return &cli.Command{
+		Name:        name,
+		Aliases:     aliases,
+		Usage:       "Print the resolved values of the config file and their sources.",
+		Description: "Values are printed after format: expansion, the other value sources, environment variables of services and defaults are applied. Each value comes from a config file, a profile, an environment variable, a command-line option or the default. Secrets are masked.",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name: "name",
+				Aliases: []string{
+					"n",
+				},
+				Usage:    "deployment name in your configuration file. Repeat this option to show several deployments. Every deployment is shown if not given.",
+				Required: false,
+			},
+		},
+		Action: func(context *cli.Context) error {
+			return task.FormatResolvedConfig(context.StringSlice("name"))
+		},
+	}
+}
@@ -1,11 +1,20 @@
 package command
 
 import (
+	"fmt"
+	"github.com/jmatsu/splitter/internal/condition"
 	"github.com/jmatsu/splitter/internal/config"
+	"github.com/jmatsu/splitter/internal/history"
 	"github.com/jmatsu/splitter/internal/logger"
+	"github.com/jmatsu/splitter/internal/util"
 	"github.com/jmatsu/splitter/service"
 	"github.com/jmatsu/splitter/task"
+	"github.com/pkg/errors"
 	"github.com/urfave/cli/v2"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"path/filepath"
+	"time"
 )
 
 // Deploy command distributes your app to pre-defined services in your config file.
@@ -16,21 +25,30 @@ func Deploy(name string, aliases []string) *cli.Command {
 		Usage:       "Manage your apps' deployments with following the configuration.",
 		Description: "You can deploy your apps to supported services based on pre-defined service configuration.",
 		Flags: []cli.Flag{
-			&cli.StringFlag{
+			&cli.StringSliceFlag{
 				Name: "name",
 				Aliases: []string{
 					"n",
 				},
-				Usage:    "deployment name in your configuration file.",
-				Required: true,
+				Usage:    "deployment name in your configuration file. Repeat this option or separate names by commas to deploy to several deployments.",
+				Required: false,
 				EnvVars:  []string{config.ToEnvName("DEPLOYMENT_NAME")},
 			},
-			&cli.PathFlag{
+			&cli.StringSliceFlag{
+				Name: "group",
+				Aliases: []string{
+					"g",
+				},
+				Usage:    "group name in your configuration file. All deployments of the group will be deployed.",
+				Required: false,
+				EnvVars:  []string{config.ToEnvName("DEPLOYMENT_GROUP")},
+			},
+			&cli.StringSliceFlag{
 				Name: "source-path",
 				Aliases: []string{
 					"f",
 				},
-				Usage:    "A path to an app file.",
+				Usage:    "A path to an app file. Repeat this option or use a glob pattern like 'build/*.apk' to deploy several files.",
 				Required: true,
 			},
 			&cli.StringFlag{
@@ -39,63 +57,397 @@ func Deploy(name string, aliases []string) *cli.Command {
 				Required: false,
 				EnvVars:  []string{config.ToEnvName("DEPLOYMENT_RELEASE_NOTE")},
 			},
+			&cli.IntFlag{
+				Name:     "concurrency",
+				Usage:    "The maximum number of deployments that run in parallel.",
+				Required: false,
+				Value:    4,
+				EnvVars:  []string{config.ToEnvName("DEPLOYMENT_CONCURRENCY")},
+			},
+			dryRunFlag,
 		},
+		Before: configureDryRun,
 		Action: func(context *cli.Context) error {
-			name := context.String("name")
+			names := context.StringSlice("name")
+
+			for _, group := range context.StringSlice("group") {
+				members, err := config.CurrentConfig().Group(group)
+
+				if err != nil {
+					return err
+				}
+
+				for _, member := range members {
+					if !slices.Contains(names, member) {
+						names = append(names, member)
+					}
+				}
+			}
+
+			if len(names) == 0 {
+				return errors.New("at least one deployment name or group is required")
+			}
 
-			logger.Logger.Info().Msgf("Loading %s config...", name)
+			if n := context.Int("concurrency"); n < 1 {
+				return errors.New(fmt.Sprintf("concurrency must be positive but %d", n))
+			}
 
-			deployment, definition, err := config.CurrentConfig().Deployment(name)
+			sourceFilePaths, err := util.ExpandPaths(context.StringSlice("source-path"))
 
 			if err != nil {
 				return err
 			}
 
-			executor := task.NewExecutor(context.Context, nil, &deployment.Lifecycle)
+			summary := task.FanOut(names, context.Int("concurrency"), func(name string) error {
+				return deploy(context, name, sourceFilePaths)
+			})
+
+			if len(names) == 1 && !context.IsSet("group") {
+				// a single deployment has its own result unless it's skipped
+				if o := summary.Outcomes[0]; o.Status != task.DeploymentSkipped {
+					return o.Err()
+				}
+			}
+
+			if err := task.FormatFanOutSummary(summary); err != nil {
+				return errors.Wrap(err, "cannot format the summary")
+			}
 
-			return executor.Execute(func() error {
-				sourceFilePath := context.String("source-path")
+			return summary.Err()
+		},
+	}
+}
 
-				switch deployment.ServiceName {
-				case config.DeploygateService:
-					dg := deployment.ServiceConfig.(config.DeployGateConfig)
+func deploy(context *cli.Context, name string, sourceFilePaths []string) error {
+	logger.Logger.Info().Msgf("Loading %s config...", name)
 
-					return task.DeployToDeployGate(context.Context, dg, sourceFilePath, func(req *service.DeployGateDeployRequest) error {
-						if v := context.String("release-note"); context.IsSet("release-note") {
-							req.SetMessage(v)
-							req.SetDistributionReleaseNote(v)
-						}
+	deployment, definition, err := config.CurrentConfig().Deployment(name)
 
-						return nil
-					})
-				case config.LocalService:
-					lo := deployment.ServiceConfig.(config.LocalConfig)
+	if err != nil {
+		return err
+	}
 
-					return task.DeployToLocal(context.Context, lo, sourceFilePath)
-				case config.FirebaseAppDistributionService:
-					fad := deployment.ServiceConfig.(config.FirebaseAppDistributionConfig)
+	artifacts := map[int]string{}
+	var indices []int
+	var unchanged int
 
-					return task.DeployToFirebaseAppDistribution(context.Context, fad, sourceFilePath, func(req *service.FirebaseAppDistributionDeployRequest) error {
-						if v := context.String("release-note"); context.IsSet("release-note") {
-							req.SetReleaseNote(v)
-						}
+	for idx, sourceFilePath := range sourceFilePaths {
+		if when := deployment.Lifecycle.When; when != "" {
+			if satisfied, err := condition.Evaluate(when, condition.CurrentVariables(sourceFilePath)); err != nil {
+				return errors.Wrapf(err, "cannot evaluate the condition of %s", name)
+			} else if !satisfied {
+				logger.Logger.Info().Msgf("%s is excluded from %s because %s is not satisfied", sourceFilePath, name, when)
+				continue
+			}
+		}
 
-						return nil
-					})
-				case config.TestFlightService:
-					tf := deployment.ServiceConfig.(config.TestFlightConfig)
+		if deployment.Lifecycle.SkipIfUnchanged {
+			if same, err := isUnchanged(context, name, deployment, sourceFilePath); err != nil {
+				return errors.Wrapf(err, "cannot compare %s with the last deployment of %s", sourceFilePath, name)
+			} else if same {
+				logger.Logger.Info().Msgf("%s is excluded from %s because it is unchanged since the last successful deployment", sourceFilePath, name)
+				unchanged++
+				continue
+			}
+		}
 
-					return task.DeployToTestFlight(context.Context, tf, sourceFilePath, func(req *service.TestFlightDeployRequest) error {
-						return nil
-					})
-				default:
-					custom := deployment.ServiceConfig.(config.CustomServiceConfig)
+		artifacts[idx] = sourceFilePath
+		indices = append(indices, idx)
+	}
 
-					return task.DeployToCustomService(context.Context, definition, custom, sourceFilePath, func(req *service.CustomServiceDeployRequest) error {
-						return nil
-					})
-				}
+	if len(indices) == 0 {
+		if unchanged > 0 {
+			return &task.SkippedError{
+				Reason: "unchanged",
+			}
+		}
+
+		return &task.SkippedError{
+			Reason: fmt.Sprintf("%s is not satisfied", deployment.Lifecycle.When),
+		}
+	}
+
+	var groups []*localVersionGroup
+
+	if deployment.ServiceName == config.LocalService {
+		lo := deployment.ServiceConfig.(config.LocalConfig)
+
+		if lo.Versioning != nil {
+			if groups, err = groupLocalVersions(lo, artifacts); err != nil {
+				return errors.Wrapf(err, "%s cannot deploy the files", name)
+			}
+		} else if err := checkLocalDestinations(lo, artifacts); err != nil {
+			return errors.Wrapf(err, "%s cannot deploy the files", name)
+		}
+	}
+
+	executor := task.NewExecutor(context.Context, nil, &deployment.Lifecycle)
+
+	return executor.Execute(func() error {
+		if len(indices) == 1 {
+			return deployAndRecord(context, name, deployment, definition, artifacts[indices[0]], indices[0], "")
+		}
+
+		versions := map[int]string{}
+
+		for _, group := range groups {
+			for _, idx := range group.indices {
+				versions[idx] = group.version
+			}
+		}
+
+		var failures int
+		failed := map[int]bool{}
+
+		for _, idx := range indices {
+			if err := deployAndRecord(context, name, deployment, definition, artifacts[idx], idx, versions[idx]); err != nil {
+				logger.Logger.Error().Err(err).Msgf("failed to deploy %s to %s", artifacts[idx], name)
+				failures++
+				failed[idx] = true
+			}
+		}
+
+		var publishErr error
+
+		for _, group := range groups {
+			if err := publishLocalVersion(context, deployment.ServiceConfig.(config.LocalConfig), group, artifacts, failed); err != nil {
+				logger.Logger.Error().Err(err).Msgf("failed to publish version %s of %s", group.version, name)
+				publishErr = err
+			}
+		}
+
+		if failures > 0 {
+			return errors.New(fmt.Sprintf("%d of %d files failed to be deployed", failures, len(indices)))
+		}
+
+		return publishErr
+	})
+}
+
+// localVersionGroup is the files that are stored in one version of a versioned destination.
+type localVersionGroup struct {
+	destinationPath string
+	version         string
+	indices         []int
+}
+
+// Under versioning, the destination is a directory. The files deployed to the same directory share a version so that latest advances once per deployment.
+func groupLocalVersions(lo config.LocalConfig, artifacts map[int]string) ([]*localVersionGroup, error) {
+	var groups []*localVersionGroup
+
+	indices := maps.Keys(artifacts)
+	slices.Sort(indices)
+
+	version := service.NewLocalVersion(time.Now())
+
+	for _, idx := range indices {
+		destinationPath, err := lo.ResolveDestinationPath(artifacts[idx], idx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if i := slices.IndexFunc(groups, func(g *localVersionGroup) bool { return g.destinationPath == destinationPath }); i >= 0 {
+			other := artifacts[groups[i].indices[0]]
+
+			if filepath.Base(other) == filepath.Base(artifacts[idx]) {
+				return nil, errors.New(fmt.Sprintf("both of %s and %s are going to be stored as %s in %s", other, artifacts[idx], filepath.Base(other), destinationPath))
+			}
+
+			groups[i].indices = append(groups[i].indices, idx)
+		} else {
+			groups = append(groups, &localVersionGroup{
+				destinationPath: destinationPath,
+				version:         version,
+				indices:         []int{idx},
 			})
-		},
+		}
+	}
+
+	// a single file publishes its own version
+	return slices.DeleteFunc(groups, func(g *localVersionGroup) bool { return len(g.indices) < 2 }), nil
+}
+
+// publishLocalVersion points latest to the version once the files have been stored. The files that failed are not a part of the version.
+func publishLocalVersion(context *cli.Context, lo config.LocalConfig, group *localVersionGroup, artifacts map[int]string, failed map[int]bool) error {
+	var fileNames []string
+
+	for _, idx := range group.indices {
+		if !failed[idx] {
+			fileNames = append(fileNames, filepath.Base(artifacts[idx]))
+		}
+	}
+
+	if len(fileNames) == 0 {
+		return nil
+	}
+
+	lo.DestinationPath = group.destinationPath
+
+	return task.PublishLocal(context.Context, lo, group.version, fileNames)
+}
+
+// Each file must have its own destination. Otherwise, the files overwrite each other.
+func checkLocalDestinations(lo config.LocalConfig, artifacts map[int]string) error {
+	sources := map[string]string{}
+
+	indices := maps.Keys(artifacts)
+	slices.Sort(indices)
+
+	for _, idx := range indices {
+		sourceFilePath := artifacts[idx]
+		destinationPath, err := lo.ResolveDestinationPath(sourceFilePath, idx)
+
+		if err != nil {
+			return err
+		}
+
+		if other, found := sources[destinationPath]; found {
+			return errors.New(fmt.Sprintf("both of %s and %s are going to be deployed to %s. Use a template in destination-path", other, sourceFilePath, destinationPath))
+		}
+
+		sources[destinationPath] = sourceFilePath
+	}
+
+	return nil
+}
+
+// isUnchanged returns true if the file is same to the latest build of the deployment.
+// The latest release is compared for Firebase App Distribution except app bundles that are not served as they are. The history is used only if the release cannot be fetched.
+// Otherwise, the last successful deployment in the history is compared whatever its file name is. The last one of the same file name is compared as well so that a deployment of several files can be skipped.
+func isUnchanged(context *cli.Context, name string, deployment config.Deployment, sourceFilePath string) (bool, error) {
+	artifact, err := service.NewArtifact(sourceFilePath)
+
+	if err != nil {
+		return false, err
+	}
+
+	if deployment.ServiceName == config.FirebaseAppDistributionService && filepath.Ext(sourceFilePath) != ".aab" && !config.CurrentConfig().DryRun() {
+		if sha256, err := task.LatestFirebaseAppDistributionSHA256(context.Context, deployment.ServiceConfig.(config.FirebaseAppDistributionConfig), artifact.Size); err != nil {
+			logger.Logger.Warn().Err(err).Msgf("cannot get the latest release of %s. the history is used instead", name)
+		} else {
+			return sha256 == artifact.SHA256, nil
+		}
+	}
+
+	ledger := history.NewLedger(config.CurrentConfig().HistoryDir())
+
+	for _, find := range []func() (*history.Record, error){
+		func() (*history.Record, error) { return ledger.LastSucceededOf(name) },
+		func() (*history.Record, error) { return ledger.LastSucceeded(name, artifact.FileName) },
+	} {
+		if last, err := find(); err != nil {
+			return false, err
+		} else if last != nil && last.SHA256 == artifact.SHA256 {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// deployAndRecord deploys the file and appends the result to the history. Dry-run deployments are not recorded.
+func deployAndRecord(context *cli.Context, name string, deployment config.Deployment, definition config.CustomServiceDefinition, sourceFilePath string, index int, localVersion string) error {
+	startedAt := time.Now()
+
+	summary, err := deployArtifact(context, name, deployment, definition, sourceFilePath, index, localVersion)
+
+	if config.CurrentConfig().DryRun() {
+		return err
+	}
+
+	record := history.Record{
+		Deployment: name,
+		Service:    deployment.ServiceName,
+		Status:     history.Succeeded,
+		FileName:   filepath.Base(sourceFilePath),
+		StartedAt:  startedAt,
+		FinishedAt: time.Now(),
+	}
+
+	if err != nil {
+		record.Status = history.Failed
+		record.Error = err.Error()
+
+		if artifact, err := service.NewArtifact(sourceFilePath); err == nil {
+			summary = &service.DeploySummary{
+				Artifact: *artifact,
+			}
+		}
+	}
+
+	if summary != nil {
+		record.FileName = summary.Artifact.FileName
+		record.Size = summary.Artifact.Size
+		record.SHA256 = summary.Artifact.SHA256
+		record.PackageName = summary.PackageName
+		record.VersionName = summary.VersionName
+		record.VersionCode = summary.VersionCode
+		record.URLs = summary.URLs
+	}
+
+	ledger := history.NewLedger(config.CurrentConfig().HistoryDir())
+
+	if err := ledger.Append(record); err != nil {
+		logger.Logger.Warn().Err(err).Msgf("cannot record the deployment of %s", name)
+	}
+
+	return err
+}
+
+// localVersion is the version that the file shares with the other files. It's available only for versioning of local service.
+func deployArtifact(context *cli.Context, name string, deployment config.Deployment, definition config.CustomServiceDefinition, sourceFilePath string, index int, localVersion string) (*service.DeploySummary, error) {
+	switch deployment.ServiceName {
+	case config.DeploygateService:
+		dg := deployment.ServiceConfig.(config.DeployGateConfig)
+
+		return task.DeployToDeployGate(context.Context, dg, sourceFilePath, func(req *service.DeployGateDeployRequest) error {
+			if v := context.String("release-note"); context.IsSet("release-note") {
+				req.SetMessage(v)
+				req.SetDistributionReleaseNote(v)
+			}
+
+			return nil
+		})
+	case config.LocalService:
+		lo := deployment.ServiceConfig.(config.LocalConfig)
+
+		if destinationPath, err := lo.ResolveDestinationPath(sourceFilePath, index); err != nil {
+			return nil, err
+		} else {
+			lo.DestinationPath = destinationPath
+		}
+
+		return task.DeployToLocal(context.Context, lo, sourceFilePath, func(req *service.LocalDeployRequest) error {
+			req.SetDeploymentName(name)
+
+			if localVersion != "" {
+				req.SetVersion(localVersion)
+			}
+
+			return nil
+		})
+	case config.FirebaseAppDistributionService:
+		fad := deployment.ServiceConfig.(config.FirebaseAppDistributionConfig)
+
+		return task.DeployToFirebaseAppDistribution(context.Context, fad, sourceFilePath, func(req *service.FirebaseAppDistributionDeployRequest) error {
+			if v := context.String("release-note"); context.IsSet("release-note") {
+				req.SetReleaseNote(v)
+			}
+
+			return nil
+		})
+	case config.TestFlightService:
+		tf := deployment.ServiceConfig.(config.TestFlightConfig)
+
+		return task.DeployToTestFlight(context.Context, tf, sourceFilePath, func(req *service.TestFlightDeployRequest) error {
+			return nil
+		})
+	default:
+		custom := deployment.ServiceConfig.(config.CustomServiceConfig)
+
+		return task.DeployToCustomService(context.Context, definition, custom, sourceFilePath, func(req *service.CustomServiceDeployRequest) error {
+			return nil
+		})
 	}
 }
@@ -70,14 +70,16 @@ func DeployGate(name string, aliases []string) *cli.Command {
 				Usage:    "Specify this file if you would like to disable notifications for iOS.",
 				Required: false,
 			},
+			dryRunFlag,
 		},
+		Before: configureDryRun,
 		Action: func(context *cli.Context) error {
 			conf := config.DeployGateConfig{
 				AppOwnerName: context.String("app-owner-name"),
 				ApiToken:     context.String("api-token"),
 			}
 
-			return task.DeployToDeployGate(context.Context, conf, context.String("source-path"), func(req *service.DeployGateDeployRequest) error {
+			_, err := task.DeployToDeployGate(context.Context, conf, context.String("source-path"), func(req *service.DeployGateDeployRequest) error {
 				if v := context.String("message"); context.IsSet("message") {
 					req.SetMessage(v)
 				}
@@ -98,6 +100,8 @@ func DeployGate(name string, aliases []string) *cli.Command {
 
 				return nil
 			})
+
+			return err
 		},
 	}
 }
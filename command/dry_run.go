@@ -0,0 +1,23 @@
+package command
+
+import (
+	"github.com/jmatsu/splitter/internal/config"
+	"github.com/urfave/cli/v2"
+)
+
+// dryRunFlag is shared by commands that deploy apps.
+var dryRunFlag = &cli.BoolFlag{
+	Name:     "dry-run",
+	Usage:    "Print the requests and steps that would be executed without sending or running anything.",
+	Required: false,
+	Value:    false,
+	EnvVars:  []string{config.ToEnvName("DRY_RUN")},
+}
+
+func configureDryRun(context *cli.Context) error {
+	if context.Bool(dryRunFlag.Name) {
+		config.SetGlobalDryRun(true)
+	}
+
+	return nil
+}
@@ -60,19 +60,28 @@ func FirebaseAppDistribution(name string, aliases []string) *cli.Command {
 				Usage:    "Emails of testers. Separate multiple aliases by commas.",
 				Required: false,
 			},
+			&cli.StringFlag{
+				Name:        "upload-chunk-size",
+				Usage:       "The size of each chunk of resumable uploads. This must be a multiple of 256KiB.",
+				Required:    false,
+				DefaultText: config.DefaultFirebaseUploadChunkSize,
+			},
+			dryRunFlag,
 		},
+		Before: configureDryRun,
 		Action: func(context *cli.Context) error {
 			conf := config.FirebaseAppDistributionConfig{
 				AccessToken:           context.String("access-token"),
 				GoogleCredentialsPath: context.String("credentials"),
 				AppId:                 context.String("app-id"),
+				UploadChunkSize:       context.String("upload-chunk-size"),
 			}
 
 			if v := strings.Split(context.String("group-aliases"), ","); context.IsSet("group-aliases") && len(v) > 0 {
 				conf.GroupAliases = v
 			}
 
-			return task.DeployToFirebaseAppDistribution(context.Context, conf, context.String("source-path"), func(req *service.FirebaseAppDistributionDeployRequest) error {
+			_, err := task.DeployToFirebaseAppDistribution(context.Context, conf, context.String("source-path"), func(req *service.FirebaseAppDistributionDeployRequest) error {
 				if v := context.String("release-note"); context.IsSet("release-note") {
 					req.SetReleaseNote(v)
 				}
@@ -83,6 +92,8 @@ func FirebaseAppDistribution(name string, aliases []string) *cli.Command {
 
 				return nil
 			})
+
+			return err
 		},
 	}
 }
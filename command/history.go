@@ -0,0 +1,97 @@
+package command
+
+import (
+	"fmt"
+	"github.com/jmatsu/splitter/internal/config"
+	"github.com/jmatsu/splitter/internal/history"
+	"github.com/jmatsu/splitter/task"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/exp/slices"
+)
+
+// History command lists past deployments that are recorded by deploy command.
+func History(name string, aliases []string) *cli.Command {
+	return &cli.Command{
+		Name:        name,
+		Aliases:     aliases,
+		Usage:       "List past deployments.",
+		Description: "You can see past deployments recorded by deploy command. The latest deployment comes first.",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name: "name",
+				Aliases: []string{
+					"n",
+				},
+				Usage:    "Show only the deployments of the name. Repeat this option to show several deployments.",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "status",
+				Usage:    fmt.Sprintf("Show only the deployments of the status. (Values: %s, %s)", history.Succeeded, history.Failed),
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "since",
+				Usage:    "Show only the deployments started at or after the date. YYYY-MM-DD or RFC3339 timestamp.",
+				Required: false,
+			},
+			&cli.StringFlag{
+				Name:     "until",
+				Usage:    "Show only the deployments started before the date. YYYY-MM-DD or RFC3339 timestamp.",
+				Required: false,
+			},
+			&cli.IntFlag{
+				Name:        "limit",
+				Usage:       "The maximum number of deployments to show.",
+				Required:    false,
+				DefaultText: "unlimited",
+			},
+		},
+		Action: func(context *cli.Context) error {
+			filter := history.Filter{
+				Deployments: context.StringSlice("name"),
+				Status:      context.String("status"),
+			}
+
+			if s := filter.Status; s != "" && !slices.Contains([]history.Status{history.Succeeded, history.Failed}, s) {
+				return errors.New(fmt.Sprintf("%s is unknown status", s))
+			}
+
+			if v := context.String("since"); context.IsSet("since") {
+				if t, err := history.ParseTime(v); err != nil {
+					return errors.Wrap(err, "--since is invalid")
+				} else {
+					filter.Since = t
+				}
+			}
+
+			if v := context.String("until"); context.IsSet("until") {
+				if t, err := history.ParseTime(v); err != nil {
+					return errors.Wrap(err, "--until is invalid")
+				} else {
+					filter.Until = t
+				}
+			}
+
+			if n := context.Int("limit"); n < 0 {
+				return errors.New(fmt.Sprintf("limit must not be negative but %d", n))
+			}
+
+			records, err := history.NewLedger(config.CurrentConfig().HistoryDir()).Records()
+
+			if err != nil {
+				return err
+			}
+
+			records = filter.Apply(records)
+			slices.Reverse(records)
+
+			if n := context.Int("limit"); n > 0 && len(records) > n {
+				records = records[:n]
+			}
+
+			return task.FormatHistory(records)
+		},
+	}
+}
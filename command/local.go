@@ -1,8 +1,11 @@
 package command
 
 import (
+	"fmt"
 	"github.com/jmatsu/splitter/internal/config"
+	"github.com/jmatsu/splitter/service"
 	"github.com/jmatsu/splitter/task"
+	"github.com/pkg/errors"
 	"github.com/urfave/cli/v2"
 	"os"
 )
@@ -20,13 +23,13 @@ func Local(name string, aliases []string) *cli.Command {
 				Aliases: []string{
 					"f",
 				},
-				Usage:    "A source path to an app file.",
-				Required: true,
+				Usage:    "A source path to an app file. (required)",
+				Required: false, // not to require this for subcommands
 			},
 			&cli.PathFlag{
 				Name:     "destination-path",
-				Usage:    "A destination path to an app file.",
-				Required: true,
+				Usage:    "A destination path to an app file. This can be a template like 'dist/{{.PackageName}}/{{.VersionName}}-{{.ShortSHA}}{{.Ext}}'. (required)",
+				Required: false, // not to require this for subcommands
 			},
 			&cli.BoolFlag{
 				Name:     "delete-source",
@@ -47,16 +50,133 @@ func Local(name string, aliases []string) *cli.Command {
 				Value:       0,
 				DefaultText: "Same to the source",
 			},
+			&cli.UintFlag{
+				Name:        "dir-mode",
+				Usage:       "The permission of parent directories that are created for the destination path.",
+				Required:    false,
+				Value:       0,
+				DefaultText: "0755",
+			},
+			&cli.BoolFlag{
+				Name:     "checksum-file",
+				Usage:    "Specify true if you would like to write <destination>.sha256 next to the destination file.",
+				Required: false,
+				Value:    false,
+			},
+			&cli.BoolFlag{
+				Name:     "metadata-file",
+				Usage:    "Specify true if you would like to write <destination>.json that contains the source, the hash and CI metadata.",
+				Required: false,
+				Value:    false,
+			},
+			&cli.StringFlag{
+				Name:     "install-page-base-url",
+				Usage:    "Write an install page next to the destination file. Specify the URL that serves the destination directory.",
+				Required: false,
+			},
+			dryRunFlag,
+		},
+		Before: configureDryRun,
+		Subcommands: []*cli.Command{
+			localRollback("rollback", []string{}),
 		},
 		Action: func(context *cli.Context) error {
+			for _, name := range []string{"source-path", "destination-path"} {
+				if !context.IsSet(name) {
+					return errors.New(fmt.Sprintf("--%s is required", name))
+				}
+			}
+
 			conf := config.LocalConfig{
 				DestinationPath: context.String("destination-path"),
 				DeleteSource:    context.Bool("delete-source"),
 				AllowOverwrite:  context.Bool("overwrite"),
 				FileMode:        os.FileMode(context.Uint("file-mode")),
+				DirMode:         os.FileMode(context.Uint("dir-mode")),
+				ChecksumFile:    context.Bool("checksum-file"),
+				MetadataFile:    context.Bool("metadata-file"),
+			}
+
+			if context.IsSet("install-page-base-url") {
+				conf.InstallPage = &config.LocalInstallPageConfig{
+					BaseURL: context.String("install-page-base-url"),
+				}
+			}
+
+			sourceFilePath := context.String("source-path")
+
+			if destinationPath, err := conf.ResolveDestinationPath(sourceFilePath, 0); err != nil {
+				return err
+			} else {
+				conf.DestinationPath = destinationPath
+			}
+
+			_, err := task.DeployToLocal(context.Context, conf, sourceFilePath, func(req *service.LocalDeployRequest) error {
+				return nil
+			})
+
+			return err
+		},
+	}
+}
+
+// localRollback command points latest of a versioned local deployment to the previous version.
+func localRollback(name string, aliases []string) *cli.Command {
+	return &cli.Command{
+		Name:        name,
+		Aliases:     aliases,
+		Usage:       "Point latest of a versioned local deployment to the previous version.",
+		Description: "You can roll back a local deployment that enables versioning in your config file. Running this command repeatedly goes back further.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name: "name",
+				Aliases: []string{
+					"n",
+				},
+				Usage:    "A deployment name of local service in your configuration file.",
+				Required: true,
+			},
+			&cli.PathFlag{
+				Name: "source-path",
+				Aliases: []string{
+					"f",
+				},
+				Usage:    "A source path to resolve destination-path if it's a template.",
+				Required: false,
+			},
+			dryRunFlag,
+		},
+		Before: configureDryRun,
+		Action: func(context *cli.Context) error {
+			name := context.String("name")
+
+			deployment, _, err := config.CurrentConfig().Deployment(name)
+
+			if err != nil {
+				return err
+			}
+
+			if deployment.ServiceName != config.LocalService {
+				return errors.New(fmt.Sprintf("%s is not a deployment of %s service", name, config.LocalService))
+			}
+
+			conf := deployment.ServiceConfig.(config.LocalConfig)
+
+			if conf.Versioning == nil {
+				return errors.New(fmt.Sprintf("%s does not enable versioning", name))
+			}
+
+			if conf.HasDestinationTemplate() && !context.IsSet("source-path") {
+				return errors.New(fmt.Sprintf("destination-path of %s is a template so --source-path is required", name))
+			}
+
+			if destinationPath, err := conf.ResolveDestinationPath(context.String("source-path"), 0); err != nil {
+				return err
+			} else {
+				conf.DestinationPath = destinationPath
 			}
 
-			return task.DeployToLocal(context.Context, conf, context.String("source-path"))
+			return task.RollbackLocal(context.Context, conf)
 		},
 	}
 }
@@ -57,7 +57,9 @@ func CustomService(name string, aliases []string) *cli.Command {
 				Usage:    "Append <key>=<value> to form parameters",
 				Required: false,
 			},
+			dryRunFlag,
 		},
+		Before: configureDryRun,
 		Action: func(context *cli.Context) error {
 			conf := config.CustomServiceConfig{
 				AuthToken: context.String("auth-token"),
@@ -69,7 +71,7 @@ func CustomService(name string, aliases []string) *cli.Command {
 				return errors.Wrapf(err, "cannot get a definition")
 			}
 
-			return task.DeployToCustomService(context.Context, def, conf, context.String("source-path"), func(req *service.CustomServiceDeployRequest) error {
+			_, err = task.DeployToCustomService(context.Context, def, conf, context.String("source-path"), func(req *service.CustomServiceDeployRequest) error {
 				if headers := context.StringSlice("header"); context.IsSet("header") {
 					for _, header := range headers {
 						if name, value, ok := strings.Cut(header, "="); ok {
@@ -104,6 +106,8 @@ func CustomService(name string, aliases []string) *cli.Command {
 
 				return nil
 			})
+
+			return err
 		},
 	}
 }
@@ -50,7 +50,9 @@ func TestFlight(name string, aliases []string) *cli.Command {
 				Required: false,
 				EnvVars:  []string{"TESTFLIGHT_ISSUER_ID", "TEST_FLIGHT_ISSUER_ID"},
 			},
+			dryRunFlag,
 		},
+		Before: configureDryRun,
 		Action: func(context *cli.Context) error {
 			conf := config.TestFlightConfig{
 				AppleID:  context.String("apple-id"),
@@ -59,10 +61,12 @@ func TestFlight(name string, aliases []string) *cli.Command {
 				IssuerID: context.String("issuer-id"),
 			}
 
-			return task.DeployToTestFlight(context.Context, conf, context.String("source-path"), func(req *service.TestFlightDeployRequest) error {
+			_, err := task.DeployToTestFlight(context.Context, conf, context.String("source-path"), func(req *service.TestFlightDeployRequest) error {
 				// no-op
 				return nil
 			})
+
+			return err
 		},
 	}
 }
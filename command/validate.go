@@ -0,0 +1,20 @@
+package command
+
+import (
+	"github.com/jmatsu/splitter/internal/config"
+	"github.com/jmatsu/splitter/task"
+	"github.com/urfave/cli/v2"
+)
+
+// Validate command evaluates and validates every deployment and service definition in the config file.
+func Validate(name string, aliases []string) *cli.Command {
+	return &cli.Command{
+		Name:        name,
+		Aliases:     aliases,
+		Usage:       "Validate the whole config file.",
+		Description: "Deployments are usually validated only when they are deployed. This command reports every problem of the config file at once including unset environment variables, and exits with non-zero code if any problem is found.",
+		Action: func(context *cli.Context) error {
+			return task.FormatValidation(config.CurrentConfig().ValidateAll())
+		},
+	}
+}
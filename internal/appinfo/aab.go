@@ -0,0 +1,217 @@
+package appinfo
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"github.com/pkg/errors"
+	"strconv"
+)
+
+func readAab(r *zip.Reader) (*AppInfo, error) {
+	f, err := findZipEntry(r, func(name string) bool {
+		return name == "base/manifest/AndroidManifest.xml"
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := readZipEntry(f)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return parseProtoManifest(bytes)
+}
+
+// protoField is a field of protocol buffers' wire format. Only varint and length-delimited values are kept.
+type protoField struct {
+	number int
+	varint uint64
+	bytes  []byte
+}
+
+func parseProtoFields(b []byte) ([]protoField, error) {
+	var fields []protoField
+
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+
+		if n <= 0 {
+			return nil, errors.New("a field key is broken")
+		}
+
+		b = b[n:]
+
+		field := protoField{
+			number: int(key >> 3),
+		}
+
+		switch key & 0x7 {
+		case 0: // varint
+			v, n := binary.Uvarint(b)
+
+			if n <= 0 {
+				return nil, errors.New("a varint is broken")
+			}
+
+			field.varint = v
+			b = b[n:]
+		case 1: // 64-bit
+			if len(b) < 8 {
+				return nil, errors.New("a fixed64 is broken")
+			}
+
+			b = b[8:]
+		case 2: // length-delimited
+			l, n := binary.Uvarint(b)
+
+			if n <= 0 || uint64(len(b)-n) < l {
+				return nil, errors.New("a length-delimited value is broken")
+			}
+
+			field.bytes = b[n : n+int(l)]
+			b = b[n+int(l):]
+		case 5: // 32-bit
+			if len(b) < 4 {
+				return nil, errors.New("a fixed32 is broken")
+			}
+
+			b = b[4:]
+		default:
+			return nil, errors.New("an unsupported wire type is found")
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// Field numbers of aapt2's Resources.proto
+const (
+	protoXmlNodeElement = 1
+
+	protoXmlElementName      = 3
+	protoXmlElementAttribute = 4
+
+	protoXmlAttributeName         = 2
+	protoXmlAttributeValue        = 3
+	protoXmlAttributeResourceId   = 5
+	protoXmlAttributeCompiledItem = 6
+
+	protoItemPrim = 7
+
+	protoPrimitiveIntDecimal     = 6
+	protoPrimitiveIntHexadecimal = 7
+)
+
+// parseProtoManifest reads the attributes of the root manifest element of aapt2's proto XML.
+func parseProtoManifest(data []byte) (*AppInfo, error) {
+	nodeFields, err := parseProtoFields(data)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "AndroidManifest.xml is broken")
+	}
+
+	var element []byte
+
+	for _, f := range nodeFields {
+		if f.number == protoXmlNodeElement {
+			element = f.bytes
+		}
+	}
+
+	elementFields, err := parseProtoFields(element)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "manifest element is broken")
+	}
+
+	info := &AppInfo{}
+	var name string
+
+	for _, f := range elementFields {
+		switch f.number {
+		case protoXmlElementName:
+			name = string(f.bytes)
+		case protoXmlElementAttribute:
+			if err := readProtoAttribute(f.bytes, info); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if name != "manifest" {
+		return nil, errors.New("manifest element is not found")
+	}
+
+	return info, nil
+}
+
+func readProtoAttribute(b []byte, info *AppInfo) error {
+	fields, err := parseProtoFields(b)
+
+	if err != nil {
+		return errors.Wrap(err, "an attribute of manifest element is broken")
+	}
+
+	var name, value string
+	var resId uint64
+
+	for _, f := range fields {
+		switch f.number {
+		case protoXmlAttributeName:
+			name = string(f.bytes)
+		case protoXmlAttributeValue:
+			value = string(f.bytes)
+		case protoXmlAttributeResourceId:
+			resId = f.varint
+		case protoXmlAttributeCompiledItem:
+			if value == "" {
+				value = readProtoPrimitiveInt(f.bytes)
+			}
+		}
+	}
+
+	switch {
+	case resId == androidVersionCodeResId || name == "versionCode":
+		info.VersionCode = value
+	case resId == androidVersionNameResId || name == "versionName":
+		info.VersionName = value
+	case name == "package":
+		info.PackageName = value
+	}
+
+	return nil
+}
+
+// readProtoPrimitiveInt reads an integer of a compiled item. An empty string is returned if it's not an integer.
+func readProtoPrimitiveInt(item []byte) string {
+	itemFields, err := parseProtoFields(item)
+
+	if err != nil {
+		return ""
+	}
+
+	for _, f := range itemFields {
+		if f.number != protoItemPrim {
+			continue
+		}
+
+		primFields, err := parseProtoFields(f.bytes)
+
+		if err != nil {
+			return ""
+		}
+
+		for _, p := range primFields {
+			if p.number == protoPrimitiveIntDecimal || p.number == protoPrimitiveIntHexadecimal {
+				return strconv.FormatUint(uint64(uint32(p.varint)), 10)
+			}
+		}
+	}
+
+	return ""
+}
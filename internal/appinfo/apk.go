@@ -0,0 +1,270 @@
+package appinfo
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"fmt"
+	"github.com/pkg/errors"
+	"strconv"
+	"unicode/utf16"
+)
+
+// Chunk types of Android's binary XML
+const (
+	axmlStringPoolType   = 0x0001
+	axmlXmlType          = 0x0003
+	axmlResourceMapType  = 0x0180
+	axmlStartElementType = 0x0102
+
+	axmlUtf8Flag = 1 << 8
+	axmlNoIndex  = 0xFFFFFFFF
+
+	axmlTypeString = 0x03
+	axmlTypeIntDec = 0x10
+	axmlTypeIntHex = 0x11
+
+	androidVersionCodeResId = 0x0101021b
+	androidVersionNameResId = 0x0101021c
+)
+
+func readApk(r *zip.Reader) (*AppInfo, error) {
+	f, err := findZipEntry(r, func(name string) bool {
+		return name == "AndroidManifest.xml"
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := readZipEntry(f)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return parseAxmlManifest(bytes)
+}
+
+type axmlParser struct {
+	data    []byte
+	strings []string
+	resIds  []uint32
+}
+
+// parseAxmlManifest reads the attributes of the root manifest element of a binary XML.
+func parseAxmlManifest(data []byte) (*AppInfo, error) {
+	if len(data) < 8 || binary.LittleEndian.Uint16(data) != axmlXmlType {
+		return nil, errors.New("AndroidManifest.xml is not a binary XML")
+	}
+
+	p := &axmlParser{
+		data: data,
+	}
+
+	offset := int(binary.LittleEndian.Uint16(data[2:]))
+
+	for offset+8 <= len(data) {
+		chunkType := binary.LittleEndian.Uint16(data[offset:])
+		chunkSize := int(binary.LittleEndian.Uint32(data[offset+4:]))
+
+		if chunkSize < 8 || offset+chunkSize > len(data) {
+			return nil, errors.New(fmt.Sprintf("a chunk at %d is broken", offset))
+		}
+
+		chunk := data[offset : offset+chunkSize]
+
+		switch chunkType {
+		case axmlStringPoolType:
+			if err := p.readStringPool(chunk); err != nil {
+				return nil, err
+			}
+		case axmlResourceMapType:
+			headerSize := int(binary.LittleEndian.Uint16(chunk[2:]))
+
+			for i := headerSize; i+4 <= len(chunk); i += 4 {
+				p.resIds = append(p.resIds, binary.LittleEndian.Uint32(chunk[i:]))
+			}
+		case axmlStartElementType:
+			// the first element is the root
+			return p.readManifest(chunk)
+		}
+
+		offset += chunkSize
+	}
+
+	return nil, errors.New("manifest element is not found")
+}
+
+func (p *axmlParser) readStringPool(chunk []byte) error {
+	if len(chunk) < 28 {
+		return errors.New("the string pool is broken")
+	}
+
+	headerSize := int(binary.LittleEndian.Uint16(chunk[2:]))
+	count := int(binary.LittleEndian.Uint32(chunk[8:]))
+	flags := binary.LittleEndian.Uint32(chunk[16:])
+	stringsStart := int(binary.LittleEndian.Uint32(chunk[20:]))
+
+	if headerSize+count*4 > len(chunk) {
+		return errors.New("the string pool is broken")
+	}
+
+	p.strings = make([]string, count)
+
+	for i := 0; i < count; i++ {
+		offset := stringsStart + int(binary.LittleEndian.Uint32(chunk[headerSize+i*4:]))
+
+		if offset >= len(chunk) {
+			return errors.New(fmt.Sprintf("string %d is out of the pool", i))
+		}
+
+		var err error
+
+		if flags&axmlUtf8Flag != 0 {
+			p.strings[i], err = decodeAxmlUtf8(chunk[offset:])
+		} else {
+			p.strings[i], err = decodeAxmlUtf16(chunk[offset:])
+		}
+
+		if err != nil {
+			return errors.Wrapf(err, "string %d is broken", i)
+		}
+	}
+
+	return nil
+}
+
+// UTF-8 strings are prefixed by the lengths in UTF-16 and UTF-8. Each length is 1 or 2 bytes.
+func decodeAxmlUtf8(b []byte) (string, error) {
+	skipLength := func(b []byte) (int, []byte, error) {
+		if len(b) < 1 {
+			return 0, nil, errors.New("unexpected end")
+		} else if b[0]&0x80 == 0 {
+			return int(b[0]), b[1:], nil
+		} else if len(b) < 2 {
+			return 0, nil, errors.New("unexpected end")
+		} else {
+			return int(b[0]&0x7F)<<8 | int(b[1]), b[2:], nil
+		}
+	}
+
+	_, b, err := skipLength(b)
+
+	if err != nil {
+		return "", err
+	}
+
+	n, b, err := skipLength(b)
+
+	if err != nil {
+		return "", err
+	} else if n > len(b) {
+		return "", errors.New("unexpected end")
+	}
+
+	return string(b[:n]), nil
+}
+
+// UTF-16 strings are prefixed by the length that is 1 or 2 uint16.
+func decodeAxmlUtf16(b []byte) (string, error) {
+	if len(b) < 2 {
+		return "", errors.New("unexpected end")
+	}
+
+	n := int(binary.LittleEndian.Uint16(b))
+	b = b[2:]
+
+	if n&0x8000 != 0 {
+		if len(b) < 2 {
+			return "", errors.New("unexpected end")
+		}
+
+		n = (n&0x7FFF)<<16 | int(binary.LittleEndian.Uint16(b))
+		b = b[2:]
+	}
+
+	if n*2 > len(b) {
+		return "", errors.New("unexpected end")
+	}
+
+	units := make([]uint16, n)
+
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(b[i*2:])
+	}
+
+	return string(utf16.Decode(units)), nil
+}
+
+func (p *axmlParser) str(idx uint32) string {
+	if idx == axmlNoIndex || int(idx) >= len(p.strings) {
+		return ""
+	}
+
+	return p.strings[idx]
+}
+
+func (p *axmlParser) resId(idx uint32) uint32 {
+	if int(idx) >= len(p.resIds) {
+		return 0
+	}
+
+	return p.resIds[idx]
+}
+
+func (p *axmlParser) readManifest(chunk []byte) (*AppInfo, error) {
+	headerSize := int(binary.LittleEndian.Uint16(chunk[2:]))
+
+	if len(chunk) < headerSize+20 {
+		return nil, errors.New("manifest element is broken")
+	}
+
+	ext := chunk[headerSize:]
+
+	if name := p.str(binary.LittleEndian.Uint32(ext[4:])); name != "manifest" {
+		return nil, errors.New(fmt.Sprintf("the root element must be manifest but %s", name))
+	}
+
+	attributeStart := int(binary.LittleEndian.Uint16(ext[8:]))
+	attributeSize := int(binary.LittleEndian.Uint16(ext[10:]))
+	attributeCount := int(binary.LittleEndian.Uint16(ext[12:]))
+
+	info := &AppInfo{}
+
+	for i := 0; i < attributeCount; i++ {
+		offset := headerSize + attributeStart + i*attributeSize
+
+		if offset+20 > len(chunk) {
+			return nil, errors.New("an attribute of manifest element is broken")
+		}
+
+		attr := chunk[offset:]
+		nameIdx := binary.LittleEndian.Uint32(attr[4:])
+		rawValue := binary.LittleEndian.Uint32(attr[8:])
+		dataType := attr[15]
+		data := binary.LittleEndian.Uint32(attr[16:])
+
+		var value string
+
+		switch {
+		case rawValue != axmlNoIndex:
+			value = p.str(rawValue)
+		case dataType == axmlTypeString:
+			value = p.str(data)
+		case dataType == axmlTypeIntDec || dataType == axmlTypeIntHex:
+			value = strconv.FormatUint(uint64(data), 10)
+		}
+
+		// names may be obfuscated so prefer the resource ids
+		switch name := p.str(nameIdx); {
+		case p.resId(nameIdx) == androidVersionCodeResId || name == "versionCode":
+			info.VersionCode = value
+		case p.resId(nameIdx) == androidVersionNameResId || name == "versionName":
+			info.VersionName = value
+		case name == "package":
+			info.PackageName = value
+		}
+	}
+
+	return info, nil
+}
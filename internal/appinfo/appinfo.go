@@ -0,0 +1,80 @@
+package appinfo
+
+import (
+	"archive/zip"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// AppInfo is the metadata of an app file.
+type AppInfo struct {
+	// Android's package name or iOS's bundle identifier
+	PackageName string
+
+	// Android's versionName or iOS's CFBundleShortVersionString
+	VersionName string
+
+	// Android's versionCode or iOS's CFBundleVersion
+	VersionCode string
+}
+
+// Read extracts the metadata from apk, aab and ipa files.
+func Read(filePath string) (*AppInfo, error) {
+	ext := strings.ToLower(filepath.Ext(filePath))
+
+	var read func(r *zip.Reader) (*AppInfo, error)
+
+	switch ext {
+	case ".apk":
+		read = readApk
+	case ".aab":
+		read = readAab
+	case ".ipa":
+		read = readIpa
+	default:
+		return nil, errors.New(fmt.Sprintf("%s files are not supported to read the app metadata", ext))
+	}
+
+	r, err := zip.OpenReader(filePath)
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s is not a valid archive", filePath)
+	}
+
+	//goland:noinspection GoUnhandledErrorResult
+	defer r.Close()
+
+	info, err := read(&r.Reader)
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read the app metadata of %s", filePath)
+	}
+
+	return info, nil
+}
+
+func readZipEntry(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot open %s", f.Name)
+	}
+
+	//goland:noinspection GoUnhandledErrorResult
+	defer rc.Close()
+
+	return io.ReadAll(rc)
+}
+
+func findZipEntry(r *zip.Reader, match func(name string) bool) (*zip.File, error) {
+	for _, f := range r.File {
+		if match(f.Name) {
+			return f, nil
+		}
+	}
+
+	return nil, errors.New("the manifest is not found")
+}
@@ -0,0 +1,360 @@
+package appinfo
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"unicode/utf16"
+)
+
+func writeZip(t *testing.T, path string, entries map[string][]byte) {
+	t.Helper()
+
+	f, err := os.Create(path)
+
+	if err != nil {
+		t.Fatalf("failed to create %s: %v", path, err)
+	}
+
+	w := zip.NewWriter(f)
+
+	for name, data := range entries {
+		if fw, err := w.Create(name); err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		} else if _, err := fw.Write(data); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close the zip: %v", err)
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatalf("failed to close %s: %v", path, err)
+	}
+}
+
+type axmlAttribute struct {
+	name     int
+	rawValue uint32
+	dataType byte
+	data     uint32
+}
+
+// buildAxml builds a minimal binary XML that has only the root element.
+func buildAxml(strs []string, utf8 bool, resIds []uint32, element int, attrs []axmlAttribute) []byte {
+	le := binary.LittleEndian
+
+	var stringData []byte
+	var offsets []byte
+
+	for _, s := range strs {
+		offsets = le.AppendUint32(offsets, uint32(len(stringData)))
+
+		if utf8 {
+			stringData = append(stringData, byte(len(utf16.Encode([]rune(s)))), byte(len(s)))
+			stringData = append(stringData, s...)
+			stringData = append(stringData, 0)
+		} else {
+			units := utf16.Encode([]rune(s))
+			stringData = le.AppendUint16(stringData, uint16(len(units)))
+
+			for _, u := range units {
+				stringData = le.AppendUint16(stringData, u)
+			}
+
+			stringData = le.AppendUint16(stringData, 0)
+		}
+	}
+
+	for len(stringData)%4 != 0 {
+		stringData = append(stringData, 0)
+	}
+
+	var flags uint32
+
+	if utf8 {
+		flags = axmlUtf8Flag
+	}
+
+	var pool []byte
+	pool = le.AppendUint16(pool, axmlStringPoolType)
+	pool = le.AppendUint16(pool, 28)
+	pool = le.AppendUint32(pool, uint32(28+len(offsets)+len(stringData)))
+	pool = le.AppendUint32(pool, uint32(len(strs)))
+	pool = le.AppendUint32(pool, 0)
+	pool = le.AppendUint32(pool, flags)
+	pool = le.AppendUint32(pool, uint32(28+len(offsets)))
+	pool = le.AppendUint32(pool, 0)
+	pool = append(pool, offsets...)
+	pool = append(pool, stringData...)
+
+	var resMap []byte
+	resMap = le.AppendUint16(resMap, axmlResourceMapType)
+	resMap = le.AppendUint16(resMap, 8)
+	resMap = le.AppendUint32(resMap, uint32(8+4*len(resIds)))
+
+	for _, id := range resIds {
+		resMap = le.AppendUint32(resMap, id)
+	}
+
+	var start []byte
+	start = le.AppendUint16(start, axmlStartElementType)
+	start = le.AppendUint16(start, 16)
+	start = le.AppendUint32(start, uint32(16+20+20*len(attrs)))
+	start = le.AppendUint32(start, 1)
+	start = le.AppendUint32(start, axmlNoIndex)
+	start = le.AppendUint32(start, axmlNoIndex)
+	start = le.AppendUint32(start, uint32(element))
+	start = le.AppendUint16(start, 20)
+	start = le.AppendUint16(start, 20)
+	start = le.AppendUint16(start, uint16(len(attrs)))
+	start = le.AppendUint16(start, 0)
+	start = le.AppendUint16(start, 0)
+	start = le.AppendUint16(start, 0)
+
+	for _, a := range attrs {
+		start = le.AppendUint32(start, axmlNoIndex)
+		start = le.AppendUint32(start, uint32(a.name))
+		start = le.AppendUint32(start, a.rawValue)
+		start = le.AppendUint16(start, 8)
+		start = append(start, 0, a.dataType)
+		start = le.AppendUint32(start, a.data)
+	}
+
+	var xml []byte
+	xml = le.AppendUint16(xml, axmlXmlType)
+	xml = le.AppendUint16(xml, 8)
+	xml = le.AppendUint32(xml, uint32(8+len(pool)+len(resMap)+len(start)))
+	xml = append(xml, pool...)
+	xml = append(xml, resMap...)
+	xml = append(xml, start...)
+
+	return xml
+}
+
+func appendProtoBytes(b []byte, number int, v []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(number<<3|2))
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendProtoVarint(b []byte, number int, v uint64) []byte {
+	b = binary.AppendUvarint(b, uint64(number<<3))
+	return binary.AppendUvarint(b, v)
+}
+
+func Test_Read(t *testing.T) {
+	t.Parallel()
+
+	expected := &AppInfo{
+		PackageName: "com.example.app",
+		VersionName: "1.2.3",
+		VersionCode: "123",
+	}
+
+	strs := []string{"versionName", "versionCode", "package", "manifest", "1.2.3", "com.example.app"}
+	attrs := []axmlAttribute{
+		{name: 0, rawValue: 4, dataType: axmlTypeString, data: 4},
+		{name: 1, rawValue: axmlNoIndex, dataType: axmlTypeIntDec, data: 123},
+		{name: 2, rawValue: 5, dataType: axmlTypeString, data: 5},
+	}
+
+	// obfuscated names are resolved by the resource ids
+	obfuscatedStrs := []string{"", "", "package", "manifest", "1.2.3", "com.example.app"}
+
+	var versionCode []byte
+	versionCode = appendProtoBytes(versionCode, protoXmlAttributeName, []byte("versionCode"))
+	versionCode = appendProtoVarint(versionCode, protoXmlAttributeResourceId, androidVersionCodeResId)
+	versionCode = appendProtoBytes(versionCode, protoXmlAttributeCompiledItem, appendProtoBytes(nil, protoItemPrim, appendProtoVarint(nil, protoPrimitiveIntDecimal, 123)))
+
+	var element []byte
+	element = appendProtoBytes(element, protoXmlElementName, []byte("manifest"))
+	element = appendProtoBytes(element, protoXmlElementAttribute, appendProtoBytes(appendProtoBytes(nil, protoXmlAttributeName, []byte("versionName")), protoXmlAttributeValue, []byte("1.2.3")))
+	element = appendProtoBytes(element, protoXmlElementAttribute, versionCode)
+	element = appendProtoBytes(element, protoXmlElementAttribute, appendProtoBytes(appendProtoBytes(nil, protoXmlAttributeName, []byte("package")), protoXmlAttributeValue, []byte("com.example.app")))
+	element = appendProtoBytes(element, 5, appendProtoBytes(nil, protoXmlNodeElement, appendProtoBytes(nil, protoXmlElementName, []byte("application"))))
+
+	xmlPlist := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>CFBundleIcons</key>
+	<dict>
+		<key>CFBundleVersion</key>
+		<string>nested</string>
+	</dict>
+	<key>CFBundleIdentifier</key>
+	<string>com.example.app</string>
+	<key>CFBundleShortVersionString</key>
+	<string>1.2.3</string>
+	<key>LSRequiresIPhoneOS</key>
+	<true/>
+	<key>CFBundleVersion</key>
+	<string>123</string>
+</dict>
+</plist>`)
+
+	cases := map[string]struct {
+		fileName string
+		entries  map[string][]byte
+
+		expected    *AppInfo
+		expectedErr bool
+	}{
+		"apk with utf-16 strings": {
+			fileName: "app.apk",
+			entries: map[string][]byte{
+				"AndroidManifest.xml": buildAxml(strs, false, nil, 3, attrs),
+			},
+			expected: expected,
+		},
+		"apk with utf-8 strings": {
+			fileName: "app.apk",
+			entries: map[string][]byte{
+				"AndroidManifest.xml": buildAxml(strs, true, nil, 3, attrs),
+			},
+			expected: expected,
+		},
+		"apk with obfuscated names": {
+			fileName: "app.apk",
+			entries: map[string][]byte{
+				"AndroidManifest.xml": buildAxml(obfuscatedStrs, false, []uint32{androidVersionNameResId, androidVersionCodeResId}, 3, attrs),
+			},
+			expected: expected,
+		},
+		"apk without manifest": {
+			fileName: "app.apk",
+			entries: map[string][]byte{
+				"classes.dex": {},
+			},
+			expectedErr: true,
+		},
+		"apk with a text manifest": {
+			fileName: "app.apk",
+			entries: map[string][]byte{
+				"AndroidManifest.xml": []byte("<manifest/>"),
+			},
+			expectedErr: true,
+		},
+		"aab": {
+			fileName: "app.aab",
+			entries: map[string][]byte{
+				"base/manifest/AndroidManifest.xml": appendProtoBytes(nil, protoXmlNodeElement, element),
+			},
+			expected: expected,
+		},
+		"ipa with a xml plist": {
+			fileName: "app.ipa",
+			entries: map[string][]byte{
+				"Payload/App.app/Frameworks/Lib.framework/Info.plist": []byte("broken"),
+				"Payload/App.app/Info.plist":                          xmlPlist,
+			},
+			expected: expected,
+		},
+		"ipa with a binary plist": {
+			fileName: "app.ipa",
+			entries: map[string][]byte{
+				"Payload/App.app/Info.plist": buildBinaryPlist(map[string]string{
+					"CFBundleIdentifier":         "com.example.app",
+					"CFBundleShortVersionString": "1.2.3",
+					"CFBundleVersion":            "123",
+				}),
+			},
+			expected: expected,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), c.fileName)
+			writeZip(t, path, c.entries)
+
+			actual, err := Read(path)
+
+			if c.expectedErr {
+				if err == nil {
+					t.Errorf("%s case is expected to be failure but %v", name, actual)
+				}
+
+				return
+			} else if err != nil {
+				t.Fatalf("%s case is expected to be success but %v", name, err)
+			}
+
+			if !reflect.DeepEqual(c.expected, actual) {
+				t.Errorf("%v is expected but %v", c.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_Read_unsupported(t *testing.T) {
+	t.Parallel()
+
+	if _, err := Read("app.zip"); err == nil {
+		t.Errorf("zip files are expected to be unsupported")
+	}
+}
+
+// buildBinaryPlist builds a binary plist of a dictionary of ascii strings.
+func buildBinaryPlist(values map[string]string) []byte {
+	var keys, strs []string
+
+	for k, v := range values {
+		keys = append(keys, k)
+		strs = append(strs, v)
+	}
+
+	n := len(keys)
+
+	// object 0 is the dictionary, 1..n are keys and n+1..2n are values
+	data := []byte("bplist00")
+	var offsets []uint64
+
+	offsets = append(offsets, uint64(len(data)))
+	data = append(data, 0xD0|byte(n))
+
+	for i := 0; i < n; i++ {
+		data = append(data, byte(1+i))
+	}
+
+	for i := 0; i < n; i++ {
+		data = append(data, byte(1+n+i))
+	}
+
+	for _, s := range append(keys, strs...) {
+		offsets = append(offsets, uint64(len(data)))
+
+		if len(s) < 0x0F {
+			data = append(data, 0x50|byte(len(s)))
+		} else {
+			data = append(data, 0x5F, 0x10, byte(len(s)))
+		}
+
+		data = append(data, s...)
+	}
+
+	offsetTableOffset := len(data)
+
+	for _, o := range offsets {
+		data = append(data, byte(o))
+	}
+
+	trailer := make([]byte, 32)
+	trailer[6] = 1
+	trailer[7] = 1
+	binary.BigEndian.PutUint64(trailer[8:], uint64(len(offsets)))
+	binary.BigEndian.PutUint64(trailer[16:], 0)
+	binary.BigEndian.PutUint64(trailer[24:], uint64(offsetTableOffset))
+
+	return append(data, trailer...)
+}
@@ -0,0 +1,280 @@
+package appinfo
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+func readIpa(r *zip.Reader) (*AppInfo, error) {
+	f, err := findZipEntry(r, func(name string) bool {
+		// Payload/<name>.app/Info.plist
+		segments := strings.Split(name, "/")
+		return len(segments) == 3 && segments[0] == "Payload" && strings.HasSuffix(segments[1], ".app") && segments[2] == "Info.plist"
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := readZipEntry(f)
+
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := parsePlistDict(data)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "Info.plist is broken")
+	}
+
+	return &AppInfo{
+		PackageName: values["CFBundleIdentifier"],
+		VersionName: values["CFBundleShortVersionString"],
+		VersionCode: values["CFBundleVersion"],
+	}, nil
+}
+
+// parsePlistDict reads the scalar values of the top-level dictionary. Nested values are ignored.
+func parsePlistDict(data []byte) (map[string]string, error) {
+	if bytes.HasPrefix(data, []byte("bplist00")) {
+		return parseBinaryPlistDict(data)
+	}
+
+	return parseXmlPlistDict(data)
+}
+
+func parseXmlPlistDict(data []byte) (map[string]string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	values := map[string]string{}
+
+	depth := 0 // plist = 1, top-level dict = 2
+	var key string
+
+	for {
+		token, err := decoder.Token()
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+
+			if depth != 3 {
+				continue
+			}
+
+			switch t.Name.Local {
+			case "key":
+				if err := decoder.DecodeElement(&key, &t); err != nil {
+					return nil, err
+				}
+
+				depth--
+			case "string", "integer", "real":
+				var value string
+
+				if err := decoder.DecodeElement(&value, &t); err != nil {
+					return nil, err
+				}
+
+				values[key] = strings.TrimSpace(value)
+				depth--
+			case "true", "false":
+				values[key] = t.Name.Local
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+
+	return values, nil
+}
+
+type binaryPlist struct {
+	data          []byte
+	offsets       []uint64
+	objectRefSize int
+}
+
+func parseBinaryPlistDict(data []byte) (map[string]string, error) {
+	if len(data) < 8+32 {
+		return nil, errors.New("the binary plist is too short")
+	}
+
+	trailer := data[len(data)-32:]
+	offsetIntSize := int(trailer[6])
+	objectRefSize := int(trailer[7])
+	numObjects := binary.BigEndian.Uint64(trailer[8:])
+	topObject := binary.BigEndian.Uint64(trailer[16:])
+	offsetTableOffset := binary.BigEndian.Uint64(trailer[24:])
+
+	if offsetIntSize < 1 || offsetIntSize > 8 || objectRefSize < 1 || objectRefSize > 8 {
+		return nil, errors.New("the trailer of the binary plist is broken")
+	}
+
+	if numObjects > uint64(len(data)) || offsetTableOffset+numObjects*uint64(offsetIntSize) > uint64(len(data)) {
+		return nil, errors.New("the offset table of the binary plist is broken")
+	}
+
+	p := &binaryPlist{
+		data:          data,
+		offsets:       make([]uint64, numObjects),
+		objectRefSize: objectRefSize,
+	}
+
+	for i := range p.offsets {
+		start := offsetTableOffset + uint64(i*offsetIntSize)
+		p.offsets[i] = readBigEndian(data[start : start+uint64(offsetIntSize)])
+	}
+
+	marker, count, body, err := p.object(topObject)
+
+	if err != nil {
+		return nil, err
+	} else if marker>>4 != 0xD {
+		return nil, errors.New("the top object is not a dictionary")
+	}
+
+	if uint64(len(body)) < count*2*uint64(objectRefSize) {
+		return nil, errors.New("the top dictionary is broken")
+	}
+
+	values := map[string]string{}
+
+	for i := uint64(0); i < count; i++ {
+		keyRef := readBigEndian(body[i*uint64(objectRefSize) : (i+1)*uint64(objectRefSize)])
+		valueRef := readBigEndian(body[(count+i)*uint64(objectRefSize) : (count+i+1)*uint64(objectRefSize)])
+
+		key, ok, err := p.scalar(keyRef)
+
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			continue
+		}
+
+		if value, ok, err := p.scalar(valueRef); err != nil {
+			return nil, err
+		} else if ok {
+			values[key] = value
+		}
+	}
+
+	return values, nil
+}
+
+func readBigEndian(b []byte) uint64 {
+	var v uint64
+
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+
+	return v
+}
+
+// object returns the marker, the count and the bytes after the marker (and the extended count) of the object.
+func (p *binaryPlist) object(ref uint64) (byte, uint64, []byte, error) {
+	if ref >= uint64(len(p.offsets)) || p.offsets[ref] >= uint64(len(p.data)) {
+		return 0, 0, nil, errors.New(fmt.Sprintf("object %d is out of the plist", ref))
+	}
+
+	b := p.data[p.offsets[ref]:]
+	marker := b[0]
+	count := uint64(marker & 0x0F)
+	b = b[1:]
+
+	// the count is followed as an int object if it's too large
+	if kind := marker >> 4; count == 0x0F && kind != 0x0 && kind != 0x1 && kind != 0x2 && kind != 0x3 {
+		if len(b) < 1 || b[0]>>4 != 0x1 {
+			return 0, 0, nil, errors.New("the extended count is broken")
+		}
+
+		size := 1 << (b[0] & 0x0F)
+
+		if len(b) < 1+size {
+			return 0, 0, nil, errors.New("the extended count is broken")
+		}
+
+		count = readBigEndian(b[1 : 1+size])
+		b = b[1+size:]
+	}
+
+	return marker, count, b, nil
+}
+
+// scalar returns the value of strings, integers, reals and booleans as a string.
+func (p *binaryPlist) scalar(ref uint64) (string, bool, error) {
+	marker, count, b, err := p.object(ref)
+
+	if err != nil {
+		return "", false, err
+	}
+
+	switch marker >> 4 {
+	case 0x0:
+		switch marker {
+		case 0x08:
+			return "false", true, nil
+		case 0x09:
+			return "true", true, nil
+		}
+	case 0x1:
+		size := 1 << count
+
+		if len(b) < size {
+			return "", false, errors.New("an integer is broken")
+		}
+
+		if size == 8 {
+			return strconv.FormatInt(int64(readBigEndian(b[:size])), 10), true, nil
+		}
+
+		return strconv.FormatUint(readBigEndian(b[:size]), 10), true, nil
+	case 0x2:
+		size := 1 << count
+
+		switch {
+		case size == 4 && len(b) >= 4:
+			return strconv.FormatFloat(float64(math.Float32frombits(binary.BigEndian.Uint32(b))), 'f', -1, 32), true, nil
+		case size == 8 && len(b) >= 8:
+			return strconv.FormatFloat(math.Float64frombits(binary.BigEndian.Uint64(b)), 'f', -1, 64), true, nil
+		}
+
+		return "", false, errors.New("a real is broken")
+	case 0x5:
+		if uint64(len(b)) < count {
+			return "", false, errors.New("a string is broken")
+		}
+
+		return string(b[:count]), true, nil
+	case 0x6:
+		if uint64(len(b)) < count*2 {
+			return "", false, errors.New("a string is broken")
+		}
+
+		units := make([]uint16, count)
+
+		for i := range units {
+			units[i] = binary.BigEndian.Uint16(b[i*2:])
+		}
+
+		return string(utf16.Decode(units)), true, nil
+	}
+
+	return "", false, nil
+}
@@ -0,0 +1,88 @@
+package ci
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Metadata describes the CI build that runs splitter.
+type Metadata struct {
+	Provider string `json:"provider"`
+	BuildID  string `json:"build_id,omitempty"`
+	BuildURL string `json:"build_url,omitempty"`
+	Commit   string `json:"commit,omitempty"`
+	Branch   string `json:"branch,omitempty"`
+}
+
+// Current detects the CI build of the current process.
+func Current() *Metadata {
+	return Detect(os.LookupEnv)
+}
+
+// Detect reads the well-known environment variables of CI services. nil is returned if it's not running on CI.
+func Detect(lookupEnv func(name string) (string, bool)) *Metadata {
+	env := func(name string) string {
+		v, _ := lookupEnv(name)
+		return v
+	}
+
+	switch {
+	case env("GITHUB_ACTIONS") == "true":
+		m := &Metadata{
+			Provider: "github-actions",
+			BuildID:  env("GITHUB_RUN_ID"),
+			Commit:   env("GITHUB_SHA"),
+			Branch:   env("GITHUB_HEAD_REF"),
+		}
+
+		if server, repo := env("GITHUB_SERVER_URL"), env("GITHUB_REPOSITORY"); server != "" && repo != "" && m.BuildID != "" {
+			m.BuildURL = fmt.Sprintf("%s/%s/actions/runs/%s", server, repo, m.BuildID)
+		}
+
+		// GITHUB_HEAD_REF is available only for pull requests
+		if m.Branch == "" && env("GITHUB_REF_TYPE") == "branch" {
+			m.Branch = env("GITHUB_REF_NAME")
+		}
+
+		return m
+	case env("CIRCLECI") == "true":
+		return &Metadata{
+			Provider: "circleci",
+			BuildID:  env("CIRCLE_BUILD_NUM"),
+			BuildURL: env("CIRCLE_BUILD_URL"),
+			Commit:   env("CIRCLE_SHA1"),
+			Branch:   env("CIRCLE_BRANCH"),
+		}
+	case env("BITRISE_IO") == "true":
+		return &Metadata{
+			Provider: "bitrise",
+			BuildID:  env("BITRISE_BUILD_NUMBER"),
+			BuildURL: env("BITRISE_BUILD_URL"),
+			Commit:   env("BITRISE_GIT_COMMIT"),
+			Branch:   env("BITRISE_GIT_BRANCH"),
+		}
+	case env("GITLAB_CI") == "true":
+		return &Metadata{
+			Provider: "gitlab-ci",
+			BuildID:  env("CI_PIPELINE_ID"),
+			BuildURL: env("CI_PIPELINE_URL"),
+			Commit:   env("CI_COMMIT_SHA"),
+			Branch:   env("CI_COMMIT_REF_NAME"),
+		}
+	case env("JENKINS_URL") != "":
+		return &Metadata{
+			Provider: "jenkins",
+			BuildID:  env("BUILD_NUMBER"),
+			BuildURL: env("BUILD_URL"),
+			Commit:   env("GIT_COMMIT"),
+			Branch:   strings.TrimPrefix(env("GIT_BRANCH"), "origin/"),
+		}
+	case env("CI") != "" && env("CI") != "false":
+		return &Metadata{
+			Provider: "unknown",
+		}
+	}
+
+	return nil
+}
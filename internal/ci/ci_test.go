@@ -0,0 +1,99 @@
+package ci
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_Detect(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		env map[string]string
+
+		expected *Metadata
+	}{
+		"not ci": {
+			env:      map[string]string{},
+			expected: nil,
+		},
+		"ci is false": {
+			env: map[string]string{
+				"CI": "false",
+			},
+			expected: nil,
+		},
+		"unknown ci": {
+			env: map[string]string{
+				"CI": "true",
+			},
+			expected: &Metadata{
+				Provider: "unknown",
+			},
+		},
+		"github actions push": {
+			env: map[string]string{
+				"CI":                "true",
+				"GITHUB_ACTIONS":    "true",
+				"GITHUB_RUN_ID":     "123",
+				"GITHUB_SHA":        "abcdef",
+				"GITHUB_SERVER_URL": "https://github.com",
+				"GITHUB_REPOSITORY": "jmatsu/splitter",
+				"GITHUB_REF_TYPE":   "branch",
+				"GITHUB_REF_NAME":   "main",
+			},
+			expected: &Metadata{
+				Provider: "github-actions",
+				BuildID:  "123",
+				BuildURL: "https://github.com/jmatsu/splitter/actions/runs/123",
+				Commit:   "abcdef",
+				Branch:   "main",
+			},
+		},
+		"github actions pull request": {
+			env: map[string]string{
+				"GITHUB_ACTIONS":  "true",
+				"GITHUB_HEAD_REF": "feature",
+				"GITHUB_REF_TYPE": "branch",
+				"GITHUB_REF_NAME": "1/merge",
+			},
+			expected: &Metadata{
+				Provider: "github-actions",
+				Branch:   "feature",
+			},
+		},
+		"jenkins": {
+			env: map[string]string{
+				"JENKINS_URL":  "https://jenkins.example.com/",
+				"BUILD_NUMBER": "42",
+				"BUILD_URL":    "https://jenkins.example.com/job/app/42/",
+				"GIT_COMMIT":   "abcdef",
+				"GIT_BRANCH":   "origin/main",
+			},
+			expected: &Metadata{
+				Provider: "jenkins",
+				BuildID:  "42",
+				BuildURL: "https://jenkins.example.com/job/app/42/",
+				Commit:   "abcdef",
+				Branch:   "main",
+			},
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual := Detect(func(name string) (string, bool) {
+				v, ok := c.env[name]
+				return v, ok
+			})
+
+			if !reflect.DeepEqual(c.expected, actual) {
+				t.Errorf("%v is expected but %v", c.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package condition
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// Variables are the values that expressions can refer to.
+type Variables struct {
+	LookupEnv func(name string) (string, bool)
+	FilePath  string
+	OSName    string
+}
+
+// CurrentVariables returns variables of the current process and the given artifact.
+func CurrentVariables(filePath string) Variables {
+	return Variables{
+		LookupEnv: os.LookupEnv,
+		FilePath:  filePath,
+		OSName:    runtime.GOOS,
+	}
+}
+
+func (v *Variables) resolve(identifier string) (value, error) {
+	scope, name, found := strings.Cut(identifier, ".")
+
+	if !found || name == "" {
+		return value{}, errors.New(fmt.Sprintf("%s is an unknown identifier", identifier))
+	}
+
+	switch scope {
+	case "env":
+		if v.LookupEnv == nil {
+			return stringValue(""), nil
+		}
+
+		s, _ := v.LookupEnv(name)
+		return stringValue(s), nil
+	case "file":
+		switch name {
+		case "ext":
+			return stringValue(strings.ToLower(strings.TrimPrefix(filepath.Ext(v.FilePath), "."))), nil
+		case "name":
+			return stringValue(filepath.Base(v.FilePath)), nil
+		case "path":
+			return stringValue(v.FilePath), nil
+		}
+	case "os":
+		if name == "name" {
+			return stringValue(v.OSName), nil
+		}
+	}
+
+	return value{}, errors.New(fmt.Sprintf("%s is an unknown identifier", identifier))
+}
+
+// knownIdentifiers are the identifiers except env.<NAME>.
+var knownIdentifiers = []string{"file.ext", "file.name", "file.path", "os.name"}
+
+// checkIdentifier makes sure the identifier is one of the known variables so that typos are rejected before evaluation.
+func checkIdentifier(identifier string) error {
+	if scope, name, found := strings.Cut(identifier, "."); found && scope == "env" && name != "" {
+		return nil
+	}
+
+	if slices.Contains(knownIdentifiers, identifier) {
+		return nil
+	}
+
+	return errors.New(fmt.Sprintf("%s is an unknown identifier. Use env.<NAME>, %s", identifier, strings.Join(knownIdentifiers, ", ")))
+}
+
+// Validate checks the syntax of the expression and its identifiers against the known variables.
+func Validate(expression string) error {
+	_, err := parse(expression)
+	return err
+}
+
+// Evaluate the boolean expression. An empty expression is always true.
+//
+// Supported syntax:
+//   - Identifiers: env.<NAME>, file.ext, file.name, file.path, os.name
+//   - Literals: "string", 'string', true, false
+//   - Operators: ==, != , =~ (regular expression), !, &&, || and parentheses
+//
+// A string is true if it is not empty.
+func Evaluate(expression string, vars Variables) (bool, error) {
+	n, err := parse(expression)
+
+	if err != nil {
+		return false, err
+	}
+
+	if n == nil {
+		return true, nil
+	}
+
+	if v, err := n.eval(&vars); err != nil {
+		return false, err
+	} else {
+		return v.truthy(), nil
+	}
+}
+
+type value struct {
+	s      string
+	b      bool
+	isBool bool
+}
+
+func stringValue(s string) value {
+	return value{s: s}
+}
+
+func boolValue(b bool) value {
+	return value{b: b, isBool: true}
+}
+
+func (v value) truthy() bool {
+	if v.isBool {
+		return v.b
+	}
+
+	return v.s != ""
+}
+
+func (v value) String() string {
+	if v.isBool {
+		return fmt.Sprintf("%t", v.b)
+	}
+
+	return v.s
+}
+
+type node interface {
+	eval(vars *Variables) (value, error)
+}
+
+type literalNode struct {
+	value value
+}
+
+func (n *literalNode) eval(_ *Variables) (value, error) {
+	return n.value, nil
+}
+
+type identifierNode struct {
+	name string
+}
+
+func (n *identifierNode) eval(vars *Variables) (value, error) {
+	return vars.resolve(n.name)
+}
+
+type notNode struct {
+	operand node
+}
+
+func (n *notNode) eval(vars *Variables) (value, error) {
+	if v, err := n.operand.eval(vars); err != nil {
+		return value{}, err
+	} else {
+		return boolValue(!v.truthy()), nil
+	}
+}
+
+type binaryNode struct {
+	operator string
+	left     node
+	right    node
+}
+
+func (n *binaryNode) eval(vars *Variables) (value, error) {
+	left, err := n.left.eval(vars)
+
+	if err != nil {
+		return value{}, err
+	}
+
+	// short-circuit
+	switch n.operator {
+	case "&&":
+		if !left.truthy() {
+			return boolValue(false), nil
+		}
+	case "||":
+		if left.truthy() {
+			return boolValue(true), nil
+		}
+	}
+
+	right, err := n.right.eval(vars)
+
+	if err != nil {
+		return value{}, err
+	}
+
+	switch n.operator {
+	case "&&", "||":
+		return boolValue(right.truthy()), nil
+	case "==":
+		return boolValue(left.String() == right.String()), nil
+	case "!=":
+		return boolValue(left.String() != right.String()), nil
+	case "=~":
+		if r, err := regexp.Compile(right.String()); err != nil {
+			return value{}, errors.Wrapf(err, "%s is not a valid regular expression", right.String())
+		} else {
+			return boolValue(r.MatchString(left.String())), nil
+		}
+	default:
+		panic(fmt.Sprintf("%s is not implemented yet", n.operator))
+	}
+}
@@ -0,0 +1,175 @@
+package condition
+
+import (
+	"testing"
+)
+
+func Test_Evaluate(t *testing.T) {
+	t.Parallel()
+
+	vars := Variables{
+		LookupEnv: func(name string) (string, bool) {
+			switch name {
+			case "GITHUB_REF":
+				return "refs/heads/main", true
+			case "CI":
+				return "true", true
+			default:
+				return "", false
+			}
+		},
+		FilePath: "/path/to/app-release.AAB",
+		OSName:   "linux",
+	}
+
+	cases := map[string]struct {
+		expression string
+
+		expected    bool
+		expectedErr bool
+	}{
+		"empty": {
+			expression: " ",
+			expected:   true,
+		},
+		"equal": {
+			expression: `env.GITHUB_REF == "refs/heads/main"`,
+			expected:   true,
+		},
+		"not equal": {
+			expression: `env.GITHUB_REF != 'refs/heads/main'`,
+			expected:   false,
+		},
+		"and": {
+			expression: `env.GITHUB_REF == "refs/heads/main" && file.ext == "aab"`,
+			expected:   true,
+		},
+		"or": {
+			expression: `file.ext == "apk" || os.name == "linux"`,
+			expected:   true,
+		},
+		"not": {
+			expression: `!(file.ext == "apk")`,
+			expected:   true,
+		},
+		"precedence": {
+			expression: `false && false || true`,
+			expected:   true,
+		},
+		"regexp": {
+			expression: `env.GITHUB_REF =~ "^refs/heads/(main|release/.+)$"`,
+			expected:   true,
+		},
+		"truthy": {
+			expression: `env.CI && !env.UNDEFINED`,
+			expected:   true,
+		},
+		"file name": {
+			expression: `file.name == "app-release.AAB"`,
+			expected:   true,
+		},
+		"escaped quote": {
+			expression: `"a\"b" == 'a"b'`,
+			expected:   true,
+		},
+		"unknown identifier": {
+			expression:  `file.size == "1"`,
+			expectedErr: true,
+		},
+		"unclosed string": {
+			expression:  `os.name == "linux`,
+			expectedErr: true,
+		},
+		"unclosed paren": {
+			expression:  `(os.name == "linux"`,
+			expectedErr: true,
+		},
+		"missing operand": {
+			expression:  `os.name ==`,
+			expectedErr: true,
+		},
+		"single equal": {
+			expression:  `os.name = "linux"`,
+			expectedErr: true,
+		},
+		"invalid regexp": {
+			expression:  `os.name =~ "("`,
+			expectedErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := Evaluate(c.expression, vars)
+
+			if c.expectedErr {
+				if err == nil {
+					t.Errorf("%s case is expected to be failure but not: %t", name, actual)
+				}
+
+				return
+			} else if err != nil {
+				t.Fatalf("%s case is expected to be success but not: %v", name, err)
+			}
+
+			if c.expected != actual {
+				t.Errorf("%t is expected but %t", c.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_Validate(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		expression string
+
+		expectedErr bool
+	}{
+		"empty": {
+			expression: "",
+		},
+		"known identifiers": {
+			expression: `env.BRANCH == "main" && file.ext == "apk" && file.name != "" && file.path != "" && os.name == "linux"`,
+		},
+		"typo without scope": {
+			expression:  `brnach == "main"`,
+			expectedErr: true,
+		},
+		"typo of scope": {
+			expression:  `evn.BRANCH == "main"`,
+			expectedErr: true,
+		},
+		"typo of name": {
+			expression:  `file.extt == "apk"`,
+			expectedErr: true,
+		},
+		"env without name": {
+			expression:  `env.`,
+			expectedErr: true,
+		},
+		"never evaluated": {
+			expression:  `false && file.size == "1"`,
+			expectedErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := Validate(c.expression); c.expectedErr && err == nil {
+				t.Errorf("%s case is expected to be failure", name)
+			} else if !c.expectedErr && err != nil {
+				t.Errorf("%s case is expected to be success but not: %v", name, err)
+			}
+		})
+	}
+}
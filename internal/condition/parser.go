@@ -0,0 +1,288 @@
+package condition
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdentifier
+	tokenString
+	tokenOperator
+	tokenLeftParen
+	tokenRightParen
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	index int
+}
+
+func tokenize(expression string) ([]token, error) {
+	var tokens []token
+
+	runes := []rune(expression)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLeftParen, text: "(", index: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRightParen, text: ")", index: i})
+			i++
+		case r == '"' || r == '\'':
+			var b strings.Builder
+			start := i
+			i++
+
+			for ; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+
+				b.WriteRune(runes[i])
+			}
+
+			if i >= len(runes) {
+				return nil, errors.New(fmt.Sprintf("a string literal at %d is not closed", start))
+			}
+
+			tokens = append(tokens, token{kind: tokenString, text: b.String(), index: start})
+			i++
+		case strings.ContainsRune("=!&|", r):
+			start := i
+
+			if i+1 < len(runes) {
+				if op := string(runes[i : i+2]); op == "==" || op == "!=" || op == "=~" || op == "&&" || op == "||" {
+					tokens = append(tokens, token{kind: tokenOperator, text: op, index: start})
+					i += 2
+					continue
+				}
+			}
+
+			if r != '!' {
+				return nil, errors.New(fmt.Sprintf("an unexpected character %q at %d", r, start))
+			}
+
+			tokens = append(tokens, token{kind: tokenOperator, text: "!", index: start})
+			i++
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+
+			for ; i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.'); i++ {
+			}
+
+			tokens = append(tokens, token{kind: tokenIdentifier, text: string(runes[start:i]), index: start})
+		default:
+			return nil, errors.New(fmt.Sprintf("an unexpected character %q at %d", r, i))
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, index: len(runes)}), nil
+}
+
+// parser is a recursive descent parser of the following grammar.
+//
+//	or         = and { "||" and }
+//	and        = unary { "&&" unary }
+//	unary      = "!" unary | comparison
+//	comparison = primary [ ( "==" | "!=" | "=~" ) primary ]
+//	primary    = "(" or ")" | string | "true" | "false" | identifier
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func parse(expression string) (node, error) {
+	if strings.TrimSpace(expression) == "" {
+		return nil, nil
+	}
+
+	tokens, err := tokenize(expression)
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s is not a valid expression", expression)
+	}
+
+	p := &parser{tokens: tokens}
+
+	n, err := p.or()
+
+	if err == nil && p.peek().kind != tokenEOF {
+		err = p.unexpected()
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s is not a valid expression", expression)
+	}
+
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *parser) accept(operators ...string) (string, bool) {
+	if t := p.peek(); t.kind == tokenOperator {
+		for _, op := range operators {
+			if t.text == op {
+				p.pos++
+				return op, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+func (p *parser) unexpected() error {
+	if t := p.peek(); t.kind == tokenEOF {
+		return errors.New("an unexpected end of the expression")
+	} else {
+		return errors.New(fmt.Sprintf("an unexpected token %q at %d", t.text, t.index))
+	}
+}
+
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if _, ok := p.accept("||"); !ok {
+			return left, nil
+		}
+
+		right, err := p.and()
+
+		if err != nil {
+			return nil, err
+		}
+
+		left = &binaryNode{operator: "||", left: left, right: right}
+	}
+}
+
+func (p *parser) and() (node, error) {
+	left, err := p.unary()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if _, ok := p.accept("&&"); !ok {
+			return left, nil
+		}
+
+		right, err := p.unary()
+
+		if err != nil {
+			return nil, err
+		}
+
+		left = &binaryNode{operator: "&&", left: left, right: right}
+	}
+}
+
+func (p *parser) unary() (node, error) {
+	if _, ok := p.accept("!"); ok {
+		operand, err := p.unary()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &notNode{operand: operand}, nil
+	}
+
+	return p.comparison()
+}
+
+func (p *parser) comparison() (node, error) {
+	left, err := p.primary()
+
+	if err != nil {
+		return nil, err
+	}
+
+	op, ok := p.accept("==", "!=", "=~")
+
+	if !ok {
+		return left, nil
+	}
+
+	right, err := p.primary()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &binaryNode{operator: op, left: left, right: right}, nil
+}
+
+func (p *parser) primary() (node, error) {
+	switch t := p.peek(); t.kind {
+	case tokenLeftParen:
+		p.next()
+
+		n, err := p.or()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if p.peek().kind != tokenRightParen {
+			return nil, p.unexpected()
+		}
+
+		p.next()
+
+		return n, nil
+	case tokenString:
+		p.next()
+		return &literalNode{value: stringValue(t.text)}, nil
+	case tokenIdentifier:
+		p.next()
+
+		switch t.text {
+		case "true":
+			return &literalNode{value: boolValue(true)}, nil
+		case "false":
+			return &literalNode{value: boolValue(false)}, nil
+		default:
+			// reject unknown identifiers even if they are never evaluated
+			if err := checkIdentifier(t.text); err != nil {
+				return nil, err
+			}
+
+			return &identifierNode{name: t.text}, nil
+		}
+	default:
+		return nil, p.unexpected()
+	}
+}
@@ -0,0 +1,249 @@
+package config
+
+import (
+	"fmt"
+	"github.com/jmatsu/splitter/internal/logger"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const includeKey = "include" // paths of config files to be merged before the file.
+
+// LoadOptions decides which config files are loaded and how they are merged.
+type LoadOptions struct {
+	Paths         []string // config files that are merged in order. The default config file is used if empty.
+	AllowOverride bool     // later files can override deployments, services, groups and profiles of the same name.
+	Profile       string   // a profile to be applied. No profile is applied if empty.
+}
+
+// configLoader merges config files and their includes in order.
+type configLoader struct {
+	allowOverride bool
+	merged        rawConfig
+	origins       map[string]string // <section>.<name> or a top-level key -> the path of the file
+	loaded        []string          // absolute paths in the merged order
+	visiting      []string
+}
+
+func newConfigLoader(allowOverride bool) *configLoader {
+	return &configLoader{
+		allowOverride: allowOverride,
+		merged: rawConfig{
+			Deployments: map[string]interface{}{},
+			Services:    map[string]interface{}{},
+			Groups:      map[string][]string{},
+			Profiles:    map[string]ProfileConfig{},
+		},
+		origins: map[string]string{},
+	}
+}
+
+// load merges the included files and then the file.
+func (l *configLoader) load(path string) error {
+	path, err := filepath.Abs(path)
+
+	if err != nil {
+		return errors.Wrapf(err, "cannot resolve %s", path)
+	}
+
+	if slices.Contains(l.visiting, path) {
+		return errors.New(fmt.Sprintf("a cycle of includes is detected: %s -> %s", strings.Join(l.visiting, " -> "), path))
+	} else if slices.Contains(l.loaded, path) {
+		logger.Logger.Debug().Msgf("%s has been already loaded", path)
+		return nil
+	}
+
+	logger.Logger.Debug().Msgf("Loading a config file on %s", path)
+
+	l.visiting = append(l.visiting, path)
+	defer func() {
+		l.visiting = l.visiting[:len(l.visiting)-1]
+	}()
+
+	layer, includes, err := readConfigFile(path)
+
+	if err != nil {
+		return err
+	}
+
+	for _, include := range includes {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+
+		if err := l.load(include); err != nil {
+			return errors.Wrapf(err, "cannot include %s from %s", include, path)
+		}
+	}
+
+	if err := l.merge(path, layer); err != nil {
+		return err
+	}
+
+	l.loaded = append(l.loaded, path)
+
+	return nil
+}
+
+// merge puts the values of the layer on the merged values. Names in each section must be unique unless allowOverride is true.
+func (l *configLoader) merge(path string, layer rawConfig) error {
+	sections := []struct {
+		key    string
+		names  []string
+		assign func(name string)
+	}{
+		{
+			key:   deploymentsKey,
+			names: maps.Keys(layer.Deployments),
+			assign: func(name string) {
+				l.merged.Deployments[name] = layer.Deployments[name]
+			},
+		},
+		{
+			key:   serviceDefinitionsKey,
+			names: maps.Keys(layer.Services),
+			assign: func(name string) {
+				l.merged.Services[name] = layer.Services[name]
+			},
+		},
+		{
+			key:   groupsKey,
+			names: maps.Keys(layer.Groups),
+			assign: func(name string) {
+				l.merged.Groups[name] = layer.Groups[name]
+			},
+		},
+		{
+			key:   profilesKey,
+			names: maps.Keys(layer.Profiles),
+			assign: func(name string) {
+				l.merged.Profiles[name] = layer.Profiles[name]
+			},
+		},
+	}
+
+	for _, section := range sections {
+		slices.Sort(section.names)
+
+		for _, name := range section.names {
+			key := fmt.Sprintf("%s.%s", section.key, name)
+
+			if origin, ok := l.origins[key]; ok {
+				if !l.allowOverride {
+					return errors.New(fmt.Sprintf("%s is defined in both of %s and %s. Allow overriding if it's intended", key, origin, path))
+				}
+
+				logger.Logger.Debug().Msgf("%s of %s is overridden by %s", key, origin, path)
+			}
+
+			section.assign(name)
+			l.origins[key] = path
+		}
+	}
+
+	for _, v := range []struct {
+		key   string
+		value string
+		dest  *string
+	}{
+		{key: "format-style", value: layer.FormatStyle, dest: &l.merged.FormatStyle},
+		{key: "network-timeout", value: layer.NetworkTimeout, dest: &l.merged.NetworkTimeout},
+		{key: "wait-timeout", value: layer.WaitTimeout, dest: &l.merged.WaitTimeout},
+		{key: "history-dir", value: layer.HistoryDir, dest: &l.merged.HistoryDir},
+	} {
+		if v.value != "" {
+			*v.dest = v.value
+			l.origins[v.key] = path
+		}
+	}
+
+	for key, set := range map[string]bool{
+		"max-attempts":       layer.Retry.MaxAttempts != 0,
+		"base-delay":         layer.Retry.BaseDelay != "",
+		"max-delay":          layer.Retry.MaxDelay != "",
+		"retryable-statuses": len(layer.Retry.RetryableStatuses) > 0,
+	} {
+		if set {
+			l.origins[fmt.Sprintf("%s.%s", retryKey, key)] = path
+		}
+	}
+
+	l.merged.Retry = l.merged.Retry.Merge(&layer.Retry)
+
+	return nil
+}
+
+// readConfigFile reads the values and the includes of the file.
+func readConfigFile(path string) (rawConfig, []string, error) {
+	v := viper.New()
+
+	_, extName, _ := strings.Cut(DefaultConfigName, ".")
+
+	v.SetConfigFile(path)
+	v.SetConfigType(extName)
+
+	if err := v.ReadInConfig(); err != nil {
+		return rawConfig{}, nil, errors.Wrapf(err, "failed to read %s", path)
+	}
+
+	values := rawConfig{
+		Deployments:    v.GetStringMap(deploymentsKey),
+		Services:       v.GetStringMap(serviceDefinitionsKey),
+		Groups:         v.GetStringMapStringSlice(groupsKey),
+		FormatStyle:    v.GetString("format-style"),
+		WaitTimeout:    v.GetString("wait-timeout"),
+		NetworkTimeout: v.GetString("network-timeout"),
+		HistoryDir:     v.GetString("history-dir"),
+	}
+
+	if retry := v.Get(retryKey); retry != nil {
+		if bytes, err := yaml.Marshal(retry); err != nil {
+			return rawConfig{}, nil, errors.Wrapf(err, "cannot load the retry policy of %s", path)
+		} else if err := yaml.Unmarshal(bytes, &values.Retry); err != nil {
+			return rawConfig{}, nil, errors.Wrapf(err, "cannot load the retry policy of %s", path)
+		}
+	}
+
+	if profiles := v.Get(profilesKey); profiles != nil {
+		if bytes, err := yaml.Marshal(profiles); err != nil {
+			return rawConfig{}, nil, errors.Wrapf(err, "cannot load the profiles of %s", path)
+		} else if err := yaml.Unmarshal(bytes, &values.Profiles); err != nil {
+			return rawConfig{}, nil, errors.Wrapf(err, "cannot load the profiles of %s", path)
+		}
+	}
+
+	return values, v.GetStringSlice(includeKey), nil
+}
+
+// defaultConfigPaths returns the default config file in the current directory if exists. Any extension that viper supports is accepted.
+func defaultConfigPaths() []string {
+	wd, err := os.Getwd()
+
+	if err != nil {
+		logger.Logger.Debug().Err(err).Msgf("Cannot loading the current working directory")
+		return nil
+	}
+
+	baseName, _, _ := strings.Cut(DefaultConfigName, ".")
+
+	for _, ext := range viper.SupportedExts {
+		if path := filepath.Join(wd, fmt.Sprintf("%s.%s", baseName, ext)); fileExists(path) {
+			return []string{path}
+		}
+	}
+
+	logger.Logger.Debug().Msgf("No config file is found on the current directory: %s", wd)
+
+	return nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
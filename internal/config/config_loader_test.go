@@ -0,0 +1,219 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_configLoader_load(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		files         map[string]string
+		paths         []string
+		allowOverride bool
+
+		expectedErr         bool
+		expectedDeployments []string
+		expectedServices    []string
+		expectedFormatStyle string
+		expectedLoaded      []string
+		expectedOrigins     map[string]string
+	}{
+		"single file": {
+			files: map[string]string{
+				"splitter.yml": `
+deployments:
+  app1:
+    service: local
+    destination-path: out
+`,
+			},
+			paths:               []string{"splitter.yml"},
+			expectedDeployments: []string{"app1"},
+			expectedLoaded:      []string{"splitter.yml"},
+		},
+		"includes": {
+			files: map[string]string{
+				"shared/services.yml": `
+services:
+  uploader:
+    endpoint: https://example.com
+format-style: raw
+`,
+				"app/splitter.yml": `
+include:
+  - ../shared/services.yml
+deployments:
+  app1:
+    service: uploader
+format-style: markdown
+`,
+			},
+			paths:               []string{"app/splitter.yml"},
+			expectedDeployments: []string{"app1"},
+			expectedServices:    []string{"uploader"},
+			expectedFormatStyle: MarkdownFormat,
+			expectedLoaded:      []string{"shared/services.yml", "app/splitter.yml"},
+			expectedOrigins: map[string]string{
+				"services.uploader": "shared/services.yml",
+				"deployments.app1":  "app/splitter.yml",
+				"format-style":      "app/splitter.yml",
+			},
+		},
+		"diamond includes": {
+			files: map[string]string{
+				"base.yml": `
+services:
+  uploader:
+    endpoint: https://example.com
+`,
+				"a.yml": `
+include: [base.yml]
+deployments:
+  a:
+    service: uploader
+`,
+				"b.yml": `
+include: [base.yml]
+deployments:
+  b:
+    service: uploader
+`,
+			},
+			paths:               []string{"a.yml", "b.yml"},
+			expectedDeployments: []string{"a", "b"},
+			expectedServices:    []string{"uploader"},
+			expectedLoaded:      []string{"base.yml", "a.yml", "b.yml"},
+		},
+		"cycle": {
+			files: map[string]string{
+				"a.yml": `include: [b.yml]`,
+				"b.yml": `include: [a.yml]`,
+			},
+			paths:       []string{"a.yml"},
+			expectedErr: true,
+		},
+		"conflicts": {
+			files: map[string]string{
+				"a.yml": `
+deployments:
+  app1:
+    service: local
+    destination-path: a
+`,
+				"b.yml": `
+deployments:
+  app1:
+    service: local
+    destination-path: b
+`,
+			},
+			paths:       []string{"a.yml", "b.yml"},
+			expectedErr: true,
+		},
+		"override": {
+			files: map[string]string{
+				"a.yml": `
+deployments:
+  app1:
+    service: local
+    destination-path: a
+`,
+				"b.yml": `
+deployments:
+  app1:
+    service: local
+    destination-path: b
+`,
+			},
+			paths:               []string{"a.yml", "b.yml"},
+			allowOverride:       true,
+			expectedDeployments: []string{"app1"},
+			expectedLoaded:      []string{"a.yml", "b.yml"},
+			expectedOrigins: map[string]string{
+				"deployments.app1": "b.yml",
+			},
+		},
+		"missing include": {
+			files: map[string]string{
+				"a.yml": `include: [missing.yml]`,
+			},
+			paths:       []string{"a.yml"},
+			expectedErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+
+			for path, content := range c.files {
+				path = filepath.Join(dir, path)
+
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("failed to prepare a directory: %v", err)
+				} else if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatalf("failed to prepare a file: %v", err)
+				}
+			}
+
+			loader := newConfigLoader(c.allowOverride)
+
+			var err error
+
+			for _, path := range c.paths {
+				if err = loader.load(filepath.Join(dir, path)); err != nil {
+					break
+				}
+			}
+
+			if c.expectedErr {
+				if err == nil {
+					t.Errorf("%s case is expected to fail", name)
+				}
+
+				return
+			} else if err != nil {
+				t.Fatalf("%s case is expected to succeed but %v", name, err)
+			}
+
+			for _, name := range c.expectedDeployments {
+				if _, ok := loader.merged.Deployments[name]; !ok {
+					t.Errorf("%s deployment is expected to be merged", name)
+				}
+			}
+
+			for _, name := range c.expectedServices {
+				if _, ok := loader.merged.Services[name]; !ok {
+					t.Errorf("%s service is expected to be merged", name)
+				}
+			}
+
+			if c.expectedFormatStyle != "" && loader.merged.FormatStyle != c.expectedFormatStyle {
+				t.Errorf("%s is expected but %s", c.expectedFormatStyle, loader.merged.FormatStyle)
+			}
+
+			var expectedLoaded []string
+
+			for _, path := range c.expectedLoaded {
+				expectedLoaded = append(expectedLoaded, filepath.Join(dir, path))
+			}
+
+			if !reflect.DeepEqual(loader.loaded, expectedLoaded) {
+				t.Errorf("%v are expected but %v", expectedLoaded, loader.loaded)
+			}
+
+			for key, path := range c.expectedOrigins {
+				if expected := filepath.Join(dir, path); loader.origins[key] != expected {
+					t.Errorf("%s is expected to come from %s but %s", key, expected, loader.origins[key])
+				}
+			}
+		})
+	}
+}
@@ -4,7 +4,8 @@ type CustomServiceConfig struct {
 	serviceNameHolder `yaml:",inline"`
 	ExecutionConfig   `yaml:",inline"`
 
-	AuthToken string `yaml:"auth-token" required:"true"`
+	// An auth token of this service
+	AuthToken string `yaml:"auth-token" required:"true" secret:"true"`
 }
 
 func (c *CustomServiceConfig) Validate() error {
@@ -18,10 +18,18 @@ const (
 	QueryAssignFormatPrefix      valueAssignFormat = "query_params."
 )
 
+// CustomServiceDefinition defines how splitter sends requests to an unsupported service.
 type CustomServiceDefinition struct {
-	Endpoint                 string                   `yaml:"endpoint" required:"true"`
-	SourceFileFormat         valueAssignFormat        `yaml:"source-file-format" required:"true"`
-	AuthDefinition           CustomAuthDefinition     `yaml:"auth" required:"true"`
+	// The endpoint. e.g. https://example.com/path/to/endpoint
+	Endpoint string `yaml:"endpoint" required:"true"`
+
+	// How splitter sets a source file. form_params.<name> or request_body
+	SourceFileFormat valueAssignFormat `yaml:"source-file-format" required:"true"`
+
+	// How splitter sets a token.
+	AuthDefinition CustomAuthDefinition `yaml:"auth" required:"true"`
+
+	// Default values of requests.
 	DefaultRequestDefinition DefaultRequestDefinition `yaml:"default,omitempty"`
 }
 
@@ -77,8 +85,11 @@ func (d *CustomServiceDefinition) SourceFile() (string, string, error) {
 }
 
 type CustomAuthDefinition struct {
+	// form_params.<name>, query_params.<name> or headers.<name>
 	StyleFormat valueAssignFormat `yaml:"style-format" required:"true"`
-	ValueFormat string            `yaml:"value-format" required:"true"`
+
+	// The value format of tokens. This must include exact one %s. e.g. Bearer %s
+	ValueFormat string `yaml:"value-format" required:"true"`
 }
 
 func (d *CustomAuthDefinition) validate() error {
@@ -124,10 +135,11 @@ func (d *CustomAuthDefinition) AuthValue() (string, string, error) {
 	return "", "", errors.New(fmt.Sprintf("no authentication method is found in %s", d.StyleFormat))
 }
 
+// DefaultRequestDefinition may contain credentials so the secret sources are available.
 type DefaultRequestDefinition struct {
-	Headers    map[string]string   `yaml:"headers,omitempty"`
-	Queries    map[string][]string `yaml:"queries,omitempty"`
-	FormParams map[string]string   `yaml:"form-params,omitempty"`
+	Headers    map[string]string   `yaml:"headers,omitempty" credential:"true"`
+	Queries    map[string][]string `yaml:"queries,omitempty" credential:"true"`
+	FormParams map[string]string   `yaml:"form-params,omitempty" credential:"true"`
 }
 
 func (d *DefaultRequestDefinition) validate() error {
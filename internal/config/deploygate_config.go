@@ -9,10 +9,10 @@ type DeployGateConfig struct {
 	AppOwnerName string `yaml:"app-owner-name" env:"DEPLOYGATE_APP_OWNER_NAME" required:"true"`
 
 	// API token of the app owner or who has permission to use their namespace.
-	ApiToken string `yaml:"api-token" env:"DEPLOYGATE_API_TOKEN" required:"true"`
+	ApiToken string `yaml:"api-token" env:"DEPLOYGATE_API_TOKEN" required:"true" secret:"true"`
 
 	// The existing access key of the distribution
-	DistributionAccessKey string `yaml:"distribution-access-key,omitempty" env:"DEPLOYGATE_DISTRIBUTION_KEY"`
+	DistributionAccessKey string `yaml:"distribution-access-key,omitempty" env:"DEPLOYGATE_DISTRIBUTION_KEY" credential:"true"`
 
 	// A name of a distribution
 	DistributionName string `yaml:"distribution-name,omitempty" env:"DEPLOYGATE_DISTRIBUTION_NAME"`
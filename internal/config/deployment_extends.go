@@ -0,0 +1,126 @@
+package config
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+	"strings"
+)
+
+const extendsKey = "extends"   // a key to inherit another deployment's values.
+const abstractKey = "abstract" // a key to declare a deployment that is only extended by other deployments.
+
+// isAbstract returns true if the deployment is only extended by other deployments. Abstract deployments are not loaded, so they don't have to be complete.
+func (c *GlobalConfig) isAbstract(name string) (bool, error) {
+	values, _ := c.rawConfig.Deployments[name].(map[string]interface{})
+
+	v, found := values[abstractKey]
+
+	if !found {
+		return false, nil
+	}
+
+	abstract, correct := v.(bool)
+
+	if !correct {
+		return false, errors.New(fmt.Sprintf("%s of %s must be a boolean", abstractKey, name))
+	}
+
+	return abstract, nil
+}
+
+// Resolve the raw values of the deployment. If the deployment extends another deployment, the parent's values are deep-merged into the deployment's values.
+func (c *GlobalConfig) resolveDeploymentValues(name string, visiting []string) (map[string]interface{}, error) {
+	if slices.Contains(visiting, name) {
+		return nil, errors.New(fmt.Sprintf("a cycle of extends is detected: %s -> %s", strings.Join(visiting, " -> "), name))
+	}
+
+	visiting = append(visiting, name)
+
+	raw, found := c.rawConfig.Deployments[name]
+
+	if !found {
+		return nil, errors.New(fmt.Sprintf("%s deployment is not found", name))
+	}
+
+	values, correct := raw.(map[string]interface{})
+
+	if !correct {
+		return nil, errors.New(fmt.Sprintf("%s must be Mapping", name))
+	}
+
+	overlay, err := c.profileOverlay(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the profile cannot override the service whether it is the deployment's own or inherited
+	service, hasService := values["service"]
+	values = deepMerge(values, overlay)
+
+	parent, found := values[extendsKey]
+
+	if !found {
+		if err := c.checkProfileService(name, overlay, service); err != nil {
+			return nil, err
+		}
+
+		return values, nil
+	}
+
+	parentName, correct := parent.(string)
+
+	if !correct || parentName == "" {
+		return nil, errors.New(fmt.Sprintf("%s of %s must be a deployment name", extendsKey, name))
+	}
+
+	parentValues, err := c.resolveDeploymentValues(parentName, visiting)
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s cannot extend %s", name, parentName)
+	}
+
+	if !hasService {
+		service = parentValues["service"]
+	}
+
+	if err := c.checkProfileService(name, overlay, service); err != nil {
+		return nil, err
+	}
+
+	// an abstract parent may leave the service to its children
+	if parentService, found := parentValues["service"]; found {
+		if v, found := values["service"]; found && v != parentService {
+			return nil, errors.New(fmt.Sprintf("%s cannot override the service of %s: %v -> %v", name, parentName, parentService, v))
+		}
+	}
+
+	merged := deepMerge(parentValues, values)
+	delete(merged, extendsKey)
+	delete(merged, abstractKey) // abstract is not inherited
+
+	return merged, nil
+}
+
+// Merge the both of maps into a new map. Values of the overlay take priority over the base except nested maps that are merged recursively.
+func deepMerge(base map[string]interface{}, overlay map[string]interface{}) map[string]interface{} {
+	merged := map[string]interface{}{}
+
+	for key, value := range base {
+		merged[key] = value
+	}
+
+	for key, value := range overlay {
+		if b, ok := merged[key].(map[string]interface{}); ok {
+			if o, ok := value.(map[string]interface{}); ok {
+				merged[key] = deepMerge(b, o)
+				continue
+			}
+		}
+
+		merged[key] = value
+	}
+
+	return merged
+}
@@ -0,0 +1,289 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_Config_resolveDeploymentValues(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		deployments map[string]interface{}
+		name        string
+		expected    map[string]interface{}
+	}{
+		"no extends": {
+			deployments: map[string]interface{}{
+				"def1": map[string]interface{}{
+					"service":        DeploygateService,
+					"app-owner-name": "owner",
+				},
+			},
+			name: "def1",
+			expected: map[string]interface{}{
+				"service":        DeploygateService,
+				"app-owner-name": "owner",
+			},
+		},
+		"single level": {
+			deployments: map[string]interface{}{
+				"base": map[string]interface{}{
+					"service":        DeploygateService,
+					"app-owner-name": "owner",
+					"api-token":      "token",
+					"pre-steps":      []interface{}{[]interface{}{"echo", "base"}},
+				},
+				"def1": map[string]interface{}{
+					"extends":           "base",
+					"distribution-name": "def1",
+				},
+			},
+			name: "def1",
+			expected: map[string]interface{}{
+				"service":           DeploygateService,
+				"app-owner-name":    "owner",
+				"api-token":         "token",
+				"distribution-name": "def1",
+				"pre-steps":         []interface{}{[]interface{}{"echo", "base"}},
+			},
+		},
+		"multi level": {
+			deployments: map[string]interface{}{
+				"base": map[string]interface{}{
+					"service":        DeploygateService,
+					"app-owner-name": "owner",
+					"api-token":      "token",
+				},
+				"middle": map[string]interface{}{
+					"extends":   "base",
+					"api-token": "middle-token",
+				},
+				"def1": map[string]interface{}{
+					"extends":           "middle",
+					"service":           DeploygateService,
+					"distribution-name": "def1",
+				},
+			},
+			name: "def1",
+			expected: map[string]interface{}{
+				"service":           DeploygateService,
+				"app-owner-name":    "owner",
+				"api-token":         "middle-token",
+				"distribution-name": "def1",
+			},
+		},
+		"nested maps": {
+			deployments: map[string]interface{}{
+				"base": map[string]interface{}{
+					"service": LocalService,
+					"nested": map[string]interface{}{
+						"key1": "base1",
+						"key2": "base2",
+					},
+				},
+				"def1": map[string]interface{}{
+					"extends": "base",
+					"nested": map[string]interface{}{
+						"key2": "def1",
+					},
+				},
+			},
+			name: "def1",
+			expected: map[string]interface{}{
+				"service": LocalService,
+				"nested": map[string]interface{}{
+					"key1": "base1",
+					"key2": "def1",
+				},
+			},
+		},
+		"abstract parent without service": {
+			deployments: map[string]interface{}{
+				"base": map[string]interface{}{
+					"abstract": true,
+					"retry": map[string]interface{}{
+						"max-attempts": 3,
+					},
+				},
+				"def1": map[string]interface{}{
+					"extends": "base",
+					"service": DeploygateService,
+				},
+			},
+			name: "def1",
+			expected: map[string]interface{}{
+				"service": DeploygateService,
+				"retry": map[string]interface{}{
+					"max-attempts": 3,
+				},
+			},
+		},
+		"override service": {
+			deployments: map[string]interface{}{
+				"base": map[string]interface{}{
+					"service": DeploygateService,
+				},
+				"def1": map[string]interface{}{
+					"extends": "base",
+					"service": LocalService,
+				},
+			},
+			name:     "def1",
+			expected: nil,
+		},
+		"cycle": {
+			deployments: map[string]interface{}{
+				"def1": map[string]interface{}{
+					"extends": "def2",
+					"service": DeploygateService,
+				},
+				"def2": map[string]interface{}{
+					"extends": "def1",
+				},
+			},
+			name:     "def1",
+			expected: nil,
+		},
+		"self": {
+			deployments: map[string]interface{}{
+				"def1": map[string]interface{}{
+					"extends": "def1",
+					"service": DeploygateService,
+				},
+			},
+			name:     "def1",
+			expected: nil,
+		},
+		"unknown parent": {
+			deployments: map[string]interface{}{
+				"def1": map[string]interface{}{
+					"extends": "unknown",
+					"service": DeploygateService,
+				},
+			},
+			name:     "def1",
+			expected: nil,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := GlobalConfig{
+				rawConfig: rawConfig{
+					Deployments: c.deployments,
+				},
+			}
+
+			actual, err := config.resolveDeploymentValues(c.name, nil)
+
+			if c.expected == nil {
+				if err == nil {
+					t.Errorf("%s case is expected to be failure but not: %v", name, actual)
+				}
+
+				return
+			} else if err != nil {
+				t.Fatalf("%s case is expected to be success but not: %v", name, err)
+			}
+
+			if !reflect.DeepEqual(c.expected, actual) {
+				t.Errorf("%v is expected but %v", c.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_Config_configure_extends(t *testing.T) {
+	config := GlobalConfig{
+		rawConfig: rawConfig{
+			Deployments: map[string]interface{}{
+				"base": map[string]interface{}{
+					"service":        DeploygateService,
+					"app-owner-name": "owner",
+					"api-token":      "token",
+				},
+				"def1": map[string]interface{}{
+					"extends":           "base",
+					"distribution-name": "def1",
+				},
+			},
+		},
+	}
+
+	if err := config.configure(); err != nil {
+		t.Fatalf("failed to configure: %v", err)
+	}
+
+	expected := DeployGateConfig{
+		serviceNameHolder: serviceNameHolder{
+			Name: DeploygateService,
+		},
+		AppOwnerName:     "owner",
+		ApiToken:         "token",
+		DistributionName: "def1",
+	}
+
+	if actual := config.deployments["def1"].ServiceConfig; !reflect.DeepEqual(expected, actual) {
+		t.Errorf("%v is expected but %v", expected, actual)
+	}
+}
+
+func Test_Config_configure_abstract(t *testing.T) {
+	cases := map[string]struct {
+		abstract interface{}
+
+		expectedErr bool
+	}{
+		"abstract": {
+			abstract: true,
+		},
+		"not abstract": {
+			abstract:    false,
+			expectedErr: true,
+		},
+		"not a boolean": {
+			abstract:    "yes",
+			expectedErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		config := GlobalConfig{
+			rawConfig: rawConfig{
+				Deployments: map[string]interface{}{
+					"base": map[string]interface{}{
+						"abstract":       c.abstract,
+						"app-owner-name": "owner",
+					},
+					"def1": map[string]interface{}{
+						"extends":   "base",
+						"service":   DeploygateService,
+						"api-token": "token",
+					},
+				},
+			},
+		}
+
+		if err := config.configure(); c.expectedErr {
+			if err == nil {
+				t.Errorf("%s case is expected to fail", name)
+			}
+
+			continue
+		} else if err != nil {
+			t.Fatalf("%s case is expected to succeed but %v", name, err)
+		}
+
+		if _, found := config.deployments["base"]; found {
+			t.Errorf("%s case: an abstract deployment must not be loaded", name)
+		}
+
+		if _, _, err := config.Deployment("def1"); err != nil {
+			t.Errorf("%s case: def1 is expected to be loaded but %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"github.com/jmatsu/splitter/internal/logger"
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+	"strings"
+)
+
+// Expand all groups into deployment names. A member of a group can be either a deployment name or another group name.
+func (c *GlobalConfig) configureGroups() error {
+	c.groups = map[string][]string{}
+
+	for name := range c.rawConfig.Groups {
+		logger.Logger.Debug().Msgf("Configuring the group of %s", name)
+
+		if _, ok := c.deployments[name]; ok {
+			return errors.New(fmt.Sprintf("%s is used as both of a group name and a deployment name", name))
+		}
+
+		if members, err := c.expandGroup(name, nil); err != nil {
+			return errors.Wrapf(err, "%s group is invalid", name)
+		} else {
+			c.groups[name] = members
+		}
+	}
+
+	return nil
+}
+
+func (c *GlobalConfig) expandGroup(name string, visiting []string) ([]string, error) {
+	if slices.Contains(visiting, name) {
+		return nil, errors.New(fmt.Sprintf("a cycle is detected: %s -> %s", strings.Join(visiting, " -> "), name))
+	}
+
+	visiting = append(visiting, name)
+
+	var members []string
+
+	for _, member := range c.rawConfig.Groups[name] {
+		var expanded []string
+
+		if _, ok := c.deployments[member]; ok {
+			expanded = []string{member}
+		} else if _, ok := c.rawConfig.Groups[member]; ok {
+			if v, err := c.expandGroup(member, visiting); err != nil {
+				return nil, err
+			} else {
+				expanded = v
+			}
+		} else {
+			return nil, errors.New(fmt.Sprintf("%s is neither a deployment nor a group", member))
+		}
+
+		for _, v := range expanded {
+			if !slices.Contains(members, v) {
+				members = append(members, v)
+			}
+		}
+	}
+
+	if len(members) == 0 {
+		return nil, errors.New(fmt.Sprintf("%s has no members", name))
+	}
+
+	return members, nil
+}
+
+// Group returns deployment names that belong to the group.
+func (c *GlobalConfig) Group(name string) ([]string, error) {
+	if members, ok := c.groups[name]; ok {
+		return members, nil
+	} else {
+		return nil, errors.New(fmt.Sprintf("%s group is not found", name))
+	}
+}
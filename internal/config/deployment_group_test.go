@@ -0,0 +1,132 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_Config_configureGroups(t *testing.T) {
+	t.Parallel()
+
+	deployments := map[string]interface{}{
+		"def1": map[string]interface{}{
+			"service":          LocalService,
+			"destination-path": "def1-destination-path",
+		},
+		"def2": map[string]interface{}{
+			"service":          LocalService,
+			"destination-path": "def2-destination-path",
+		},
+		"def3": map[string]interface{}{
+			"service":          LocalService,
+			"destination-path": "def3-destination-path",
+		},
+	}
+
+	cases := map[string]struct {
+		groups   map[string][]string
+		expected map[string][]string
+	}{
+		"flat": {
+			groups: map[string][]string{
+				"group1": {"def1", "def2"},
+			},
+			expected: map[string][]string{
+				"group1": {"def1", "def2"},
+			},
+		},
+		"nested": {
+			groups: map[string][]string{
+				"group1": {"def1", "group2"},
+				"group2": {"def2", "def3"},
+			},
+			expected: map[string][]string{
+				"group1": {"def1", "def2", "def3"},
+				"group2": {"def2", "def3"},
+			},
+		},
+		"duplicated members": {
+			groups: map[string][]string{
+				"group1": {"def1", "group2", "def2"},
+				"group2": {"def2", "def1"},
+			},
+			expected: map[string][]string{
+				"group1": {"def1", "def2"},
+				"group2": {"def2", "def1"},
+			},
+		},
+		"unknown member": {
+			groups: map[string][]string{
+				"group1": {"def1", "unknown"},
+			},
+			expected: nil,
+		},
+		"self cycle": {
+			groups: map[string][]string{
+				"group1": {"def1", "group1"},
+			},
+			expected: nil,
+		},
+		"indirect cycle": {
+			groups: map[string][]string{
+				"group1": {"group2"},
+				"group2": {"group3"},
+				"group3": {"def1", "group1"},
+			},
+			expected: nil,
+		},
+		"name conflicts with deployment": {
+			groups: map[string][]string{
+				"def1": {"def2"},
+			},
+			expected: nil,
+		},
+		"empty group": {
+			groups: map[string][]string{
+				"group1": {},
+			},
+			expected: nil,
+		},
+		"zero": {
+			expected: map[string][]string{},
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := GlobalConfig{
+				rawConfig: rawConfig{
+					Deployments: deployments,
+					Groups:      c.groups,
+				},
+			}
+
+			err := config.configure()
+
+			if c.expected == nil {
+				if err == nil {
+					t.Errorf("%s case is expected to be failure but not", name)
+				}
+
+				return
+			} else if err != nil {
+				t.Fatalf("%s case is expected to be success but not: %v", name, err)
+			}
+
+			if !reflect.DeepEqual(c.expected, config.groups) {
+				t.Errorf("%v is expected but %v", c.expected, config.groups)
+			}
+
+			for group, members := range c.expected {
+				if actual, err := config.Group(group); err != nil {
+					t.Errorf("%s is expected to be found but not: %v", group, err)
+				} else if !reflect.DeepEqual(members, actual) {
+					t.Errorf("%v is expected but %v", members, actual)
+				}
+			}
+		})
+	}
+}
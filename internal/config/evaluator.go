@@ -1,15 +1,89 @@
 package config
 
 import (
+	"encoding/base64"
 	"fmt"
 	"github.com/jmatsu/splitter/internal/logger"
+	"github.com/jmatsu/splitter/internal/secret"
 	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
 	"os"
+	"os/exec"
 	"reflect"
 	"strings"
 )
 
-// Evaluate the styled format for the embedded variables.
+// valueSource resolves a value that starts with <prefix>: in the config file.
+type valueSource func(e *evaluator, value string) (string, error)
+
+// secretSources are the prefixes that are available only for credential fields. Their values are secrets regardless of the fields.
+var secretSources = []string{"env", "file", "cmd", "base64"}
+
+// secrecy is how the evaluator treats values of a field. Nested values inherit it.
+type secrecy int
+
+const (
+	plainField      secrecy = iota
+	credentialField         // credential tag. The secret sources are available.
+	secretField             // secret tag. The secret sources are available and every value is a secret.
+)
+
+// of returns the secrecy of the field in a struct that has this secrecy.
+func (s secrecy) of(field reflect.StructField) secrecy {
+	if field.Tag.Get("secret") == "true" {
+		return secretField
+	} else if s < credentialField && field.Tag.Get("credential") == "true" {
+		return credentialField
+	}
+
+	return s
+}
+
+// valueSources are keyed by the prefix.
+var valueSources = map[string]valueSource{
+	"format": func(e *evaluator, format string) (string, error) {
+		return expandFormat(format, e.lookupEnv)
+	},
+	"env": func(e *evaluator, name string) (string, error) {
+		if value, found := e.lookupEnv(name); found {
+			return value, nil
+		}
+
+		return "", &unsetVariableError{name: name}
+	},
+	"file": func(e *evaluator, path string) (string, error) {
+		if bytes, err := e.readFile(path); err != nil {
+			return "", errors.Wrapf(err, "failed to read %s", path)
+		} else {
+			return strings.TrimRight(string(bytes), "\r\n"), nil
+		}
+	},
+	"cmd": func(e *evaluator, commandLine string) (string, error) {
+		args, err := splitCommandLine(commandLine)
+
+		if err != nil {
+			return "", err
+		} else if len(args) == 0 {
+			return "", errors.New("a command is required")
+		}
+
+		if bytes, err := e.runCommand(args[0], args[1:]...); err != nil {
+			return "", errors.Wrapf(err, "%s failed to run", args[0])
+		} else {
+			return strings.TrimRight(string(bytes), "\r\n"), nil
+		}
+	},
+	"base64": func(e *evaluator, encoded string) (string, error) {
+		if bytes, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded)); err != nil {
+			return "", errors.Wrap(err, "failed to decode the value")
+		} else {
+			return string(bytes), nil
+		}
+	},
+}
+
+// Evaluate the styled format for the embedded variables and resolve values from the sources. Nested structs, pointers, slices and maps are evaluated recursively.
+// The given struct is overwritten by copies so slices and maps shared with others are never modified.
 func evaluateValues(v any) error {
 	vRef := reflect.ValueOf(v).Elem()
 
@@ -17,26 +91,368 @@ func evaluateValues(v any) error {
 		return errors.New(fmt.Sprintf("%v is not a struct", v))
 	}
 
-	for i := 0; i < vRef.NumField(); i++ {
-		value := vRef.Field(i)
-		field := vRef.Type().Field(i)
-		tag := vRef.Type().Field(i).Tag
+	e := newEvaluator()
+	evaluated := e.evaluate("", vRef, plainField)
 
-		if _, found := tag.Lookup("yaml"); !found {
-			continue
+	if num := len(e.errs); num > 0 {
+		return errors.New(fmt.Sprintf("%d values cannot be evaluated: %s", num, strings.Join(e.errs, ", ")))
+	}
+
+	vRef.Set(evaluated)
+
+	return nil
+}
+
+// newEvaluator returns an evaluator that resolves values from the environment, files and commands.
+func newEvaluator() *evaluator {
+	return &evaluator{
+		lookupEnv: os.LookupEnv,
+		readFile:  os.ReadFile,
+		runCommand: func(name string, args ...string) ([]byte, error) {
+			// stdout is never mirrored to logs unlike exec.CommandLine because it may be a secret
+			cmd := exec.Command(name, args...)
+			cmd.Stderr = os.Stderr
+
+			return cmd.Output()
+		},
+	}
+}
+
+type evaluator struct {
+	lookupEnv  func(name string) (string, bool)
+	readFile   func(path string) ([]byte, error)
+	runCommand func(name string, args ...string) ([]byte, error)
+	errs       []string // <field path>: <reason>
+	unset      []string // variables that are required but not set
+}
+
+// evaluate returns the evaluated copy of v. s is the secrecy of the field that v is a part of.
+// The secret sources are resolved only for credential fields so that values like file:... of the other fields are never reinterpreted.
+// Values of secret fields and values resolved from the secret sources are registered as secrets.
+func (e *evaluator) evaluate(path string, v reflect.Value, s secrecy) reflect.Value {
+	switch v.Kind() {
+	case reflect.String:
+		prefix, rest, ok := strings.Cut(v.String(), ":")
+		source, found := valueSources[prefix]
+
+		if found && s == plainField && slices.Contains(secretSources, prefix) {
+			logger.Logger.Debug().Msgf("%s: %s is available only for credentials", path, prefix)
+			found = false
+		}
+
+		if !ok || !found {
+			if s == secretField {
+				secret.Register(v.String())
+			}
+
+			logger.Logger.Debug().Msgf("%s = %v: needn't be evaluated", path, v)
+			return v
+		}
+
+		value, err := source(e, rest)
+
+		if err != nil {
+			e.errs = append(e.errs, fmt.Sprintf("%s: %v", path, err))
+
+			var unset *unsetVariableError
+
+			if errors.As(err, &unset) {
+				e.unset = append(e.unset, unset.name)
+			}
+
+			return v
+		}
+
+		// format is not a secret source. Otherwise values like "release" would be masked everywhere
+		if s == secretField || slices.Contains(secretSources, prefix) {
+			secret.Register(value)
+		}
+
+		// the value is never logged because it may be too short to be masked
+		logger.Logger.Debug().Msgf("%s: is evaluated from %s", path, prefix)
+
+		newValue := reflect.New(v.Type()).Elem()
+		newValue.SetString(value)
+
+		return newValue
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
 		}
 
-		if value.Kind() == reflect.String {
-			if prefix, format, ok := strings.Cut(value.String(), ":"); ok && prefix == "format" {
-				newValue := os.ExpandEnv(format)
-				value.SetString(newValue)
+		newValue := reflect.New(v.Type().Elem())
+		newValue.Elem().Set(e.evaluate(path, v.Elem(), s))
+
+		return newValue
+	case reflect.Struct:
+		newValue := reflect.New(v.Type()).Elem()
+		newValue.Set(v)
+
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			tag, found := field.Tag.Lookup("yaml")
+
+			if !found || !newValue.Field(i).CanSet() {
+				continue
+			}
+
+			key, options, _ := strings.Cut(tag, ",")
+			s := s.of(field)
 
-				logger.Logger.Debug().Msgf("%s = %v: is evaluated", field.Name, newValue)
+			if key == "-" {
+				continue
+			} else if strings.Contains(options, "inline") {
+				newValue.Field(i).Set(e.evaluate(path, v.Field(i), s))
+			} else if path == "" {
+				newValue.Field(i).Set(e.evaluate(key, v.Field(i), s))
 			} else {
-				logger.Logger.Debug().Msgf("%s = %v: needn't be evaluated", field.Name, value)
+				newValue.Field(i).Set(e.evaluate(path+"."+key, v.Field(i), s))
 			}
 		}
+
+		return newValue
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		newValue := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+
+		for i := 0; i < v.Len(); i++ {
+			newValue.Index(i).Set(e.evaluate(fmt.Sprintf("%s[%d]", path, i), v.Index(i), s))
+		}
+
+		return newValue
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+
+		newValue := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+
+		for iter.Next() {
+			newValue.SetMapIndex(iter.Key(), e.evaluate(fmt.Sprintf("%s.%v", path, iter.Key()), iter.Value(), s))
+		}
+
+		return newValue
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+
+		newValue := reflect.New(v.Type()).Elem()
+		newValue.Set(e.evaluate(path, v.Elem(), s))
+
+		return newValue
+	default:
+		return v
 	}
+}
 
-	return nil
+type unsetVariableError struct {
+	name string
+}
+
+func (e *unsetVariableError) Error() string {
+	return fmt.Sprintf("%s is not set", e.name)
+}
+
+// expandFormat expands variables in the format.
+//
+//	$$                   a literal $
+//	$NAME, ${NAME}       the value of NAME. NAME must be set.
+//	${NAME:-default}     the default value if NAME is unset or empty. The default value is expanded as well.
+//	${NAME:?message}     fails with the message if NAME is unset or empty.
+func expandFormat(format string, lookupEnv func(name string) (string, bool)) (string, error) {
+	var b strings.Builder
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '$' || i+1 == len(format) {
+			b.WriteByte(format[i])
+			continue
+		}
+
+		switch next := format[i+1]; {
+		case next == '$':
+			b.WriteByte('$')
+			i++
+		case next == '{':
+			end := closingBrace(format, i+2)
+
+			if end < 0 {
+				return "", errors.New(fmt.Sprintf("%s is not closed", format[i:]))
+			}
+
+			value, err := expandVariable(format[i+2:end], lookupEnv)
+
+			if err != nil {
+				return "", err
+			}
+
+			b.WriteString(value)
+			i = end
+		case isVariableNameChar(next, true):
+			end := i + 1
+
+			for end < len(format) && isVariableNameChar(format[end], false) {
+				end++
+			}
+
+			value, err := expandVariable(format[i+1:end], lookupEnv)
+
+			if err != nil {
+				return "", err
+			}
+
+			b.WriteString(value)
+			i = end - 1
+		default:
+			b.WriteByte('$')
+		}
+	}
+
+	return b.String(), nil
+}
+
+// expandVariable expands the inside of ${...}.
+func expandVariable(expr string, lookupEnv func(name string) (string, bool)) (string, error) {
+	end := 0
+
+	for end < len(expr) && isVariableNameChar(expr[end], end == 0) {
+		end++
+	}
+
+	name, operator := expr[:end], expr[end:]
+
+	if name == "" {
+		return "", errors.New(fmt.Sprintf("${%s} is a bad substitution", expr))
+	}
+
+	value, found := lookupEnv(name)
+
+	switch {
+	case operator == "":
+		if !found {
+			return "", &unsetVariableError{name: name}
+		}
+
+		return value, nil
+	case strings.HasPrefix(operator, ":-"):
+		if value == "" {
+			return expandFormat(operator[2:], lookupEnv)
+		}
+
+		return value, nil
+	case strings.HasPrefix(operator, ":?"):
+		var err error
+
+		if value != "" {
+			return value, nil
+		} else if found {
+			err = errors.New(fmt.Sprintf("%s is empty", name))
+		} else {
+			err = &unsetVariableError{name: name}
+		}
+
+		if message := operator[2:]; message != "" {
+			return "", errors.Wrap(err, message)
+		}
+
+		return "", err
+	default:
+		return "", errors.New(fmt.Sprintf("${%s} is a bad substitution", expr))
+	}
+}
+
+// splitCommandLine splits the command line into arguments like shells do. No shell is involved.
+//
+//	'...'     a literal string
+//	"..."     a string in which \" and \\ are escaped
+//	\c        the literal character c outside quotes
+func splitCommandLine(commandLine string) ([]string, error) {
+	var args []string
+	var b strings.Builder
+	var inArg bool
+
+	for i := 0; i < len(commandLine); i++ {
+		switch c := commandLine[i]; c {
+		case ' ', '\t', '\n', '\r':
+			if inArg {
+				args = append(args, b.String())
+				b.Reset()
+				inArg = false
+			}
+		case '\'':
+			end := strings.IndexByte(commandLine[i+1:], '\'')
+
+			if end < 0 {
+				return nil, errors.New(fmt.Sprintf("%s is not closed", commandLine[i:]))
+			}
+
+			b.WriteString(commandLine[i+1 : i+1+end])
+			i += end + 1
+			inArg = true
+		case '"':
+			closed := false
+
+			for i++; i < len(commandLine); i++ {
+				if commandLine[i] == '"' {
+					closed = true
+					break
+				} else if commandLine[i] == '\\' && i+1 < len(commandLine) && (commandLine[i+1] == '"' || commandLine[i+1] == '\\') {
+					i++
+				}
+
+				b.WriteByte(commandLine[i])
+			}
+
+			if !closed {
+				return nil, errors.New(fmt.Sprintf("%s is not closed", commandLine))
+			}
+
+			inArg = true
+		case '\\':
+			if i+1 == len(commandLine) {
+				return nil, errors.New(fmt.Sprintf("%s ends with an escape", commandLine))
+			}
+
+			i++
+			b.WriteByte(commandLine[i])
+			inArg = true
+		default:
+			b.WriteByte(c)
+			inArg = true
+		}
+	}
+
+	if inArg {
+		args = append(args, b.String())
+	}
+
+	return args, nil
+}
+
+// closingBrace returns the index of } that closes ${ or -1. Nested ${...} in default values are skipped.
+func closingBrace(format string, start int) int {
+	depth := 0
+
+	for i := start; i < len(format); i++ {
+		switch format[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return i
+			}
+
+			depth--
+		}
+	}
+
+	return -1
+}
+
+func isVariableNameChar(c byte, first bool) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || !first && '0' <= c && c <= '9'
 }
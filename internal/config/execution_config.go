@@ -2,6 +2,19 @@ package config
 
 // ExecutionConfig represents pre-/post-hooks of each config
 type ExecutionConfig struct {
-	PreSteps  [][]string `yaml:"pre-steps,omitempty"`
+	// A boolean expression. The deployment is skipped if this is evaluated to false.
+	When string `yaml:"when,omitempty"`
+
+	// Skip files whose hash is same to the latest build of the deployment.
+	// Firebase App Distribution compares the latest release. The others compare the history, so the history directory must be cached or persistent on CI.
+	SkipIfUnchanged bool `yaml:"skip-if-unchanged,omitempty"`
+
+	// Command calls that are executed before the deployment. e.g. [["cmd", "arg1"]]
+	PreSteps [][]string `yaml:"pre-steps,omitempty"`
+
+	// Command calls that are executed after the successful deployment.
 	PostSteps [][]string `yaml:"post-steps,omitempty"`
+
+	// A retry policy of requests of this deployment. Each value overrides the global retry policy.
+	Retry *RetryConfig `yaml:"retry,omitempty"`
 }
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"fmt"
 	"github.com/jmatsu/splitter/internal/logger"
+	"github.com/jmatsu/splitter/internal/util"
+	"github.com/pkg/errors"
 	"strings"
 )
 
@@ -15,20 +18,33 @@ type FirebaseAppDistributionConfig struct {
 	AppId string `yaml:"app-id" required:"true"`
 
 	// Access token that has permission to use App Distribution
-	AccessToken string `yaml:"access-token,omitempty"`
+	AccessToken string `yaml:"access-token,omitempty" secret:"true"`
 
 	// A path to credentials file. If the both of this and access token are given, access token takes priority.
 	GoogleCredentialsPath string `yaml:"credentials-path" env:"GOOGLE_APPLICATION_CREDENTIALS"`
 
 	// A list of group aliases.
 	GroupAliases []string `yaml:"group-aliases,omitempty"`
+
+	// The size of each chunk of resumable uploads. e.g. 8MiB. This must be a multiple of 256KiB.
+	UploadChunkSize string `yaml:"upload-chunk-size,omitempty"`
 }
 
+const DefaultFirebaseUploadChunkSize = "8MiB"
+
 func (c *FirebaseAppDistributionConfig) Validate() error {
 	if err := validateMissingValues(c); err != nil {
 		return err
 	}
 
+	if c.UploadChunkSize != "" {
+		if v, err := util.ParseByteSize(c.UploadChunkSize); err != nil {
+			return errors.Wrap(err, "upload-chunk-size is invalid")
+		} else if v == 0 || v%firebaseUploadChunkGranularity != 0 {
+			return errors.New(fmt.Sprintf("upload-chunk-size must be a positive multiple of 256KiB but %s", c.UploadChunkSize))
+		}
+	}
+
 	if c.AccessToken == "" && c.GoogleCredentialsPath == "" {
 		logger.Logger.Warn().Msg("we recommend specifying a token or credentials path explicitly")
 	} else if c.AccessToken != "" && c.GoogleCredentialsPath != "" {
@@ -38,6 +54,22 @@ func (c *FirebaseAppDistributionConfig) Validate() error {
 	return nil
 }
 
+// The resumable upload protocol requires chunks to be a multiple of this size.
+const firebaseUploadChunkGranularity = 256 * 1024
+
+// ChunkSize returns the size of each chunk of resumable uploads in bytes.
+func (c *FirebaseAppDistributionConfig) ChunkSize() int64 {
+	value := DefaultFirebaseUploadChunkSize
+
+	if c.UploadChunkSize != "" {
+		value = c.UploadChunkSize
+	}
+
+	size, _ := util.ParseByteSize(value) // Validate() ensures the format
+
+	return size
+}
+
 func (c *FirebaseAppDistributionConfig) ProjectNumber() string {
 	// <num>:<project number>:<os>:<uid>
 	return strings.SplitN(c.AppId, ":", 3)[1]
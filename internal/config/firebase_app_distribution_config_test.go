@@ -37,3 +37,57 @@ func Test_FirebaseAppDistributionConfig_validateMissingValues(t *testing.T) {
 		})
 	}
 }
+
+func Test_FirebaseAppDistributionConfig_ChunkSize(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		chunkSize string
+
+		expected          int64
+		expectedValidness bool
+	}{
+		"default": {
+			expected:          8 * 1024 * 1024,
+			expectedValidness: true,
+		},
+		"multiple of 256KiB": {
+			chunkSize:         "512KiB",
+			expected:          512 * 1024,
+			expectedValidness: true,
+		},
+		"not multiple of 256KiB": {
+			chunkSize:         "1MB",
+			expectedValidness: false,
+		},
+		"zero": {
+			chunkSize:         "0",
+			expectedValidness: false,
+		},
+		"invalid": {
+			chunkSize:         "large",
+			expectedValidness: false,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := FirebaseAppDistributionConfig{
+				AppId:           "AppId",
+				AccessToken:     "AccessToken",
+				UploadChunkSize: c.chunkSize,
+			}
+
+			if err := config.Validate(); (err == nil) != c.expectedValidness {
+				t.Fatalf("%s case is expected to be %t but %v", name, c.expectedValidness, err)
+			}
+
+			if c.expectedValidness && config.ChunkSize() != c.expected {
+				t.Errorf("%d is expected but %d", c.expected, config.ChunkSize())
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"github.com/jmatsu/splitter/internal/condition"
 	"github.com/jmatsu/splitter/internal/logger"
 	"github.com/pkg/errors"
 	"golang.org/x/exp/slices"
@@ -9,19 +10,8 @@ import (
 	"os"
 	"strings"
 	"time"
-
-	"github.com/spf13/viper"
 )
 
-func init() {
-	baseName, extName, _ := strings.Cut(DefaultConfigName, ".")
-
-	viper.SetConfigName(baseName)
-	viper.SetConfigType(extName)
-
-	viper.SetEnvPrefix(envPrefix)
-}
-
 const (
 	envPrefix = "SPLITTER_" // The prefix of environment variables used for splitter's global options.
 
@@ -29,6 +19,8 @@ const (
 
 	deploymentsKey        = "deployments" // deployment definitions' key in the config file.
 	serviceDefinitionsKey = "services"    // service definitions' key in the config file.
+	groupsKey             = "groups"      // deployment groups' key in the config file.
+	retryKey              = "retry"       // a global retry policy's key in the config file.
 
 	DeploygateService              = "deploygate"                // represents DeployGateConfig
 	LocalService                   = "local"                     // represents LocalConfig
@@ -45,14 +37,47 @@ type GlobalConfig struct {
 	rawConfig   rawConfig
 	deployments map[string]Deployment
 	services    map[string]CustomServiceDefinition
+	groups      map[string][]string
+	dryRun      bool
+	profile     string            // the selected profile
+	problems    Problems          // problems found while loading the config file
+	sources     []string          // loaded config files in the merged order
+	origins     map[string]string // <section>.<name> or a top-level key -> the config file that defines it
+	options     []string          // top-level keys given by command-line options
 }
 
+// rawConfig is the structure of the config file.
 type rawConfig struct {
-	Deployments    map[string]interface{} `yaml:"deployments"`
-	Services       map[string]interface{} `yaml:"services"`
-	FormatStyle    string                 `yaml:"format-style,omitempty"`
-	NetworkTimeout string                 `yaml:"network-timeout,omitempty"`
-	WaitTimeout    string                 `yaml:"wait-timeout,omitempty"`
+	// Deployments by name. Each deployment must have service key or extend another deployment. An abstract deployment is only extended by other deployments.
+	Deployments map[string]interface{} `yaml:"deployments"`
+
+	// Custom services by name.
+	Services map[string]interface{} `yaml:"services"`
+
+	// Named groups of deployments. A member can be a deployment name or another group name.
+	Groups map[string][]string `yaml:"groups,omitempty"`
+
+	// Config files to be merged before this file. Relative paths are resolved from the directory of this file.
+	Include []string `yaml:"include,omitempty"`
+
+	// Profiles by name. A profile overrides values of existing deployments while it's selected.
+	Profiles map[string]ProfileConfig `yaml:"profiles,omitempty"`
+
+	// The output format. (default: pretty)
+	FormatStyle string `yaml:"format-style,omitempty" enum:"pretty,raw,markdown"`
+
+	// Read/connection timeout of requests. e.g. 10m
+	NetworkTimeout string `yaml:"network-timeout,omitempty"`
+
+	// Timeout for services' async-processing state. e.g. 5m
+	WaitTimeout string `yaml:"wait-timeout,omitempty"`
+
+	// A directory that contains the deployment history. (default: .splitter)
+	// Keep this directory cached or persistent on CI to make skip-if-unchanged effective.
+	HistoryDir string `yaml:"history-dir,omitempty"`
+
+	// A retry policy of requests. This is effective only for services that use HTTP.
+	Retry RetryConfig `yaml:"retry,omitempty"`
 }
 
 // Deployment holds a service name and its config struct
@@ -73,6 +98,7 @@ const (
 
 	DefaultNetworkTimeout = "10m"
 	DefaultWaitTimeout    = "5m"
+	DefaultHistoryDir     = ".splitter"
 )
 
 var styles = []FormatStyle{
@@ -89,14 +115,27 @@ func NewConfig() *GlobalConfig {
 
 func SetGlobalFormatStyle(value string) {
 	config.rawConfig.FormatStyle = value
+	config.options = append(config.options, "format-style")
 }
 
 func SetGlobalNetworkTimeout(value string) {
 	config.rawConfig.NetworkTimeout = value
+	config.options = append(config.options, "network-timeout")
 }
 
 func SetGlobalWaitTimeout(value string) {
 	config.rawConfig.WaitTimeout = value
+	config.options = append(config.options, "wait-timeout")
+}
+
+func SetGlobalHistoryDir(value string) {
+	config.rawConfig.HistoryDir = value
+	config.options = append(config.options, "history-dir")
+}
+
+// SetGlobalDryRun enables dry-run mode that never sends requests nor runs steps.
+func SetGlobalDryRun(value bool) {
+	config.dryRun = value
 }
 
 func CurrentConfig() *GlobalConfig {
@@ -104,32 +143,27 @@ func CurrentConfig() *GlobalConfig {
 	return config
 }
 
-func LoadGlobalConfig(path *string) error {
-	if path != nil {
-		viper.SetConfigFile(*path)
-		logger.Logger.Debug().Msgf("Loading a config file on %s", *path)
-	} else {
-		viper.AddConfigPath(".")
+// LoadGlobalConfig merges the config files and configures the global config.
+func LoadGlobalConfig(options LoadOptions) error {
+	paths := options.Paths
 
-		if wd, err := os.Getwd(); err == nil {
-			logger.Logger.Debug().Msgf("Loading a config file on the current directory: %s", wd)
-		} else {
-			logger.Logger.Debug().Err(err).Msgf("Cannot loading the current working directory")
-		}
+	if len(paths) == 0 {
+		paths = defaultConfigPaths()
 	}
 
-	if err := viper.ReadInConfig(); path != nil && err != nil {
-		return errors.Wrap(err, "failed to read a config file")
-	}
+	loader := newConfigLoader(options.AllowOverride)
 
-	config.rawConfig = rawConfig{
-		Deployments:    viper.GetStringMap(deploymentsKey),
-		Services:       viper.GetStringMap(serviceDefinitionsKey),
-		FormatStyle:    viper.GetString("format-style"),
-		WaitTimeout:    viper.GetString("wait-timeout"),
-		NetworkTimeout: viper.GetString("network-timeout"),
+	for _, path := range paths {
+		if err := loader.load(path); err != nil {
+			return errors.Wrap(err, "failed to read a config file")
+		}
 	}
 
+	config.rawConfig = loader.merged
+	config.sources = loader.loaded
+	config.origins = loader.origins
+	config.profile = options.Profile
+
 	if err := config.configure(); err != nil {
 		return errors.Wrap(err, "your config file may not contain some of required values or they are invalid")
 	}
@@ -137,6 +171,11 @@ func LoadGlobalConfig(path *string) error {
 	return nil
 }
 
+// Sources returns the loaded config files in the merged order.
+func (c *GlobalConfig) Sources() []string {
+	return c.sources
+}
+
 func (c *GlobalConfig) configure() error {
 	if c.deployments == nil {
 		c.deployments = map[string]Deployment{}
@@ -158,126 +197,209 @@ func (c *GlobalConfig) configure() error {
 		c.rawConfig.WaitTimeout = DefaultWaitTimeout
 	}
 
+	if c.rawConfig.HistoryDir == "" {
+		c.rawConfig.HistoryDir = DefaultHistoryDir
+	}
+
+	c.problems = nil
+
+	if err := c.validateProfile(); err != nil {
+		c.problems = append(c.problems, newProblem(profilesKey, c.profile, err))
+	}
+
 	for name, values := range c.rawConfig.Services {
 		logger.Logger.Debug().Msgf("Configuring the service of %s", name)
 
-		values, correct := values.(map[string]interface{})
-
-		if !correct {
-			return errors.New(fmt.Sprintf("%s must be Mapping", name))
+		if definition, err := c.configureService(name, values); err != nil {
+			c.problems = append(c.problems, newProblem(serviceDefinitionsKey, name, err))
+		} else {
+			c.services[name] = definition
 		}
+	}
 
-		if slices.Contains([]string{DeploygateService, FirebaseAppDistributionService, LocalService, TestFlightService}, name) {
-			return errors.New(fmt.Sprintf("%s is a reserved name", name))
+	for name := range c.rawConfig.Deployments {
+		if abstract, err := c.isAbstract(name); err != nil {
+			c.problems = append(c.problems, newProblem(deploymentsKey, name, err))
+			continue
+		} else if abstract {
+			logger.Logger.Debug().Msgf("%s is abstract", name)
+			continue
 		}
 
-		var definition CustomServiceDefinition
+		logger.Logger.Debug().Msgf("Configuring the deployment of %s", name)
 
-		if bytes, err := yaml.Marshal(values); err != nil {
-			return errors.Wrapf(err, "cannot load %s service definition", name)
-		} else if err := yaml.Unmarshal(bytes, &definition); err != nil {
-			return errors.Wrapf(err, "cannot load %s service definition", name)
-		} else if err := definition.validate(); err != nil {
-			return errors.Wrapf(err, "%s service definition is invalid", name)
+		if deployment, err := c.configureDeployment(name); err != nil {
+			c.problems = append(c.problems, newProblem(deploymentsKey, name, err))
+		} else {
+			c.deployments[name] = deployment
 		}
+	}
 
-		c.services[name] = definition
+	if err := c.configureGroups(); err != nil {
+		c.problems = append(c.problems, newProblem(groupsKey, "", err))
 	}
 
-	for name, values := range c.rawConfig.Deployments {
-		logger.Logger.Debug().Msgf("Configuring the deployment of %s", name)
+	c.problems.sort()
 
-		values, correct := values.(map[string]interface{})
+	problems := slices.Clone(c.problems)
 
-		if !correct {
-			return errors.New(fmt.Sprintf("%s must be Mapping", name))
-		}
+	if err := c.Validate(); err != nil {
+		problems = append(problems, newProblem("", "", err))
+	}
+
+	if len(problems) > 0 {
+		return problems
+	}
+
+	return nil
+}
+
+func (c *GlobalConfig) configureService(name string, values any) (CustomServiceDefinition, error) {
+	var definition CustomServiceDefinition
+
+	mapping, correct := values.(map[string]interface{})
+
+	if !correct {
+		return definition, errors.New(fmt.Sprintf("%s must be Mapping", name))
+	}
+
+	if slices.Contains([]string{DeploygateService, FirebaseAppDistributionService, LocalService, TestFlightService}, name) {
+		return definition, errors.New(fmt.Sprintf("%s is a reserved name", name))
+	}
+
+	if bytes, err := yaml.Marshal(mapping); err != nil {
+		return definition, errors.Wrapf(err, "cannot load %s service definition", name)
+	} else if err := yaml.Unmarshal(bytes, &definition); err != nil {
+		return definition, errors.Wrapf(err, "cannot load %s service definition", name)
+	} else if err := definition.validate(); err != nil {
+		return definition, errors.Wrapf(err, "%s service definition is invalid", name)
+	}
+
+	return definition, nil
+}
+
+func (c *GlobalConfig) configureDeployment(name string) (Deployment, error) {
+	values, err := c.resolveDeploymentValues(name, nil)
+
+	if err != nil {
+		return Deployment{}, err
+	}
+
+	var service serviceNameHolder
 
-		var service serviceNameHolder
+	if bytes, err := yaml.Marshal(values); err != nil {
+		return Deployment{}, errors.Wrapf(err, "cannot load %s config", name)
+	} else if err := yaml.Unmarshal(bytes, &service); err != nil {
+		return Deployment{}, errors.Wrapf(err, "cannot load %s config", name)
+	}
+
+	var deployment Deployment
 
-		if bytes, err := yaml.Marshal(values); err != nil {
-			return errors.Wrapf(err, "cannot load %s config", name)
-		} else if err := yaml.Unmarshal(bytes, &service); err != nil {
-			return errors.Wrapf(err, "cannot load %s config", name)
+	switch service.Name {
+	case DeploygateService:
+		deploygate := DeployGateConfig{}
+
+		if err := loadServiceConfig(&deploygate, values); err != nil {
+			return Deployment{}, errors.Wrapf(err, "cannot load %s config", name)
 		}
 
-		switch service.Name {
-		case DeploygateService:
-			deploygate := DeployGateConfig{}
+		deployment = Deployment{
+			ServiceName:   deploygate.Name,
+			ServiceConfig: deploygate,
+			Lifecycle:     deploygate.ExecutionConfig,
+		}
+	case FirebaseAppDistributionService:
+		firebase := FirebaseAppDistributionConfig{}
 
-			if err := loadServiceConfig(&deploygate, values); err != nil {
-				return errors.Wrapf(err, "cannot load %s config", name)
-			}
+		if err := loadServiceConfig(&firebase, values); err != nil {
+			return Deployment{}, errors.Wrapf(err, "cannot load %s config", name)
+		}
 
-			c.deployments[name] = Deployment{
-				ServiceName:   deploygate.Name,
-				ServiceConfig: deploygate,
-				Lifecycle:     deploygate.ExecutionConfig,
-			}
-		case FirebaseAppDistributionService:
-			firebase := FirebaseAppDistributionConfig{}
+		deployment = Deployment{
+			ServiceName:   firebase.Name,
+			ServiceConfig: firebase,
+			Lifecycle:     firebase.ExecutionConfig,
+		}
+	case LocalService:
+		local := LocalConfig{}
 
-			if err := loadServiceConfig(&firebase, values); err != nil {
-				return errors.Wrapf(err, "cannot load %s config", name)
-			}
+		if err := loadServiceConfig(&local, values); err != nil {
+			return Deployment{}, errors.Wrapf(err, "cannot load %s config", name)
+		}
 
-			c.deployments[name] = Deployment{
-				ServiceName:   firebase.Name,
-				ServiceConfig: firebase,
-				Lifecycle:     firebase.ExecutionConfig,
-			}
-		case LocalService:
-			local := LocalConfig{}
+		deployment = Deployment{
+			ServiceName:   local.Name,
+			ServiceConfig: local,
+			Lifecycle:     local.ExecutionConfig,
+		}
+	case TestFlightService:
+		testFlight := TestFlightConfig{}
 
-			if err := loadServiceConfig(&local, values); err != nil {
-				return errors.Wrapf(err, "cannot load %s config", name)
-			}
+		if err := loadServiceConfig(&testFlight, values); err != nil {
+			return Deployment{}, errors.Wrapf(err, "cannot load %s config", name)
+		}
 
-			c.deployments[name] = Deployment{
-				ServiceName:   local.Name,
-				ServiceConfig: local,
-				Lifecycle:     local.ExecutionConfig,
-			}
-		case TestFlightService:
-			testFlight := TestFlightConfig{}
+		deployment = Deployment{
+			ServiceName:   testFlight.Name,
+			ServiceConfig: testFlight,
+			Lifecycle:     testFlight.ExecutionConfig,
+		}
+	default:
+		if _, ok := c.services[name]; ok {
+			logger.Logger.Debug().Msgf("%s is a custom service", name)
 
-			if err := loadServiceConfig(&testFlight, values); err != nil {
-				return errors.Wrapf(err, "cannot load %s config", name)
-			}
+			custom := CustomServiceConfig{}
 
-			c.deployments[name] = Deployment{
-				ServiceName:   testFlight.Name,
-				ServiceConfig: testFlight,
-				Lifecycle:     testFlight.ExecutionConfig,
+			if err := loadServiceConfig(&custom, values); err != nil {
+				return Deployment{}, errors.Wrapf(err, "cannot load %s config", name)
 			}
-		default:
-			if _, ok := c.services[name]; ok {
-				logger.Logger.Debug().Msgf("%s is a custom service", name)
 
-				custom := CustomServiceConfig{}
+			deployment = Deployment{
+				ServiceName:   name,
+				ServiceConfig: custom,
+				Lifecycle:     custom.ExecutionConfig,
+			}
+		} else {
+			return Deployment{}, errors.New(fmt.Sprintf("%s of %s is an unknown service", service.Name, name))
+		}
+	}
 
-				if err := loadServiceConfig(&custom, values); err != nil {
-					return errors.Wrapf(err, "cannot load %s config", name)
-				}
+	if err := condition.Validate(deployment.Lifecycle.When); err != nil {
+		return Deployment{}, errors.Wrapf(err, "when of %s is invalid", name)
+	}
 
-				c.deployments[name] = Deployment{
-					ServiceName:   name,
-					ServiceConfig: custom,
-					Lifecycle:     custom.ExecutionConfig,
-				}
-			} else {
-				return errors.New(fmt.Sprintf("%s of %s is an unknown service", service.Name, name))
-			}
+	if retry := deployment.Lifecycle.Retry; retry != nil {
+		if err := retry.Validate(); err != nil {
+			return Deployment{}, errors.Wrapf(err, "retry of %s is invalid", name)
 		}
 	}
 
-	return c.Validate()
+	return deployment, nil
 }
 
 func (c *GlobalConfig) FormatStyle() string {
 	return c.rawConfig.FormatStyle
 }
 
+// DryRun returns true if deployments must not have any side effect.
+func (c *GlobalConfig) DryRun() bool {
+	return c.dryRun
+}
+
+// RetryPolicy returns the retry policy that the overlay is applied to the global retry config.
+func (c *GlobalConfig) RetryPolicy(overlay *RetryConfig) RetryPolicy {
+	return c.rawConfig.Retry.Merge(overlay).Policy()
+}
+
+// HistoryDir is a directory that contains the deployment history
+func (c *GlobalConfig) HistoryDir() string {
+	if c.rawConfig.HistoryDir != "" {
+		return c.rawConfig.HistoryDir
+	}
+
+	return DefaultHistoryDir
+}
+
 // NetworkTimeout is a read/connection timeout for requests
 func (c *GlobalConfig) NetworkTimeout() time.Duration {
 	var value = DefaultNetworkTimeout
@@ -337,9 +459,14 @@ func (c *GlobalConfig) Validate() error {
 		return errors.New("empty wait timeout is invalid")
 	}
 
+	if err := c.rawConfig.Retry.Validate(); err != nil {
+		return errors.Wrap(err, "retry is invalid")
+	}
+
 	return nil
 }
 
+// Dump writes the values of the config file. The raw values keep references like env: so resolved values are never written.
 func (c *GlobalConfig) Dump(path string) error {
 	if bytes, err := yaml.Marshal(c.rawConfig); err != nil {
 		return errors.Wrapf(err, "failed to parse a config file to %s", path)
@@ -352,6 +479,10 @@ func (c *GlobalConfig) Dump(path string) error {
 
 func (c *GlobalConfig) Definition(name string) (CustomServiceDefinition, error) {
 	if s, ok := c.services[name]; ok {
+		if err := evaluateValues(&s); err != nil {
+			return CustomServiceDefinition{}, errors.Wrapf(err, "%s service definition cannot be evaluated", name)
+		}
+
 		return s, nil
 	} else {
 		return CustomServiceDefinition{}, errors.New(fmt.Sprintf("%s is not found in services", name))
@@ -364,39 +495,54 @@ func (c *GlobalConfig) Deployment(name string) (Deployment, CustomServiceDefinit
 	if d, ok := c.deployments[name]; ok {
 		switch d.ServiceName {
 		case DeploygateService:
-			config := d.ServiceConfig.(*DeployGateConfig)
+			config := d.ServiceConfig.(DeployGateConfig)
 
-			if err := evaluateAndValidate(config); err != nil {
+			if err := evaluateAndValidate(&config); err != nil {
 				return Deployment{}, definition, err
 			}
+
+			d.ServiceConfig = config
+			d.Lifecycle = config.ExecutionConfig
 		case FirebaseAppDistributionService:
-			config := d.ServiceConfig.(*FirebaseAppDistributionConfig)
+			config := d.ServiceConfig.(FirebaseAppDistributionConfig)
 
-			if err := evaluateAndValidate(config); err != nil {
+			if err := evaluateAndValidate(&config); err != nil {
 				return Deployment{}, definition, err
 			}
+
+			d.ServiceConfig = config
+			d.Lifecycle = config.ExecutionConfig
 		case LocalService:
-			config := d.ServiceConfig.(*LocalConfig)
+			config := d.ServiceConfig.(LocalConfig)
 
-			if err := evaluateAndValidate(config); err != nil {
+			if err := evaluateAndValidate(&config); err != nil {
 				return Deployment{}, definition, err
 			}
+
+			d.ServiceConfig = config
+			d.Lifecycle = config.ExecutionConfig
 		case TestFlightService:
-			config := d.ServiceConfig.(*TestFlightConfig)
+			config := d.ServiceConfig.(TestFlightConfig)
 
-			if err := evaluateAndValidate(config); err != nil {
+			if err := evaluateAndValidate(&config); err != nil {
 				return Deployment{}, definition, err
 			}
+
+			d.ServiceConfig = config
+			d.Lifecycle = config.ExecutionConfig
 		default:
-			config := d.ServiceConfig.(*CustomServiceConfig)
+			config := d.ServiceConfig.(CustomServiceConfig)
 
-			if err := evaluateAndValidate(config); err != nil {
+			if err := evaluateAndValidate(&config); err != nil {
 				return Deployment{}, definition, err
 			} else if v, err := c.Definition(config.Name); err != nil {
 				return Deployment{}, definition, err
 			} else {
 				definition = v
 			}
+
+			d.ServiceConfig = config
+			d.Lifecycle = config.ExecutionConfig
 		}
 
 		return d, definition, nil
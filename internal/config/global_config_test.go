@@ -2,8 +2,12 @@ package config
 
 import (
 	"fmt"
+	"github.com/jmatsu/splitter/internal/secret"
 	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -186,3 +190,109 @@ func Test_Config_configure(t *testing.T) {
 		})
 	}
 }
+
+func Test_Config_Deployment(t *testing.T) {
+	cases := map[string]struct {
+		rawConfig       rawConfig
+		name            string
+		envs            map[string]string
+		expectedSuccess bool
+	}{
+		"evaluated": {
+			rawConfig: rawConfig{
+				Deployments: map[string]interface{}{
+					"def1": map[string]interface{}{
+						"service":        DeploygateService,
+						"app-owner-name": "def1-owner",
+						"api-token":      "format:${TEST_DEPLOYMENT_API_TOKEN}",
+					},
+				},
+			},
+			name: "def1",
+			envs: map[string]string{
+				"TEST_DEPLOYMENT_API_TOKEN": "def1-token",
+			},
+			expectedSuccess: true,
+		},
+		"missing values": {
+			rawConfig: rawConfig{
+				Deployments: map[string]interface{}{
+					"def1": map[string]interface{}{
+						"service": LocalService,
+					},
+				},
+			},
+			name:            "def1",
+			expectedSuccess: false,
+		},
+		"not found": {
+			rawConfig:       rawConfig{},
+			name:            "def1",
+			expectedSuccess: false,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+		t.Run(name, func(t *testing.T) {
+			for name, value := range c.envs {
+				t.Setenv(name, value)
+			}
+
+			config := GlobalConfig{
+				rawConfig: c.rawConfig,
+			}
+
+			if err := config.configure(); err != nil {
+				t.Fatalf("%s case is expected to be configured but not: %v", name, err)
+			}
+
+			d, _, err := config.Deployment(c.name)
+
+			if (err == nil) != c.expectedSuccess {
+				t.Fatalf("%s case is expected to be %t but %t: %v", name, c.expectedSuccess, err == nil, err)
+			}
+
+			if dg, ok := d.ServiceConfig.(DeployGateConfig); ok && dg.ApiToken != c.envs["TEST_DEPLOYMENT_API_TOKEN"] {
+				t.Errorf("api token is expected to be evaluated but %s", dg.ApiToken)
+			}
+		})
+	}
+}
+
+func Test_Config_Dump_raw(t *testing.T) {
+	t.Parallel()
+
+	secret.Register("overwrite")
+
+	config := GlobalConfig{
+		rawConfig: rawConfig{
+			Deployments: map[string]interface{}{
+				"def1": map[string]interface{}{
+					"service":        DeploygateService,
+					"app-owner-name": "owner",
+					"api-token":      "env:DEPLOYGATE_API_TOKEN",
+				},
+				"def2": map[string]interface{}{
+					"service":          LocalService,
+					"destination-path": "dist/app.apk",
+					"allow-overwrite":  true,
+				},
+			},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "splitter.yml")
+
+	if err := config.Dump(path); err != nil {
+		t.Fatalf("the config is expected to be dumped but %v", err)
+	}
+
+	if bytes, err := os.ReadFile(path); err != nil {
+		t.Fatalf("the dumped file is expected to be read but %v", err)
+	} else if content := string(bytes); !strings.Contains(content, "env:DEPLOYGATE_API_TOKEN") {
+		t.Errorf("references of secrets are expected to be kept: %s", content)
+	} else if !strings.Contains(content, "allow-overwrite: true") {
+		t.Errorf("the raw values are expected to be written as is even if they contain secrets: %s", content)
+	}
+}
@@ -1,6 +1,17 @@
 package config
 
-import "os"
+import (
+	"fmt"
+	"github.com/jmatsu/splitter/internal/appinfo"
+	"github.com/jmatsu/splitter/internal/util"
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
 
 // LocalConfig contains the enough values to use local file system.
 type LocalConfig struct {
@@ -8,6 +19,7 @@ type LocalConfig struct {
 	ExecutionConfig   `yaml:",inline"`
 
 	// A destination file path. Absolute and/or relative paths are supported.
+	// This can be a template like `dist/{{.BaseName}}-copy{{.Ext}}`. See DestinationPathData for the available values.
 	DestinationPath string `yaml:"destination-path" required:"true"`
 
 	// Specify true if you are okay to overwrite the destination file. Otherwise, this command fails.
@@ -16,10 +28,248 @@ type LocalConfig struct {
 	// 0644 for example. zero value means keeping the perm mode of the source file
 	FileMode os.FileMode `yaml:"file-mode,omitempty"`
 
+	// The perm mode of parent directories that are created for the destination path. (default: 0755)
+	DirMode os.FileMode `yaml:"dir-mode,omitempty"`
+
 	// Specify true if you would like to delete the source file later and the behavior looks *move* then.
 	DeleteSource bool `yaml:"delete-source,omitempty"`
+
+	// Write <destination>.sha256 next to the destination file. The format is compatible with `sha256sum -c`.
+	ChecksumFile bool `yaml:"checksum-file,omitempty"`
+
+	// Write <destination>.json next to the destination file. It contains the source path, the hash, the size, the deployment name, the time and CI metadata.
+	MetadataFile bool `yaml:"metadata-file,omitempty"`
+
+	// Keep several versions instead of overwriting the destination. The destination path is used as a directory then.
+	Versioning *LocalVersioningConfig `yaml:"versioning,omitempty"`
+
+	// Write an install page next to the deployed file so that the directory can be served by a static web server.
+	InstallPage *LocalInstallPageConfig `yaml:"install-page,omitempty"`
+}
+
+type LocalLatestStrategy = string
+
+const (
+	LocalLatestSymlink LocalLatestStrategy = "symlink"
+	LocalLatestCopy    LocalLatestStrategy = "copy"
+)
+
+// LocalVersioningConfig stores each deployment in its own version directory and points the latest one.
+type LocalVersioningConfig struct {
+	// The number of versions to keep. Older versions are deleted after a deployment.
+	Retention int `yaml:"retention" required:"true"`
+
+	// How the latest pointer refers to the current version. symlink or copy. (default: symlink)
+	Latest LocalLatestStrategy `yaml:"latest,omitempty" enum:"symlink,copy"`
+}
+
+// LatestStrategy returns the strategy with the default value.
+func (c *LocalVersioningConfig) LatestStrategy() LocalLatestStrategy {
+	if c.Latest == "" {
+		return LocalLatestSymlink
+	}
+
+	return c.Latest
+}
+
+func (c *LocalVersioningConfig) Validate() error {
+	if err := validateMissingValues(c); err != nil {
+		return err
+	}
+
+	if c.Retention < 1 {
+		return errors.New(fmt.Sprintf("retention must be positive but %d", c.Retention))
+	}
+
+	if !slices.Contains([]LocalLatestStrategy{LocalLatestSymlink, LocalLatestCopy}, c.LatestStrategy()) {
+		return errors.New(fmt.Sprintf("%s is unknown strategy of latest", c.Latest))
+	}
+
+	return nil
+}
+
+// LocalInstallPageConfig writes index.html and manifest.json, and a manifest plist for itms-services if the file is an ipa.
+// The page lists all deployed files in the directory.
+type LocalInstallPageConfig struct {
+	// The URL that serves the directory of the install page. e.g. https://example.com/dogfooding/
+	BaseURL string `yaml:"base-url" required:"true"`
+
+	// The title of the page. (default: the package name or the file name)
+	Title string `yaml:"title,omitempty"`
+}
+
+func (c *LocalInstallPageConfig) Validate() error {
+	if err := validateMissingValues(c); err != nil {
+		return err
+	}
+
+	if u, err := url.Parse(c.BaseURL); err != nil {
+		return errors.Wrapf(err, "%s is not a valid url", c.BaseURL)
+	} else if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New(fmt.Sprintf("base-url must be an absolute http(s) url but %s", c.BaseURL))
+	}
+
+	return nil
+}
+
+const DefaultLocalDirMode os.FileMode = 0755
+
+// DestinationPathData contains the values that the template of the destination path can refer to.
+// Methods read the source file only when the template refers to them.
+type DestinationPathData struct {
+	// A file name of the source file. e.g. app-release.apk
+	FileName string
+
+	// A file name without the extension. e.g. app-release
+	BaseName string
+
+	// An extension with a dot. e.g. .apk
+	Ext string
+
+	// 0-origin index of the source file in the given source files.
+	Index int
+
+	// Environment variables. e.g. {{.Env.BUILD_NUMBER}}
+	Env map[string]string
+
+	sourceFilePath string
+	appInfo        *appinfo.AppInfo
+}
+
+func (d *DestinationPathData) readAppInfo(name string) (*appinfo.AppInfo, error) {
+	if d.appInfo == nil {
+		info, err := appinfo.Read(d.sourceFilePath)
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s is not available", name)
+		}
+
+		d.appInfo = info
+	}
+
+	return d.appInfo, nil
+}
+
+// PackageName is Android's package name or iOS's bundle identifier.
+func (d *DestinationPathData) PackageName() (string, error) {
+	if info, err := d.readAppInfo("PackageName"); err != nil {
+		return "", err
+	} else {
+		return info.PackageName, nil
+	}
+}
+
+// VersionName is Android's versionName or iOS's CFBundleShortVersionString.
+func (d *DestinationPathData) VersionName() (string, error) {
+	if info, err := d.readAppInfo("VersionName"); err != nil {
+		return "", err
+	} else {
+		return info.VersionName, nil
+	}
+}
+
+// VersionCode is Android's versionCode or iOS's CFBundleVersion.
+func (d *DestinationPathData) VersionCode() (string, error) {
+	if info, err := d.readAppInfo("VersionCode"); err != nil {
+		return "", err
+	} else {
+		return info.VersionCode, nil
+	}
+}
+
+// SHA256 is the hex-encoded SHA-256 of the source file.
+func (d *DestinationPathData) SHA256() (string, error) {
+	if digest, err := util.DigestFile(d.sourceFilePath); err != nil {
+		return "", err
+	} else {
+		return digest.SHA256, nil
+	}
+}
+
+// ShortSHA is the first 7 characters of SHA256.
+func (d *DestinationPathData) ShortSHA() (string, error) {
+	if v, err := d.SHA256(); err != nil {
+		return "", err
+	} else {
+		return v[:7], nil
+	}
 }
 
 func (c *LocalConfig) Validate() error {
-	return validateMissingValues(c)
+	if err := validateMissingValues(c); err != nil {
+		return err
+	}
+
+	if c.DirMode&^os.ModePerm != 0 {
+		return errors.New(fmt.Sprintf("dir-mode must be a permission like 0755 but %o", c.DirMode))
+	}
+
+	if c.Versioning != nil {
+		if err := c.Versioning.Validate(); err != nil {
+			return errors.Wrap(err, "versioning is invalid")
+		}
+	}
+
+	if c.InstallPage != nil {
+		if err := c.InstallPage.Validate(); err != nil {
+			return errors.Wrap(err, "install-page is invalid")
+		}
+	}
+
+	return nil
+}
+
+// ParentDirMode returns the perm mode of parent directories with the default value.
+func (c *LocalConfig) ParentDirMode() os.FileMode {
+	if c.DirMode == 0 {
+		return DefaultLocalDirMode
+	}
+
+	return c.DirMode
+}
+
+// HasDestinationTemplate returns true if the destination path depends on source files.
+func (c *LocalConfig) HasDestinationTemplate() bool {
+	return strings.Contains(c.DestinationPath, "{{")
+}
+
+// ResolveDestinationPath renders the destination path for the source file. The destination path is used as it is if it's not a template.
+func (c *LocalConfig) ResolveDestinationPath(sourceFilePath string, index int) (string, error) {
+	if !c.HasDestinationTemplate() {
+		return c.DestinationPath, nil
+	}
+
+	t, err := template.New("destination-path").Option("missingkey=error").Parse(c.DestinationPath)
+
+	if err != nil {
+		return "", errors.Wrapf(err, "%s is not a valid template", c.DestinationPath)
+	}
+
+	fileName := filepath.Base(sourceFilePath)
+	ext := filepath.Ext(fileName)
+
+	env := map[string]string{}
+
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+
+	data := &DestinationPathData{
+		FileName:       fileName,
+		BaseName:       strings.TrimSuffix(fileName, ext),
+		Ext:            ext,
+		Index:          index,
+		Env:            env,
+		sourceFilePath: sourceFilePath,
+	}
+
+	var b strings.Builder
+
+	if err := t.Execute(&b, data); err != nil {
+		return "", errors.Wrapf(err, "cannot render %s", c.DestinationPath)
+	}
+
+	return b.String(), nil
 }
@@ -1,6 +1,11 @@
 package config
 
-import "testing"
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
 
 func Test_LocalConfig_validateMissingValues(t *testing.T) {
 	t.Parallel()
@@ -51,3 +56,212 @@ func Test_LocalConfig_validateMissingValues(t *testing.T) {
 		})
 	}
 }
+
+func Test_LocalConfig_ResolveDestinationPath(t *testing.T) {
+	t.Parallel()
+
+	sourceFilePath := filepath.Join(t.TempDir(), "app.aab")
+
+	if err := os.WriteFile(sourceFilePath, []byte("hello"), 0644); err != nil {
+		t.Fatalf("failed to prepare a file: %v", err)
+	}
+
+	// os.Setenv cannot be used with t.Parallel
+	env := os.Getenv("HOME")
+
+	cases := map[string]struct {
+		destinationPath string
+		sourceFilePath  string
+		index           int
+
+		expected    string
+		expectedErr bool
+	}{
+		"plain": {
+			destinationPath: "dist/app.apk",
+			sourceFilePath:  "build/app-arm64-v8a-release.apk",
+			expected:        "dist/app.apk",
+		},
+		"template": {
+			destinationPath: "dist/{{.BaseName}}-{{.Index}}{{.Ext}}",
+			sourceFilePath:  "build/app-arm64-v8a-release.apk",
+			index:           1,
+			expected:        "dist/app-arm64-v8a-release-1.apk",
+		},
+		"file name": {
+			destinationPath: "dist/{{.FileName}}",
+			sourceFilePath:  "build/app.aab",
+			expected:        "dist/app.aab",
+		},
+		"environment variables": {
+			destinationPath: "dist/{{.Env.HOME}}/{{.FileName}}",
+			sourceFilePath:  "build/app.aab",
+			expected:        fmt.Sprintf("dist/%s/app.aab", env),
+		},
+		"unknown environment variable": {
+			destinationPath: "dist/{{.Env.SPLITTER_UNKNOWN_VARIABLE_FOR_TEST}}",
+			sourceFilePath:  "build/app.aab",
+			expectedErr:     true,
+		},
+		"hash": {
+			destinationPath: "dist/{{.ShortSHA}}/{{.SHA256}}",
+			sourceFilePath:  sourceFilePath,
+			expected:        "dist/2cf24db/2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		},
+		"hash of missing file": {
+			destinationPath: "dist/{{.ShortSHA}}",
+			sourceFilePath:  "build/app.aab",
+			expectedErr:     true,
+		},
+		"metadata of unreadable file": {
+			destinationPath: "dist/{{.PackageName}}",
+			sourceFilePath:  sourceFilePath,
+			expectedErr:     true,
+		},
+		"unknown field": {
+			destinationPath: "dist/{{.Unknown}}",
+			sourceFilePath:  "build/app.aab",
+			expectedErr:     true,
+		},
+		"broken template": {
+			destinationPath: "dist/{{.FileName",
+			sourceFilePath:  "build/app.aab",
+			expectedErr:     true,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := LocalConfig{
+				DestinationPath: c.destinationPath,
+			}
+
+			actual, err := config.ResolveDestinationPath(c.sourceFilePath, c.index)
+
+			if c.expectedErr {
+				if err == nil {
+					t.Errorf("%s case is expected to be failure but not: %s", name, actual)
+				}
+
+				return
+			} else if err != nil {
+				t.Fatalf("%s case is expected to be success but not: %v", name, err)
+			}
+
+			if c.expected != actual {
+				t.Errorf("%s is expected but %s", c.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_LocalVersioningConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		config            LocalVersioningConfig
+		expectedValidness bool
+	}{
+		"symlink": {
+			config: LocalVersioningConfig{
+				Retention: 3,
+				Latest:    LocalLatestSymlink,
+			},
+			expectedValidness: true,
+		},
+		"copy": {
+			config: LocalVersioningConfig{
+				Retention: 1,
+				Latest:    LocalLatestCopy,
+			},
+			expectedValidness: true,
+		},
+		"default strategy": {
+			config: LocalVersioningConfig{
+				Retention: 1,
+			},
+			expectedValidness: true,
+		},
+		"unknown strategy": {
+			config: LocalVersioningConfig{
+				Retention: 1,
+				Latest:    "hardlink",
+			},
+			expectedValidness: false,
+		},
+		"negative retention": {
+			config: LocalVersioningConfig{
+				Retention: -1,
+			},
+			expectedValidness: false,
+		},
+		"zero": {
+			config:            LocalVersioningConfig{},
+			expectedValidness: false,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := c.config.Validate(); (err == nil) != c.expectedValidness {
+				t.Errorf("%s case is expected to be %t but %t", name, c.expectedValidness, err == nil)
+			}
+		})
+	}
+}
+
+func Test_LocalInstallPageConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		config            LocalInstallPageConfig
+		expectedValidness bool
+	}{
+		"https": {
+			config: LocalInstallPageConfig{
+				BaseURL: "https://example.com/dogfooding/",
+			},
+			expectedValidness: true,
+		},
+		"http": {
+			config: LocalInstallPageConfig{
+				BaseURL: "http://localhost:8080",
+				Title:   "Dogfooding",
+			},
+			expectedValidness: true,
+		},
+		"relative": {
+			config: LocalInstallPageConfig{
+				BaseURL: "/dogfooding",
+			},
+			expectedValidness: false,
+		},
+		"unsupported scheme": {
+			config: LocalInstallPageConfig{
+				BaseURL: "file:///mnt/shared",
+			},
+			expectedValidness: false,
+		},
+		"zero": {
+			config:            LocalInstallPageConfig{},
+			expectedValidness: false,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := c.config.Validate(); (err == nil) != c.expectedValidness {
+				t.Errorf("%s case is expected to be %t but %t", name, c.expectedValidness, err == nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Problem is an invalid part of the config file.
+type Problem struct {
+	Section      string   `json:"section"` // deployments, services, groups or empty for the top-level values
+	Name         string   `json:"name"`
+	Message      string   `json:"message"`
+	UnsetEnvVars []string `json:"unset_env_vars,omitempty"`
+}
+
+func newProblem(section string, name string, err error) Problem {
+	return Problem{
+		Section: section,
+		Name:    name,
+		Message: err.Error(),
+	}
+}
+
+// Problems is an error that holds every problem of the config file.
+type Problems []Problem
+
+func (p Problems) Error() string {
+	var messages []string
+
+	for _, problem := range p {
+		if problem.Name != "" {
+			messages = append(messages, fmt.Sprintf("%s.%s: %s", problem.Section, problem.Name, problem.Message))
+		} else {
+			messages = append(messages, problem.Message)
+		}
+	}
+
+	return fmt.Sprintf("%d problems are found: %s", len(p), strings.Join(messages, "; "))
+}
+
+func (p Problems) sort() {
+	slices.SortStableFunc(p, func(a, b Problem) int {
+		if a.Section != b.Section {
+			return strings.Compare(a.Section, b.Section)
+		}
+
+		return strings.Compare(a.Name, b.Name)
+	})
+}
+
+// IsProblems returns true if the config file has been loaded but some of its values are invalid.
+func IsProblems(err error) bool {
+	var problems Problems
+	return errors.As(err, &problems)
+}
+
+// ValidateAll evaluates and validates every deployment in addition to the problems found while loading the config file.
+// Deployment evaluates and validates the deployment lazily so this is useful to find problems of rarely used deployments.
+func (c *GlobalConfig) ValidateAll() Problems {
+	problems := slices.Clone(c.problems)
+
+	// command-line options may be applied after loading the config file
+	if err := c.Validate(); err != nil {
+		problems = append(problems, newProblem("", "", err))
+	}
+
+	names := maps.Keys(c.deployments)
+	slices.Sort(names)
+
+	for _, name := range names {
+		var problem Problem
+
+		if d, _, err := c.Deployment(name); err != nil {
+			problem = newProblem(deploymentsKey, name, err)
+		} else if err := validateServiceConfig(d.ServiceConfig); err != nil {
+			problem = newProblem(deploymentsKey, name, err)
+		} else {
+			continue
+		}
+
+		problem.UnsetEnvVars = unsetEnvVars(c.deployments[name].ServiceConfig)
+		problems = append(problems, problem)
+	}
+
+	names = maps.Keys(c.services)
+	slices.Sort(names)
+
+	for _, name := range names {
+		if _, err := c.Definition(name); err != nil {
+			problem := newProblem(serviceDefinitionsKey, name, err)
+			problem.UnsetEnvVars = unsetEnvVars(c.services[name])
+			problems = append(problems, problem)
+		}
+	}
+
+	problems.sort()
+
+	return problems
+}
+
+func validateServiceConfig(v any) error {
+	switch config := v.(type) {
+	case DeployGateConfig:
+		return config.Validate()
+	case FirebaseAppDistributionConfig:
+		return config.Validate()
+	case LocalConfig:
+		return config.Validate()
+	case TestFlightConfig:
+		return config.Validate()
+	case CustomServiceConfig:
+		return config.Validate()
+	default:
+		return errors.New(fmt.Sprintf("%v is an unknown config", v))
+	}
+}
+
+// unsetEnvVars returns environment variables that are referred by format: values or can give required values but are not set.
+func unsetEnvVars(v any) []string {
+	vRef := reflect.ValueOf(v)
+
+	if vRef.Kind() != reflect.Struct {
+		return nil
+	}
+
+	// only variables are checked. files and commands are not touched here
+	e := &evaluator{
+		lookupEnv: os.LookupEnv,
+		readFile: func(path string) ([]byte, error) {
+			return nil, nil
+		},
+		runCommand: func(name string, args ...string) ([]byte, error) {
+			return nil, nil
+		},
+	}
+
+	e.evaluate("", vRef, plainField)
+
+	var names []string
+
+	for _, name := range e.unset {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	for i := 0; i < vRef.NumField(); i++ {
+		tag := vRef.Type().Field(i).Tag
+
+		if _, found := tag.Lookup("yaml"); !found {
+			continue
+		}
+
+		if required := tag.Get("required") == "true"; required && vRef.Field(i).IsZero() {
+			if name, found := tag.Lookup("env"); found && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"testing"
+)
+
+func Test_Config_ValidateAll(t *testing.T) {
+	cases := map[string]struct {
+		rawConfig rawConfig
+		envs      map[string]string
+
+		expectedLoadFailure bool
+		expectedProblems    []string // <section>.<name>
+		expectedUnset       map[string][]string
+	}{
+		"valid": {
+			rawConfig: rawConfig{
+				Deployments: map[string]interface{}{
+					"def1": map[string]interface{}{
+						"service":          LocalService,
+						"destination-path": "format:${TEST_VALIDATE_DIR}/app.apk",
+					},
+				},
+			},
+			envs: map[string]string{
+				"TEST_VALIDATE_DIR": "/tmp",
+			},
+		},
+		"lazily invalid": {
+			rawConfig: rawConfig{
+				Deployments: map[string]interface{}{
+					"def1": map[string]interface{}{
+						"service": DeploygateService,
+					},
+					"def2": map[string]interface{}{
+						"service":          LocalService,
+						"destination-path": "format:${TEST_VALIDATE_UNSET_DIR}/app.apk",
+					},
+					"def3": map[string]interface{}{
+						"service":          LocalService,
+						"destination-path": "out",
+						"dir-mode":         01000,
+					},
+				},
+			},
+			expectedProblems: []string{"deployments.def1", "deployments.def2", "deployments.def3"},
+			expectedUnset: map[string][]string{
+				"def1": {"DEPLOYGATE_APP_OWNER_NAME", "DEPLOYGATE_API_TOKEN"},
+				"def2": {"TEST_VALIDATE_UNSET_DIR"},
+			},
+		},
+		"invalid on loading": {
+			rawConfig: rawConfig{
+				Deployments: map[string]interface{}{
+					"def1": map[string]interface{}{
+						"service": "unknown",
+					},
+					"def2": map[string]interface{}{
+						"service":          LocalService,
+						"destination-path": "out",
+						"when":             "((",
+					},
+					"def3": map[string]interface{}{
+						"service": LocalService,
+					},
+					"def4": map[string]interface{}{
+						"service":          LocalService,
+						"destination-path": "out",
+						"when":             `brnach == "main"`,
+					},
+				},
+				Services: map[string]interface{}{
+					DeploygateService: map[string]interface{}{},
+				},
+			},
+			expectedLoadFailure: true,
+			expectedProblems:    []string{"deployments.def1", "deployments.def2", "deployments.def3", "deployments.def4", "services.deploygate"},
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+		t.Run(name, func(t *testing.T) {
+			for name, value := range c.envs {
+				t.Setenv(name, value)
+			}
+
+			config := GlobalConfig{
+				rawConfig: c.rawConfig,
+			}
+
+			if err := config.configure(); (err != nil) != c.expectedLoadFailure {
+				t.Fatalf("%s case is expected to be loaded (failure: %t) but %v", name, c.expectedLoadFailure, err)
+			} else if err != nil && !IsProblems(err) {
+				t.Fatalf("the error is expected to hold the problems but %v", err)
+			}
+
+			problems := config.ValidateAll()
+
+			if len(problems) != len(c.expectedProblems) {
+				t.Fatalf("%v are expected but %v", c.expectedProblems, problems)
+			}
+
+			for idx, problem := range problems {
+				if actual := problem.Section + "." + problem.Name; actual != c.expectedProblems[idx] {
+					t.Errorf("%s is expected but %s", c.expectedProblems[idx], actual)
+				}
+
+				if expected := c.expectedUnset[problem.Name]; len(expected) != len(problem.UnsetEnvVars) {
+					t.Errorf("%v are expected to be unset but %v", expected, problem.UnsetEnvVars)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+const profilesKey = "profiles" // profiles' key in the config file.
+
+// ProfileConfig overrides values of existing deployments while the profile is selected.
+type ProfileConfig struct {
+	// Values by deployment name. They are deep-merged into the values of the deployment before loading.
+	Deployments map[string]interface{} `yaml:"deployments"`
+}
+
+// Profile returns the selected profile or an empty string.
+func (c *GlobalConfig) Profile() string {
+	return c.profile
+}
+
+// validateProfile makes sure the selected profile exists and overrides only existing deployments.
+func (c *GlobalConfig) validateProfile() error {
+	if c.profile == "" {
+		return nil
+	}
+
+	profile, found := c.rawConfig.Profiles[c.profile]
+
+	if !found {
+		return errors.New(fmt.Sprintf("%s profile is not found", c.profile))
+	}
+
+	names := maps.Keys(profile.Deployments)
+	slices.Sort(names)
+
+	for _, name := range names {
+		if _, found := c.rawConfig.Deployments[name]; !found {
+			return errors.New(fmt.Sprintf("%s profile overrides %s but the deployment is not found", c.profile, name))
+		}
+	}
+
+	return nil
+}
+
+// profileOverlay returns the values of the selected profile for the deployment. nil is returned if the profile does not override the deployment.
+func (c *GlobalConfig) profileOverlay(name string) (map[string]interface{}, error) {
+	if c.profile == "" {
+		return nil, nil
+	}
+
+	raw, found := c.rawConfig.Profiles[c.profile].Deployments[name]
+
+	if !found {
+		return nil, nil
+	}
+
+	overlay, correct := raw.(map[string]interface{})
+
+	if !correct {
+		return nil, errors.New(fmt.Sprintf("%s of %s profile must be Mapping", name, c.profile))
+	}
+
+	return overlay, nil
+}
+
+// checkProfileService makes sure the profile does not override the service. The service is either of the deployment's own or the inherited one.
+func (c *GlobalConfig) checkProfileService(name string, overlay map[string]interface{}, service interface{}) error {
+	if v, found := overlay["service"]; found && v != service {
+		return errors.New(fmt.Sprintf("%s profile cannot override the service of %s: %v -> %v", c.profile, name, service, v))
+	}
+
+	return nil
+}
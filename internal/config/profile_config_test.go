@@ -0,0 +1,206 @@
+package config
+
+import (
+	"testing"
+)
+
+func Test_Config_profile(t *testing.T) {
+	t.Parallel()
+
+	deployments := map[string]interface{}{
+		"base": map[string]interface{}{
+			"service":        DeploygateService,
+			"app-owner-name": "production-owner",
+			"api-token":      "production-token",
+			"retry": map[string]interface{}{
+				"max-attempts": 3,
+				"base-delay":   "1s",
+			},
+		},
+		"child": map[string]interface{}{
+			"extends":           "base",
+			"distribution-name": "child",
+		},
+	}
+
+	profiles := map[string]ProfileConfig{
+		"staging": {
+			Deployments: map[string]interface{}{
+				"base": map[string]interface{}{
+					"api-token": "staging-token",
+					"retry": map[string]interface{}{
+						"max-attempts": 5,
+					},
+				},
+			},
+		},
+		"broken-service": {
+			Deployments: map[string]interface{}{
+				"base": map[string]interface{}{
+					"service": LocalService,
+				},
+			},
+		},
+		"unknown-deployment": {
+			Deployments: map[string]interface{}{
+				"unknown": map[string]interface{}{
+					"api-token": "token",
+				},
+			},
+		},
+	}
+
+	cases := map[string]struct {
+		profile string
+
+		expectedErr         bool
+		expectedToken       string
+		expectedMaxAttempts int
+	}{
+		"no profile": {
+			expectedToken:       "production-token",
+			expectedMaxAttempts: 3,
+		},
+		"staging": {
+			profile:             "staging",
+			expectedToken:       "staging-token",
+			expectedMaxAttempts: 5,
+		},
+		"unknown profile": {
+			profile:     "unknown",
+			expectedErr: true,
+		},
+		"override the service": {
+			profile:     "broken-service",
+			expectedErr: true,
+		},
+		"override an unknown deployment": {
+			profile:     "unknown-deployment",
+			expectedErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := GlobalConfig{
+				rawConfig: rawConfig{
+					Deployments: deployments,
+					Profiles:    profiles,
+				},
+				profile: c.profile,
+			}
+
+			if err := config.configure(); c.expectedErr {
+				if err == nil {
+					t.Errorf("%s case is expected to fail", name)
+				}
+
+				return
+			} else if err != nil {
+				t.Fatalf("%s case is expected to succeed but %v", name, err)
+			}
+
+			for _, deployment := range []string{"base", "child"} {
+				d, _, err := config.Deployment(deployment)
+
+				if err != nil {
+					t.Fatalf("%s is expected to be found but %v", deployment, err)
+				}
+
+				dg := d.ServiceConfig.(DeployGateConfig)
+
+				if dg.ApiToken != c.expectedToken {
+					t.Errorf("%s of %s is expected but %s", c.expectedToken, deployment, dg.ApiToken)
+				}
+
+				if dg.Retry.MaxAttempts != c.expectedMaxAttempts || dg.Retry.BaseDelay != "1s" {
+					t.Errorf("the retry of %s is expected to be deep-merged but %v", deployment, dg.Retry)
+				}
+			}
+
+			if v := deployments["base"].(map[string]interface{})["api-token"]; v != "production-token" {
+				t.Errorf("the raw values must not be modified but %v", v)
+			}
+		})
+	}
+}
+
+func Test_Config_profile_extends(t *testing.T) {
+	t.Parallel()
+
+	deployments := map[string]interface{}{
+		"base": map[string]interface{}{
+			"service":        DeploygateService,
+			"app-owner-name": "owner",
+			"api-token":      "production-token",
+		},
+		"child": map[string]interface{}{
+			"extends": "base",
+		},
+	}
+
+	cases := map[string]struct {
+		overlay map[string]interface{}
+
+		expectedErr   bool
+		expectedToken string
+	}{
+		"the inherited service": {
+			overlay: map[string]interface{}{
+				"service":   DeploygateService,
+				"api-token": "staging-token",
+			},
+			expectedToken: "staging-token",
+		},
+		"another service": {
+			overlay: map[string]interface{}{
+				"service": LocalService,
+			},
+			expectedErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			config := GlobalConfig{
+				rawConfig: rawConfig{
+					Deployments: deployments,
+					Profiles: map[string]ProfileConfig{
+						"staging": {
+							Deployments: map[string]interface{}{
+								"child": c.overlay,
+							},
+						},
+					},
+				},
+				profile: "staging",
+			}
+
+			if err := config.configure(); c.expectedErr {
+				if err == nil {
+					t.Errorf("%s case is expected to fail", name)
+				}
+
+				return
+			} else if err != nil {
+				t.Fatalf("%s case is expected to succeed but %v", name, err)
+			}
+
+			d, _, err := config.Deployment("child")
+
+			if err != nil {
+				t.Fatalf("child is expected to be found but %v", err)
+			}
+
+			if v := d.ServiceConfig.(DeployGateConfig).ApiToken; v != c.expectedToken {
+				t.Errorf("%s is expected but %s", c.expectedToken, v)
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/jmatsu/splitter/internal/secret"
+	"github.com/pkg/errors"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"os"
+	"reflect"
+	"strings"
+)
+
+const (
+	FileSource    = "file"    // a config file
+	ProfileSource = "profile" // the selected profile in a config file
+	EnvSource     = "env"     // an environment variable of the env tag
+	OptionSource  = "option"  // a command-line option or its environment variable
+	DefaultSource = "default" // no value is given
+)
+
+// ResolvedValue is a value that splitter actually uses and where it comes from.
+type ResolvedValue struct {
+	Key       string `json:"key"`                 // e.g. deployments.<name>.api-token
+	Value     string `json:"value"`               // secrets are masked
+	Source    string `json:"source"`              // file, profile, env, option or default
+	Origin    string `json:"origin,omitempty"`    // the config file or the environment variable
+	Reference string `json:"reference,omitempty"` // the value before it's resolved by format: or the other sources
+	Error     string `json:"error,omitempty"`     // the reason why the value cannot be resolved
+}
+
+// ResolveValues returns the top-level values and the values of the deployments after evaluating them. Every deployment is resolved if no name is given.
+// Custom service definitions that the deployments use are resolved as well. Unlike Deployment, values that cannot be evaluated are reported with their errors.
+func (c *GlobalConfig) ResolveValues(names []string) ([]ResolvedValue, error) {
+	if len(names) == 0 {
+		names = maps.Keys(c.deployments)
+		slices.Sort(names)
+	}
+
+	values := c.resolveTopLevelValues()
+
+	var services []string
+
+	for _, name := range names {
+		d, ok := c.deployments[name]
+
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("%s deployment is not found", name))
+		}
+
+		prefix := fmt.Sprintf("%s.%s", deploymentsKey, name)
+
+		values = append(values, resolveLeafValues(prefix, d.ServiceConfig, func(leaf leafValue) (string, string) {
+			return c.deploymentSource(name, leaf)
+		})...)
+
+		if custom, ok := d.ServiceConfig.(CustomServiceConfig); ok && !slices.Contains(services, custom.Name) {
+			services = append(services, custom.Name)
+		}
+	}
+
+	slices.Sort(services)
+
+	for _, name := range services {
+		definition, ok := c.services[name]
+
+		if !ok {
+			continue
+		}
+
+		prefix := fmt.Sprintf("%s.%s", serviceDefinitionsKey, name)
+		rawValues, _ := c.rawConfig.Services[name].(map[string]interface{})
+
+		values = append(values, resolveLeafValues(prefix, definition, func(leaf leafValue) (string, string) {
+			if hasRawValue(rawValues, leaf.keys) {
+				return FileSource, c.origins[prefix]
+			}
+
+			return DefaultSource, ""
+		})...)
+	}
+
+	return values, nil
+}
+
+func (c *GlobalConfig) resolveTopLevelValues() []ResolvedValue {
+	var values []ResolvedValue
+
+	for _, v := range []struct {
+		key   string
+		value string
+	}{
+		{key: "format-style", value: c.rawConfig.FormatStyle},
+		{key: "network-timeout", value: c.rawConfig.NetworkTimeout},
+		{key: "wait-timeout", value: c.rawConfig.WaitTimeout},
+		{key: "history-dir", value: c.HistoryDir()},
+	} {
+		source, origin := c.topLevelSource(v.key)
+
+		values = append(values, ResolvedValue{
+			Key:    v.key,
+			Value:  v.value,
+			Source: source,
+			Origin: origin,
+		})
+	}
+
+	retry := RetryConfig{
+		MaxAttempts:       DefaultRetryMaxAttempts,
+		BaseDelay:         DefaultRetryBaseDelay,
+		MaxDelay:          DefaultRetryMaxDelay,
+		RetryableStatuses: DefaultRetryableStatuses,
+	}.Merge(&c.rawConfig.Retry)
+
+	return append(values, resolveLeafValues(retryKey, retry, func(leaf leafValue) (string, string) {
+		return c.topLevelSource(fmt.Sprintf("%s.%s", retryKey, strings.Join(leaf.keys, ".")))
+	})...)
+}
+
+// topLevelSource returns where the top-level value comes from. Command-line options take priority over config files.
+func (c *GlobalConfig) topLevelSource(key string) (string, string) {
+	if slices.Contains(c.options, key) {
+		return OptionSource, ""
+	} else if path, ok := c.origins[key]; ok {
+		return FileSource, path
+	}
+
+	return DefaultSource, ""
+}
+
+// deploymentSource returns where the value of the deployment comes from. The selected profile and extended deployments are taken into account.
+// Environment variables of env tags take priority over the config file like loadServiceConfig.
+func (c *GlobalConfig) deploymentSource(name string, leaf leafValue) (string, string) {
+	if env, ok := leaf.field.Tag.Lookup("env"); ok && os.Getenv(env) != "" {
+		return EnvSource, env
+	}
+
+	var visited []string
+
+	for name != "" && !slices.Contains(visited, name) {
+		visited = append(visited, name)
+
+		values, _ := c.rawConfig.Deployments[name].(map[string]interface{})
+		parent, _ := values[extendsKey].(string)
+
+		if c.profile != "" {
+			overlay, _ := c.rawConfig.Profiles[c.profile].Deployments[name].(map[string]interface{})
+
+			if hasRawValue(overlay, leaf.keys) {
+				return ProfileSource, c.origins[fmt.Sprintf("%s.%s", profilesKey, c.profile)]
+			} else if v, ok := overlay[extendsKey].(string); ok {
+				parent = v
+			}
+		}
+
+		if hasRawValue(values, leaf.keys) {
+			return FileSource, c.origins[fmt.Sprintf("%s.%s", deploymentsKey, name)]
+		}
+
+		name = parent
+	}
+
+	return DefaultSource, ""
+}
+
+// hasRawValue returns true if the nested maps have a value of the keys.
+func hasRawValue(values map[string]interface{}, keys []string) bool {
+	for i, key := range keys {
+		value, found := values[key]
+
+		if !found {
+			return false
+		} else if i == len(keys)-1 {
+			return true
+		}
+
+		if values, found = value.(map[string]interface{}); !found {
+			return false
+		}
+	}
+
+	return false
+}
+
+// resolveLeafValues evaluates the config struct and returns its leaf values with their sources. Secrets are masked.
+func resolveLeafValues(prefix string, v any, source func(leaf leafValue) (string, string)) []ResolvedValue {
+	vRef := reflect.ValueOf(v)
+
+	e := newEvaluator()
+	raw := appendLeafValues(nil, nil, vRef, plainField)
+	evaluated := appendLeafValues(nil, nil, e.evaluate("", vRef, plainField), plainField)
+
+	values := make([]ResolvedValue, len(raw))
+
+	for i, leaf := range raw {
+		path := strings.Join(leaf.keys, ".")
+		value, reference := evaluated[i].String(), leaf.String()
+
+		if reference == value {
+			reference = ""
+		} else if strings.HasPrefix(reference, "base64:") {
+			// an encoded secret is a secret itself
+			reference = "base64:" + secret.Mask
+		}
+
+		if leaf.secrecy == secretField && value != "" {
+			value = secret.Mask
+		}
+
+		values[i] = ResolvedValue{
+			Key:       fmt.Sprintf("%s.%s", prefix, path),
+			Value:     secret.Redact(value),
+			Reference: secret.Redact(reference),
+		}
+
+		values[i].Source, values[i].Origin = source(leaf)
+
+		for _, err := range e.errs {
+			if strings.HasPrefix(err, path+": ") || strings.HasPrefix(err, path+".") || strings.HasPrefix(err, path+"[") {
+				_, values[i].Error, _ = strings.Cut(err, ": ")
+				break
+			}
+		}
+	}
+
+	return values
+}
+
+// leafValue is a field of a config struct that is not a struct.
+type leafValue struct {
+	keys    []string // yaml keys from the root
+	field   reflect.StructField
+	value   reflect.Value
+	secrecy secrecy
+}
+
+// appendLeafValues walks the struct in the same order as the evaluator. Nil pointers of structs have no leaf.
+func appendLeafValues(leaves []leafValue, keys []string, v reflect.Value, s secrecy) []leafValue {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		tag, found := field.Tag.Lookup("yaml")
+
+		if !found {
+			continue
+		}
+
+		key, options, _ := strings.Cut(tag, ",")
+		s := s.of(field)
+		value := v.Field(i)
+
+		if key == "-" {
+			continue
+		} else if strings.Contains(options, "inline") {
+			leaves = appendLeafValues(leaves, keys, value, s)
+			continue
+		} else if !field.IsExported() {
+			continue
+		}
+
+		fieldKeys := append(slices.Clone(keys), key)
+
+		if value.Kind() == reflect.Pointer && value.Type().Elem().Kind() == reflect.Struct {
+			if value.IsNil() {
+				continue
+			}
+
+			value = value.Elem()
+		}
+
+		if value.Kind() == reflect.Struct {
+			leaves = appendLeafValues(leaves, fieldKeys, value, s)
+		} else {
+			leaves = append(leaves, leafValue{
+				keys:    fieldKeys,
+				field:   field,
+				value:   value,
+				secrecy: s,
+			})
+		}
+	}
+
+	return leaves
+}
+
+func (l leafValue) String() string {
+	switch l.value.Kind() {
+	case reflect.String:
+		// String() works for fields of unexported embedded structs unlike Interface()
+		return l.value.String()
+	case reflect.Slice, reflect.Map:
+		if l.value.Len() == 0 {
+			return ""
+		}
+
+		if bytes, err := json.Marshal(l.value.Interface()); err != nil {
+			panic(err)
+		} else {
+			return string(bytes)
+		}
+	case reflect.Pointer:
+		if l.value.IsNil() {
+			return ""
+		}
+
+		return fmt.Sprint(l.value.Elem().Interface())
+	default:
+		if mode, ok := l.value.Interface().(os.FileMode); ok {
+			return fmt.Sprintf("%#o", uint32(mode))
+		}
+
+		return fmt.Sprint(l.value.Interface())
+	}
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"github.com/jmatsu/splitter/internal/secret"
+	"testing"
+)
+
+func Test_Config_ResolveValues(t *testing.T) {
+	t.Setenv("DEPLOYGATE_DISTRIBUTION_KEY", "key-from-env")
+	t.Setenv("RESOLVED_CONFIG_NAME", "resolved-name")
+
+	config := GlobalConfig{
+		rawConfig: rawConfig{
+			Deployments: map[string]interface{}{
+				"base": map[string]interface{}{
+					"service":        DeploygateService,
+					"app-owner-name": "owner",
+					"api-token":      "plain-token",
+				},
+				"child": map[string]interface{}{
+					"extends":           "base",
+					"distribution-name": "format:${RESOLVED_CONFIG_NAME}",
+					"pre-steps":         [][]string{{"echo", "format:${RESOLVED_CONFIG_UNSET}"}},
+				},
+			},
+			Profiles: map[string]ProfileConfig{
+				"staging": {
+					Deployments: map[string]interface{}{
+						"base": map[string]interface{}{
+							"app-owner-name": "staging-owner",
+						},
+					},
+				},
+			},
+			NetworkTimeout: "1m",
+			Retry: RetryConfig{
+				MaxAttempts: 3,
+			},
+		},
+		origins: map[string]string{
+			"deployments.base":   "base.yml",
+			"deployments.child":  "child.yml",
+			"profiles.staging":   "profile.yml",
+			"network-timeout":    "base.yml",
+			"retry.max-attempts": "base.yml",
+		},
+		options: []string{"wait-timeout"},
+		profile: "staging",
+	}
+
+	if err := config.configure(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	values, err := config.ResolveValues([]string{"child"})
+
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	resolved := map[string]ResolvedValue{}
+
+	for _, v := range values {
+		resolved[v.Key] = v
+	}
+
+	expected := map[string]ResolvedValue{
+		"format-style":       {Key: "format-style", Value: DefaultFormat, Source: DefaultSource},
+		"network-timeout":    {Key: "network-timeout", Value: "1m", Source: FileSource, Origin: "base.yml"},
+		"wait-timeout":       {Key: "wait-timeout", Value: DefaultWaitTimeout, Source: OptionSource},
+		"retry.max-attempts": {Key: "retry.max-attempts", Value: "3", Source: FileSource, Origin: "base.yml"},
+		"retry.base-delay":   {Key: "retry.base-delay", Value: DefaultRetryBaseDelay, Source: DefaultSource},
+		"deployments.child.service": {
+			Key: "deployments.child.service", Value: DeploygateService, Source: FileSource, Origin: "base.yml",
+		},
+		"deployments.child.app-owner-name": {
+			Key: "deployments.child.app-owner-name", Value: "staging-owner", Source: ProfileSource, Origin: "profile.yml",
+		},
+		"deployments.child.api-token": {
+			Key: "deployments.child.api-token", Value: secret.Mask, Source: FileSource, Origin: "base.yml",
+		},
+		"deployments.child.distribution-access-key": {
+			Key: "deployments.child.distribution-access-key", Value: "key-from-env", Source: EnvSource, Origin: "DEPLOYGATE_DISTRIBUTION_KEY",
+		},
+		"deployments.child.distribution-name": {
+			Key: "deployments.child.distribution-name", Value: "resolved-name", Source: FileSource, Origin: "child.yml", Reference: "format:${RESOLVED_CONFIG_NAME}",
+		},
+		"deployments.child.pre-steps": {
+			Key: "deployments.child.pre-steps", Value: `[["echo","format:${RESOLVED_CONFIG_UNSET}"]]`, Source: FileSource, Origin: "child.yml", Error: "RESOLVED_CONFIG_UNSET is not set",
+		},
+		"deployments.child.when": {
+			Key: "deployments.child.when", Value: "", Source: DefaultSource,
+		},
+	}
+
+	for key, e := range expected {
+		if v, ok := resolved[key]; !ok {
+			t.Errorf("%s is not resolved", key)
+		} else if v != e {
+			t.Errorf("%s: expected %v but %v", key, e, v)
+		}
+	}
+
+	if _, ok := resolved["deployments.base.service"]; ok {
+		t.Errorf("base must not be resolved")
+	}
+
+	if _, err := config.ResolveValues([]string{"unknown"}); err == nil {
+		t.Errorf("unknown deployment must be an error")
+	}
+}
+
+func Test_Config_ResolveValues_secrets(t *testing.T) {
+	t.Setenv("RESOLVED_CONFIG_SECRET", "resolved-config-secret")
+
+	config := GlobalConfig{
+		rawConfig: rawConfig{
+			Deployments: map[string]interface{}{
+				"dogfooding": map[string]interface{}{
+					"service":           DeploygateService,
+					"app-owner-name":    "owner",
+					"api-token":         "env:RESOLVED_CONFIG_SECRET",
+					"distribution-name": "base64:ZW5jb2RlZC1uYW1l",
+					// credential field
+					"distribution-access-key": "base64:ZW5jb2RlZC1rZXk=",
+				},
+			},
+		},
+	}
+
+	if err := config.configure(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	values, err := config.ResolveValues(nil)
+
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	for _, v := range values {
+		switch v.Key {
+		case "deployments.dogfooding.api-token":
+			if v.Value != secret.Mask || v.Reference != "env:RESOLVED_CONFIG_SECRET" {
+				t.Errorf("api-token must be masked but %v", v)
+			}
+		case "deployments.dogfooding.distribution-name":
+			if v.Value != "base64:ZW5jb2RlZC1uYW1l" || v.Reference != "" {
+				t.Errorf("a value of a plain field must not be resolved from base64 but %v", v)
+			}
+		case "deployments.dogfooding.distribution-access-key":
+			if v.Value != secret.Mask || v.Reference != "base64:"+secret.Mask {
+				t.Errorf("a value from base64 must be masked but %v", v)
+			}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"net/http"
+	"time"
+)
+
+const (
+	DefaultRetryMaxAttempts = 1 // no retry by default
+	DefaultRetryBaseDelay   = "1s"
+	DefaultRetryMaxDelay    = "30s"
+)
+
+var DefaultRetryableStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryConfig is a policy to retry failed requests. Zero values mean the values of the global config or the defaults.
+type RetryConfig struct {
+	// The maximum number of attempts including the first request. 1 means no retry.
+	MaxAttempts int `yaml:"max-attempts,omitempty"`
+
+	// The delay before the first retry. The delay is doubled for each retry. e.g. 1s
+	BaseDelay string `yaml:"base-delay,omitempty"`
+
+	// The upper limit of the delay. Retry-After of the response is capped by this as well. e.g. 30s
+	MaxDelay string `yaml:"max-delay,omitempty"`
+
+	// HTTP status codes to be retried. Connection errors are retried unless a non-idempotent request has been sent.
+	RetryableStatuses []int `yaml:"retryable-statuses,omitempty"`
+}
+
+// Merge returns a new config that the non-zero values of the overlay take priority.
+func (c RetryConfig) Merge(overlay *RetryConfig) RetryConfig {
+	if overlay == nil {
+		return c
+	}
+
+	if overlay.MaxAttempts != 0 {
+		c.MaxAttempts = overlay.MaxAttempts
+	}
+
+	if overlay.BaseDelay != "" {
+		c.BaseDelay = overlay.BaseDelay
+	}
+
+	if overlay.MaxDelay != "" {
+		c.MaxDelay = overlay.MaxDelay
+	}
+
+	if len(overlay.RetryableStatuses) > 0 {
+		c.RetryableStatuses = overlay.RetryableStatuses
+	}
+
+	return c
+}
+
+func (c *RetryConfig) Validate() error {
+	if c.MaxAttempts < 0 {
+		return errors.New(fmt.Sprintf("max-attempts must be positive but %d", c.MaxAttempts))
+	}
+
+	var baseDelay, maxDelay time.Duration
+
+	if c.BaseDelay != "" {
+		if v, err := time.ParseDuration(c.BaseDelay); err != nil {
+			return errors.Wrapf(err, "base-delay is not valid time format: %s", c.BaseDelay)
+		} else if v < 0 {
+			return errors.New("base-delay must be positive")
+		} else {
+			baseDelay = v
+		}
+	}
+
+	if c.MaxDelay != "" {
+		if v, err := time.ParseDuration(c.MaxDelay); err != nil {
+			return errors.Wrapf(err, "max-delay is not valid time format: %s", c.MaxDelay)
+		} else if v < 0 {
+			return errors.New("max-delay must be positive")
+		} else {
+			maxDelay = v
+		}
+	}
+
+	if c.BaseDelay != "" && c.MaxDelay != "" && baseDelay > maxDelay {
+		return errors.New(fmt.Sprintf("base-delay %s must be equal or less than max-delay %s", c.BaseDelay, c.MaxDelay))
+	}
+
+	for _, status := range c.RetryableStatuses {
+		if status < 100 || 599 < status {
+			return errors.New(fmt.Sprintf("%d is not a HTTP status code", status))
+		}
+	}
+
+	return nil
+}
+
+// RetryPolicy is the evaluated retry config.
+type RetryPolicy struct {
+	MaxAttempts       int
+	BaseDelay         time.Duration
+	MaxDelay          time.Duration
+	RetryableStatuses []int
+}
+
+// Policy returns the evaluated policy. The defaults are used for zero values.
+func (c RetryConfig) Policy() RetryPolicy {
+	c = RetryConfig{
+		MaxAttempts:       DefaultRetryMaxAttempts,
+		BaseDelay:         DefaultRetryBaseDelay,
+		MaxDelay:          DefaultRetryMaxDelay,
+		RetryableStatuses: DefaultRetryableStatuses,
+	}.Merge(&c)
+
+	// Validate() ensures the formats
+	baseDelay, _ := time.ParseDuration(c.BaseDelay)
+	maxDelay, _ := time.ParseDuration(c.MaxDelay)
+
+	if c.MaxAttempts < 1 {
+		c.MaxAttempts = 1
+	}
+
+	if maxDelay < baseDelay {
+		maxDelay = baseDelay
+	}
+
+	return RetryPolicy{
+		MaxAttempts:       c.MaxAttempts,
+		BaseDelay:         baseDelay,
+		MaxDelay:          maxDelay,
+		RetryableStatuses: c.RetryableStatuses,
+	}
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_RetryConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		config            RetryConfig
+		expectedValidness bool
+	}{
+		"fully-filled": {
+			config: RetryConfig{
+				MaxAttempts:       3,
+				BaseDelay:         "1s",
+				MaxDelay:          "1m",
+				RetryableStatuses: []int{503},
+			},
+			expectedValidness: true,
+		},
+		"negative attempts": {
+			config: RetryConfig{
+				MaxAttempts: -1,
+			},
+			expectedValidness: false,
+		},
+		"invalid delay": {
+			config: RetryConfig{
+				BaseDelay: "1",
+			},
+			expectedValidness: false,
+		},
+		"base delay over max delay": {
+			config: RetryConfig{
+				BaseDelay: "1m",
+				MaxDelay:  "1s",
+			},
+			expectedValidness: false,
+		},
+		"invalid status": {
+			config: RetryConfig{
+				RetryableStatuses: []int{1000},
+			},
+			expectedValidness: false,
+		},
+		"zero": {
+			config:            RetryConfig{},
+			expectedValidness: true,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := c.config.Validate(); (err == nil) != c.expectedValidness {
+				t.Errorf("%s case is expected to be %t but %v", name, c.expectedValidness, err)
+			}
+		})
+	}
+}
+
+func Test_RetryConfig_Policy(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		global  RetryConfig
+		overlay *RetryConfig
+
+		expected RetryPolicy
+	}{
+		"defaults": {
+			expected: RetryPolicy{
+				MaxAttempts:       1,
+				BaseDelay:         time.Second,
+				MaxDelay:          30 * time.Second,
+				RetryableStatuses: DefaultRetryableStatuses,
+			},
+		},
+		"global only": {
+			global: RetryConfig{
+				MaxAttempts: 3,
+				BaseDelay:   "2s",
+			},
+			expected: RetryPolicy{
+				MaxAttempts:       3,
+				BaseDelay:         2 * time.Second,
+				MaxDelay:          30 * time.Second,
+				RetryableStatuses: DefaultRetryableStatuses,
+			},
+		},
+		"overlay": {
+			global: RetryConfig{
+				MaxAttempts: 3,
+				BaseDelay:   "2s",
+			},
+			overlay: &RetryConfig{
+				MaxAttempts:       5,
+				MaxDelay:          "1m",
+				RetryableStatuses: []int{503},
+			},
+			expected: RetryPolicy{
+				MaxAttempts:       5,
+				BaseDelay:         2 * time.Second,
+				MaxDelay:          time.Minute,
+				RetryableStatuses: []int{503},
+			},
+		},
+		"base delay over the default max delay": {
+			overlay: &RetryConfig{
+				BaseDelay: "1m",
+			},
+			expected: RetryPolicy{
+				MaxAttempts:       1,
+				BaseDelay:         time.Minute,
+				MaxDelay:          time.Minute,
+				RetryableStatuses: DefaultRetryableStatuses,
+			},
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if actual := c.global.Merge(c.overlay).Policy(); !reflect.DeepEqual(c.expected, actual) {
+				t.Errorf("%v is expected but %v", c.expected, actual)
+			}
+		})
+	}
+}
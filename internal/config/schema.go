@@ -0,0 +1,391 @@
+package config
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+)
+
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// The doc comments of config structs are used as descriptions so that the schema never goes out of sync with the code.
+//
+//go:embed *_config.go custom_service_definition.go
+var configSources embed.FS
+
+// JSONSchema is a subset of JSON Schema draft-07 that is enough to describe the config file.
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Const                string                 `json:"const,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Properties           SchemaProperties       `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties any                    `json:"additionalProperties,omitempty"` // false or *JSONSchema
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Not                  *JSONSchema            `json:"not,omitempty"`
+	If                   *JSONSchema            `json:"if,omitempty"`
+	Then                 *JSONSchema            `json:"then,omitempty"`
+	Else                 *JSONSchema            `json:"else,omitempty"`
+	AllOf                []*JSONSchema          `json:"allOf,omitempty"`
+	AnyOf                []*JSONSchema          `json:"anyOf,omitempty"`
+	Definitions          map[string]*JSONSchema `json:"definitions,omitempty"`
+}
+
+// SchemaProperty is a pair of a key and its schema.
+type SchemaProperty struct {
+	Name   string
+	Schema *JSONSchema
+}
+
+// SchemaProperties keeps the declaration order of the fields.
+type SchemaProperties []SchemaProperty
+
+func (p SchemaProperties) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+
+	b.WriteByte('{')
+
+	for idx, property := range p {
+		if idx > 0 {
+			b.WriteByte(',')
+		}
+
+		if key, err := json.Marshal(property.Name); err != nil {
+			return nil, err
+		} else {
+			b.Write(key)
+		}
+
+		b.WriteByte(':')
+
+		if value, err := json.Marshal(property.Schema); err != nil {
+			return nil, err
+		} else {
+			b.Write(value)
+		}
+	}
+
+	b.WriteByte('}')
+
+	return b.Bytes(), nil
+}
+
+// Get returns the schema of the key or nil.
+func (p SchemaProperties) Get(name string) *JSONSchema {
+	for _, property := range p {
+		if property.Name == name {
+			return property.Schema
+		}
+	}
+
+	return nil
+}
+
+func (p *SchemaProperties) set(name string, schema *JSONSchema) {
+	for idx, property := range *p {
+		if property.Name == name {
+			(*p)[idx].Schema = schema
+			return
+		}
+	}
+
+	*p = append(*p, SchemaProperty{Name: name, Schema: schema})
+}
+
+const customServiceSchemaName = "custom-service"
+
+// builtinServiceTypes are the services that are distinguished by service key. The order is used in the schema.
+var builtinServiceTypes = []struct {
+	name string
+	t    reflect.Type
+}{
+	{DeploygateService, reflect.TypeOf(DeployGateConfig{})},
+	{FirebaseAppDistributionService, reflect.TypeOf(FirebaseAppDistributionConfig{})},
+	{LocalService, reflect.TypeOf(LocalConfig{})},
+	{TestFlightService, reflect.TypeOf(TestFlightConfig{})},
+}
+
+type schemaBuilder struct {
+	comments map[string]string // <type name>.<field name> -> doc comment
+}
+
+// GenerateJSONSchema builds the JSON Schema of the config file from the config structs via the reflection.
+func GenerateJSONSchema() *JSONSchema {
+	b := &schemaBuilder{
+		comments: parseConfigComments(),
+	}
+
+	extends := &JSONSchema{
+		Type:        "string",
+		Description: "A deployment name to inherit values from. Mappings are deep-merged and the other values are overridden by this deployment.",
+	}
+
+	abstract := &JSONSchema{
+		Type:        "boolean",
+		Description: "true if this deployment is only extended by other deployments. An abstract deployment cannot be deployed, and it does not have to have service key and the required values.",
+	}
+
+	var builtinNames []string
+	definitions := map[string]*JSONSchema{}
+	var branches []*JSONSchema
+
+	for _, service := range builtinServiceTypes {
+		schema := b.deploymentSchema(service.t, &JSONSchema{
+			Const: service.name,
+		}, extends, abstract)
+		schema.Description = fmt.Sprintf("A deployment of %s service.", service.name)
+
+		definitions[service.name] = schema
+		builtinNames = append(builtinNames, service.name)
+
+		branches = append(branches, &JSONSchema{
+			If: &JSONSchema{
+				Required: []string{"service"},
+				Properties: SchemaProperties{
+					{Name: "service", Schema: &JSONSchema{Const: service.name}},
+				},
+			},
+			Then: &JSONSchema{Ref: "#/definitions/" + service.name},
+		})
+	}
+
+	custom := b.deploymentSchema(reflect.TypeOf(CustomServiceConfig{}), &JSONSchema{
+		Type:        "string",
+		Description: "A name of a custom service that is defined in services.",
+	}, extends, abstract)
+	custom.Description = "A deployment of a custom service."
+	definitions[customServiceSchemaName] = custom
+
+	branches = append(branches, &JSONSchema{
+		If: &JSONSchema{
+			Required: []string{"service"},
+			Properties: SchemaProperties{
+				{Name: "service", Schema: &JSONSchema{Not: &JSONSchema{Enum: builtinNames}}},
+			},
+		},
+		Then: &JSONSchema{Ref: "#/definitions/" + customServiceSchemaName},
+	})
+
+	definitions["deployment"] = &JSONSchema{
+		Type:        "object",
+		Description: "A deployment. service key determines the available keys.",
+		// the service and the required values may be given by the parent or the children
+		If: &JSONSchema{
+			AnyOf: []*JSONSchema{
+				{Required: []string{extendsKey}},
+				{Required: []string{abstractKey}},
+			},
+		},
+		Then: &JSONSchema{
+			Properties: SchemaProperties{
+				{Name: extendsKey, Schema: extends},
+				{Name: abstractKey, Schema: abstract},
+			},
+		},
+		Else: &JSONSchema{
+			Required: []string{"service"},
+			AllOf:    branches,
+		},
+	}
+
+	definitions["service-definition"] = b.objectSchema(reflect.TypeOf(CustomServiceDefinition{}))
+	definitions["service-definition"].Description = "A definition of a custom service."
+
+	root := b.objectSchema(reflect.TypeOf(rawConfig{}))
+	root.Schema = jsonSchemaDraft
+	root.Description = "The config file of splitter."
+	root.Definitions = definitions
+
+	root.Properties.set(deploymentsKey, &JSONSchema{
+		Type:                 "object",
+		Description:          root.Properties.Get(deploymentsKey).Description,
+		AdditionalProperties: &JSONSchema{Ref: "#/definitions/deployment"},
+	})
+
+	root.Properties.set(serviceDefinitionsKey, &JSONSchema{
+		Type:                 "object",
+		Description:          root.Properties.Get(serviceDefinitionsKey).Description,
+		AdditionalProperties: &JSONSchema{Ref: "#/definitions/service-definition"},
+	})
+
+	return root
+}
+
+// deploymentSchema puts service key, extends key and abstract key at the top of the properties.
+func (b *schemaBuilder) deploymentSchema(t reflect.Type, service *JSONSchema, extends *JSONSchema, abstract *JSONSchema) *JSONSchema {
+	schema := b.objectSchema(t)
+
+	properties := SchemaProperties{
+		{Name: "service", Schema: service},
+		{Name: extendsKey, Schema: extends},
+		{Name: abstractKey, Schema: abstract},
+	}
+
+	for _, property := range schema.Properties {
+		if property.Name != "service" && property.Name != extendsKey && property.Name != abstractKey {
+			properties = append(properties, property)
+		}
+	}
+
+	schema.Properties = properties
+
+	return schema
+}
+
+func (b *schemaBuilder) typeSchema(t reflect.Type) *JSONSchema {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return b.typeSchema(t.Elem())
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &JSONSchema{Type: "array", Items: b.typeSchema(t.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Type: "object", AdditionalProperties: b.typeSchema(t.Elem())}
+	case reflect.Struct:
+		return b.objectSchema(t)
+	default:
+		return &JSONSchema{}
+	}
+}
+
+// objectSchema follows the rules of validateMissingValues. Fields that can be given by environment variables are not required in the file.
+func (b *schemaBuilder) objectSchema(t reflect.Type) *JSONSchema {
+	schema := &JSONSchema{
+		Type:                 "object",
+		Properties:           SchemaProperties{},
+		AdditionalProperties: false,
+	}
+
+	b.collectProperties(t, schema)
+
+	return schema
+}
+
+func (b *schemaBuilder) collectProperties(t reflect.Type, schema *JSONSchema) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, found := field.Tag.Lookup("yaml")
+
+		if !found {
+			continue
+		}
+
+		key, options, _ := strings.Cut(tag, ",")
+
+		if key == "-" {
+			continue
+		} else if strings.Contains(options, "inline") {
+			b.collectProperties(field.Type, schema)
+			continue
+		} else if key == "" {
+			key = strings.ToLower(field.Name)
+		}
+
+		property := b.typeSchema(field.Type)
+
+		if comment := b.comments[t.Name()+"."+field.Name]; comment != "" {
+			property.Description = comment
+		}
+
+		if values, found := field.Tag.Lookup("enum"); found {
+			property.Enum = strings.Split(values, ",")
+		}
+
+		envName, hasEnv := field.Tag.Lookup("env")
+		required := field.Tag.Get("required") == "true"
+
+		if hasEnv && required {
+			property.Description = appendSentence(property.Description, fmt.Sprintf("Required unless $%s is given.", envName))
+		} else if hasEnv {
+			property.Description = appendSentence(property.Description, fmt.Sprintf("This can be given by $%s.", envName))
+		} else if required {
+			schema.Required = append(schema.Required, key)
+		}
+
+		schema.Properties.set(key, property)
+	}
+}
+
+// parseConfigComments reads the doc comments of struct fields from the embedded sources.
+func parseConfigComments() map[string]string {
+	comments := map[string]string{}
+	fset := token.NewFileSet()
+
+	entries, _ := configSources.ReadDir(".")
+
+	for _, entry := range entries {
+		src, err := configSources.ReadFile(entry.Name())
+
+		if err != nil {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, entry.Name(), src, parser.ParseComments)
+
+		if err != nil {
+			continue
+		}
+
+		ast.Inspect(file, func(node ast.Node) bool {
+			decl, ok := node.(*ast.GenDecl)
+
+			if !ok || decl.Tok != token.TYPE {
+				return true
+			}
+
+			for _, spec := range decl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				structType, ok := typeSpec.Type.(*ast.StructType)
+
+				if !ok {
+					continue
+				}
+
+				for _, field := range structType.Fields.List {
+					if field.Doc == nil {
+						continue
+					}
+
+					for _, name := range field.Names {
+						comments[typeSpec.Name.Name+"."+name.Name] = normalizeComment(field.Doc.Text())
+					}
+				}
+			}
+
+			return false
+		})
+	}
+
+	return comments
+}
+
+func normalizeComment(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func appendSentence(text string, sentence string) string {
+	if text == "" {
+		return sentence
+	} else if !strings.HasSuffix(text, ".") {
+		text += "."
+	}
+
+	return text + " " + sentence
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"fmt"
+	"golang.org/x/exp/slices"
+	"strings"
+)
+
+const schemaDocumentIndent = "    "
+
+// RenderSchemaDocument renders the schema as a commented YAML reference like splitter.document.yml.
+func RenderSchemaDocument(schema *JSONSchema) string {
+	r := &schemaDocumentRenderer{
+		definitions: schema.Definitions,
+	}
+
+	r.renderProperties(schema, "")
+
+	// the first child follows its parent directly
+	var lines []string
+
+	for _, line := range strings.Split(strings.TrimLeft(r.b.String(), "\n"), "\n") {
+		if line == "" && len(lines) > 0 && strings.HasSuffix(lines[len(lines)-1], ":") {
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+type schemaDocumentRenderer struct {
+	b           strings.Builder
+	definitions map[string]*JSONSchema
+}
+
+func (r *schemaDocumentRenderer) resolve(schema *JSONSchema) *JSONSchema {
+	if strings.HasPrefix(schema.Ref, "#/definitions/") {
+		if resolved, ok := r.definitions[strings.TrimPrefix(schema.Ref, "#/definitions/")]; ok {
+			return resolved
+		}
+	}
+
+	return schema
+}
+
+func (r *schemaDocumentRenderer) renderProperties(schema *JSONSchema, indent string) {
+	for _, property := range schema.Properties {
+		r.renderProperty(property.Name, property.Schema, slices.Contains(schema.Required, property.Name), indent)
+	}
+}
+
+func (r *schemaDocumentRenderer) renderComment(schema *JSONSchema, required bool, indent string) {
+	r.b.WriteString("\n")
+
+	if schema.Description != "" {
+		r.b.WriteString(fmt.Sprintf("%s# %s\n", indent, schema.Description))
+	}
+
+	if required {
+		r.b.WriteString(fmt.Sprintf("%s# Required\n", indent))
+	} else {
+		r.b.WriteString(fmt.Sprintf("%s# Optional\n", indent))
+	}
+}
+
+func (r *schemaDocumentRenderer) renderProperty(key string, schema *JSONSchema, required bool, indent string) {
+	schema = r.resolve(schema)
+
+	if schema.Const != "" {
+		r.b.WriteString(fmt.Sprintf("\n%s%s: %s\n", indent, key, schema.Const))
+		return
+	}
+
+	r.renderComment(schema, required, indent)
+
+	switch {
+	case len(schema.Properties) > 0:
+		r.b.WriteString(fmt.Sprintf("%s%s:\n", indent, key))
+		r.renderProperties(schema, indent+schemaDocumentIndent)
+	case schema.Type == "object":
+		if value, ok := schema.AdditionalProperties.(*JSONSchema); ok {
+			if resolved := r.resolve(value); resolved.Else != nil && len(resolved.Else.AllOf) > 0 {
+				r.b.WriteString(fmt.Sprintf("%s%s:\n", indent, key))
+				r.renderUnion(resolved, indent+schemaDocumentIndent)
+			} else if len(resolved.Properties) > 0 {
+				r.b.WriteString(fmt.Sprintf("%s%s:\n", indent, key))
+				r.renderProperty(fmt.Sprintf("<%s-name>", strings.TrimSuffix(key, "s")), resolved, false, indent+schemaDocumentIndent)
+			} else {
+				r.b.WriteString(fmt.Sprintf("%s%s:\n%s%s<name>: %s\n", indent, key, indent, schemaDocumentIndent, r.typeName(resolved)))
+			}
+		} else {
+			r.b.WriteString(fmt.Sprintf("%s%s: object\n", indent, key))
+		}
+	case schema.Type == "array":
+		r.b.WriteString(fmt.Sprintf("%s%s:\n%s%s- %s\n", indent, key, indent, schemaDocumentIndent, r.typeName(schema.Items)))
+	default:
+		r.b.WriteString(fmt.Sprintf("%s%s: %s\n", indent, key, r.typeName(schema)))
+	}
+}
+
+// renderUnion renders each member of the union as an example that is named by the member.
+func (r *schemaDocumentRenderer) renderUnion(schema *JSONSchema, indent string) {
+	for _, branch := range schema.Else.AllOf {
+		if branch.Then == nil {
+			continue
+		}
+
+		member := r.resolve(branch.Then)
+
+		r.b.WriteString(fmt.Sprintf("\n%s# %s\n", indent, member.Description))
+		r.b.WriteString(fmt.Sprintf("%s%s:\n", indent, strings.TrimPrefix(branch.Then.Ref, "#/definitions/")))
+		r.renderProperties(member, indent+schemaDocumentIndent)
+	}
+}
+
+func (r *schemaDocumentRenderer) typeName(schema *JSONSchema) string {
+	if schema == nil {
+		return "any"
+	}
+
+	schema = r.resolve(schema)
+
+	switch {
+	case len(schema.Enum) > 0:
+		return strings.Join(schema.Enum, " | ")
+	case schema.Type == "array":
+		return fmt.Sprintf("[%s, ...]", r.typeName(schema.Items))
+	case schema.Type == "object":
+		if value, ok := schema.AdditionalProperties.(*JSONSchema); ok {
+			return fmt.Sprintf("map[string]%s", r.typeName(value))
+		}
+
+		return "object"
+	case schema.Type != "":
+		return schema.Type
+	default:
+		return "any"
+	}
+}
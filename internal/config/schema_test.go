@@ -0,0 +1,183 @@
+package config
+
+import (
+	"encoding/json"
+	"golang.org/x/exp/slices"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_GenerateJSONSchema(t *testing.T) {
+	t.Parallel()
+
+	schema := GenerateJSONSchema()
+
+	if bytes, err := json.Marshal(schema); err != nil {
+		t.Fatalf("the schema is expected to be marshaled but %v", err)
+	} else if !json.Valid(bytes) {
+		t.Fatalf("the schema is expected to be valid JSON")
+	}
+
+	cases := map[string]struct {
+		definition string
+		t          reflect.Type
+
+		expectedRequired    []string
+		expectedNotRequired []string
+	}{
+		DeploygateService: {
+			definition:          DeploygateService,
+			t:                   reflect.TypeOf(DeployGateConfig{}),
+			expectedNotRequired: []string{"app-owner-name", "api-token"},
+		},
+		FirebaseAppDistributionService: {
+			definition: FirebaseAppDistributionService,
+			t:          reflect.TypeOf(FirebaseAppDistributionConfig{}),
+		},
+		LocalService: {
+			definition:       LocalService,
+			t:                reflect.TypeOf(LocalConfig{}),
+			expectedRequired: []string{"destination-path"},
+		},
+		TestFlightService: {
+			definition: TestFlightService,
+			t:          reflect.TypeOf(TestFlightConfig{}),
+		},
+		customServiceSchemaName: {
+			definition: customServiceSchemaName,
+			t:          reflect.TypeOf(CustomServiceConfig{}),
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			definition, ok := schema.Definitions[c.definition]
+
+			if !ok {
+				t.Fatalf("%s definition is expected to exist", c.definition)
+			}
+
+			for _, key := range []string{"service", extendsKey, abstractKey} {
+				if definition.Properties.Get(key) == nil {
+					t.Errorf("%s key is expected to exist", key)
+				}
+			}
+
+			for _, key := range yamlKeys(c.t) {
+				if definition.Properties.Get(key) == nil {
+					t.Errorf("%s key is expected to exist", key)
+				}
+			}
+
+			for _, key := range c.expectedRequired {
+				if !slices.Contains(definition.Required, key) {
+					t.Errorf("%s key is expected to be required", key)
+				}
+			}
+
+			for _, key := range c.expectedNotRequired {
+				if slices.Contains(definition.Required, key) {
+					t.Errorf("%s key is expected not to be required", key)
+				}
+			}
+
+			found := false
+
+			for _, branch := range schema.Definitions["deployment"].Else.AllOf {
+				if branch.Then.Ref == "#/definitions/"+c.definition {
+					found = true
+				}
+			}
+
+			if !found {
+				t.Errorf("deployment definition is expected to refer to %s", c.definition)
+			}
+		})
+	}
+}
+
+func Test_GenerateJSONSchema_globalConfig(t *testing.T) {
+	t.Parallel()
+
+	schema := GenerateJSONSchema()
+
+	for _, key := range yamlKeys(reflect.TypeOf(rawConfig{})) {
+		if schema.Properties.Get(key) == nil {
+			t.Errorf("%s key is expected to exist", key)
+		}
+	}
+
+	var expectedStyles []string
+
+	for _, style := range styles {
+		expectedStyles = append(expectedStyles, string(style))
+	}
+
+	if actual := schema.Properties.Get("format-style").Enum; !slices.Equal(actual, expectedStyles) {
+		t.Errorf("%v is expected but %v", expectedStyles, actual)
+	}
+
+	if description := schema.Definitions[LocalService].Properties.Get("destination-path").Description; description == "" {
+		t.Errorf("the doc comment is expected to be used as the description")
+	}
+
+	if description := schema.Definitions[DeploygateService].Properties.Get("api-token").Description; !strings.Contains(description, "$DEPLOYGATE_API_TOKEN") {
+		t.Errorf("the description is expected to mention the environment variable but %s", description)
+	}
+}
+
+func Test_RenderSchemaDocument(t *testing.T) {
+	t.Parallel()
+
+	document := RenderSchemaDocument(GenerateJSONSchema())
+
+	for _, expected := range []string{"deployments:", "    local:", "        destination-path: string", "format-style: pretty | raw | markdown"} {
+		if !strings.Contains(document, expected) {
+			t.Errorf("%s is expected to be rendered", expected)
+		}
+	}
+}
+
+func Test_RenderSchemaDocument_committed(t *testing.T) {
+	t.Parallel()
+
+	committed, err := os.ReadFile(filepath.Join("..", "..", "splitter.document.yml"))
+
+	if err != nil {
+		t.Fatalf("splitter.document.yml is expected to exist but %v", err)
+	}
+
+	if document := RenderSchemaDocument(GenerateJSONSchema()); string(committed) != document {
+		t.Errorf("splitter.document.yml is out of date. Run go generate in the repository root")
+	}
+}
+
+// yamlKeys returns the keys of yaml-tagged fields including inline fields.
+func yamlKeys(t reflect.Type) []string {
+	var keys []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, found := field.Tag.Lookup("yaml")
+
+		if !found {
+			continue
+		}
+
+		key, options, _ := strings.Cut(tag, ",")
+
+		if strings.Contains(options, "inline") {
+			keys = append(keys, yamlKeys(field.Type)...)
+		} else if key != "-" && key != "" {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
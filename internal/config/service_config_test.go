@@ -2,9 +2,13 @@ package config
 
 import (
 	"fmt"
+	"github.com/jmatsu/splitter/internal/secret"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -26,6 +30,8 @@ func Test_evaluateValues(t *testing.T) {
 		config   testConfig
 		envs     map[string]string
 		expected testConfig
+
+		expectedErr bool
 	}{
 		"no expansion": {
 			config: testConfig{
@@ -50,6 +56,7 @@ func Test_evaluateValues(t *testing.T) {
 			},
 			envs: map[string]string{
 				"FROM_ENV_VALUE1": sampleValue1,
+				"FROM_ENV_VALUE2": "",
 			},
 			expected: testConfig{
 				ValueParam:           sampleValue1,
@@ -58,6 +65,29 @@ func Test_evaluateValues(t *testing.T) {
 				RequiredPointerParam: &sampleValue2,
 			},
 		},
+		"unset variables": {
+			config: testConfig{
+				ValueParam:         "format:${FROM_ENV_VALUE1}",
+				RequiredValueParam: "format:${FROM_ENV_UNSET}",
+			},
+			envs: map[string]string{
+				"FROM_ENV_VALUE1": sampleValue1,
+			},
+			expectedErr: true,
+		},
+		"pointers": {
+			config: testConfig{
+				PointerParam:         func(v string) *string { return &v }("format:$FROM_ENV_VALUE1"),
+				RequiredPointerParam: func(v string) *string { return &v }("format:${FROM_ENV_VALUE1}-$${FROM_ENV_VALUE1}"),
+			},
+			envs: map[string]string{
+				"FROM_ENV_VALUE1": sampleValue1,
+			},
+			expected: testConfig{
+				PointerParam:         &sampleValue1,
+				RequiredPointerParam: func(v string) *string { return &v }(sampleValue1 + "-${FROM_ENV_VALUE1}"),
+			},
+		},
 	}
 
 	for name, c := range cases {
@@ -69,7 +99,11 @@ func Test_evaluateValues(t *testing.T) {
 				}
 			}
 
-			if err := evaluateValues(&c.config); err != nil {
+			if err := evaluateValues(&c.config); c.expectedErr {
+				if err == nil {
+					t.Errorf("%s case is expected to fail", name)
+				}
+			} else if err != nil {
 				t.Errorf("%s case is expected to be success but not: %v", name, err)
 			} else if err := c.config.assertEquals(c.expected); err != nil {
 				t.Errorf("%v is expected to be equal to %v but not: %v", c.config, c.expected, err)
@@ -78,6 +112,153 @@ func Test_evaluateValues(t *testing.T) {
 	}
 }
 
+func Test_evaluateValues_nested(t *testing.T) {
+	t.Setenv("FROM_ENV_GROUP", "testers")
+	t.Setenv("FROM_ENV_TOKEN", "token")
+
+	steps := [][]string{{"echo", "format:${FROM_ENV_GROUP}"}}
+
+	config := FirebaseAppDistributionConfig{
+		ExecutionConfig: ExecutionConfig{
+			PreSteps: steps,
+			Retry: &RetryConfig{
+				BaseDelay: "format:${FROM_ENV_DELAY:-1s}",
+			},
+		},
+		GroupAliases: []string{"format:${FROM_ENV_GROUP}", "format:${FROM_ENV_MISSING:-qa}"},
+	}
+
+	if err := evaluateValues(&config); err != nil {
+		t.Fatalf("nested values are expected to be evaluated but %v", err)
+	}
+
+	if expected := []string{"testers", "qa"}; !reflect.DeepEqual(config.GroupAliases, expected) {
+		t.Errorf("%v is expected but %v", expected, config.GroupAliases)
+	}
+
+	if actual := config.PreSteps[0][1]; actual != "testers" {
+		t.Errorf("testers is expected but %s", actual)
+	}
+
+	if actual := config.Retry.BaseDelay; actual != "1s" {
+		t.Errorf("1s is expected but %s", actual)
+	}
+
+	if steps[0][1] != "format:${FROM_ENV_GROUP}" {
+		t.Errorf("the original values must not be modified but %s", steps[0][1])
+	}
+
+	definition := CustomServiceDefinition{
+		DefaultRequestDefinition: DefaultRequestDefinition{
+			Headers: map[string]string{
+				"X-Token": "format:${FROM_ENV_TOKEN}",
+			},
+			Queries: map[string][]string{
+				"group": {"format:${FROM_ENV_UNSET:?group is required}"},
+			},
+		},
+	}
+
+	if err := evaluateValues(&definition); err == nil {
+		t.Errorf("the unset variable is expected to fail")
+	} else if !strings.Contains(err.Error(), "default.queries.group[0]") || !strings.Contains(err.Error(), "group is required") {
+		t.Errorf("the error is expected to name the field and contain the message but %v", err)
+	}
+}
+
+func Test_expandFormat(t *testing.T) {
+	t.Parallel()
+
+	envs := map[string]string{
+		"NAME":  "value",
+		"EMPTY": "",
+	}
+
+	lookupEnv := func(name string) (string, bool) {
+		v, ok := envs[name]
+		return v, ok
+	}
+
+	cases := map[string]struct {
+		format string
+
+		expected    string
+		expectedErr bool
+	}{
+		"no variables": {
+			format:   "plain text",
+			expected: "plain text",
+		},
+		"simple": {
+			format:   "$NAME/${NAME}.apk",
+			expected: "value/value.apk",
+		},
+		"escaped": {
+			format:   "$$NAME costs $5",
+			expected: "$NAME costs $5",
+		},
+		"trailing dollar": {
+			format:   "price$",
+			expected: "price$",
+		},
+		"empty": {
+			format:   "[${EMPTY}]",
+			expected: "[]",
+		},
+		"default of unset": {
+			format:   "${UNSET:-fallback}",
+			expected: "fallback",
+		},
+		"default of empty": {
+			format:   "${EMPTY:-fallback}",
+			expected: "fallback",
+		},
+		"nested default": {
+			format:   "${UNSET:-${NAME}}",
+			expected: "value",
+		},
+		"required and set": {
+			format:   "${NAME:?must be set}",
+			expected: "value",
+		},
+		"required and empty": {
+			format:      "${EMPTY:?must be set}",
+			expectedErr: true,
+		},
+		"unset": {
+			format:      "$UNSET",
+			expectedErr: true,
+		},
+		"not closed": {
+			format:      "${NAME",
+			expectedErr: true,
+		},
+		"bad substitution": {
+			format:      "${NAME%.apk}",
+			expectedErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := expandFormat(c.format, lookupEnv)
+
+			if c.expectedErr {
+				if err == nil {
+					t.Errorf("%s case is expected to fail but %s", name, actual)
+				}
+			} else if err != nil {
+				t.Errorf("%s case is expected to succeed but %v", name, err)
+			} else if actual != c.expected {
+				t.Errorf("%s is expected but %s", c.expected, actual)
+			}
+		})
+	}
+}
+
 func Test_validateMissingValues(t *testing.T) {
 	t.Parallel()
 
@@ -229,3 +410,220 @@ func Test_loadServiceConfig(t *testing.T) {
 		})
 	}
 }
+
+func Test_evaluator_sources(t *testing.T) {
+	t.Parallel()
+
+	tokenPath := filepath.Join(t.TempDir(), "token.txt")
+
+	if err := os.WriteFile(tokenPath, []byte("file-token\n"), 0600); err != nil {
+		t.Fatalf("failed to prepare a file: %v", err)
+	}
+
+	e := &evaluator{
+		lookupEnv: func(name string) (string, bool) {
+			if name == "SOURCE_TOKEN" {
+				return "env-token", true
+			}
+
+			return "", false
+		},
+		readFile: os.ReadFile,
+		runCommand: func(name string, args ...string) ([]byte, error) {
+			if name == "pass" && reflect.DeepEqual(args, []string{"show", "deploygate"}) {
+				return []byte("cmd-token\n"), nil
+			} else if name == "op" && reflect.DeepEqual(args, []string{"read", "op://vault/My Item/token"}) {
+				return []byte("quoted-cmd-token\n"), nil
+			}
+
+			return nil, errors.New("unknown command")
+		},
+	}
+
+	cases := map[string]struct {
+		value string
+
+		expected    string
+		expectedErr bool
+	}{
+		"literal": {
+			value:    "https://example.com",
+			expected: "https://example.com",
+		},
+		"env": {
+			value:    "env:SOURCE_TOKEN",
+			expected: "env-token",
+		},
+		"unset env": {
+			value:       "env:SOURCE_UNSET",
+			expectedErr: true,
+		},
+		"file": {
+			value:    "file:" + tokenPath,
+			expected: "file-token",
+		},
+		"missing file": {
+			value:       "file:" + tokenPath + ".missing",
+			expectedErr: true,
+		},
+		"cmd": {
+			value:    "cmd:pass show deploygate",
+			expected: "cmd-token",
+		},
+		"cmd with a quoted argument": {
+			value:    `cmd:op read "op://vault/My Item/token"`,
+			expected: "quoted-cmd-token",
+		},
+		"unclosed quote": {
+			value:       `cmd:op read "op://vault/My Item/token`,
+			expectedErr: true,
+		},
+		"failed cmd": {
+			value:       "cmd:pass show unknown",
+			expectedErr: true,
+		},
+		"base64": {
+			value:    "base64:YmFzZTY0LXRva2Vu",
+			expected: "base64-token",
+		},
+		"broken base64": {
+			value:       "base64:!!!",
+			expectedErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			e := &evaluator{
+				lookupEnv:  e.lookupEnv,
+				readFile:   e.readFile,
+				runCommand: e.runCommand,
+			}
+
+			// the sources are not available for plain fields
+			if plain := e.evaluate("release-note", reflect.ValueOf(c.value), plainField).String(); len(e.errs) > 0 || plain != c.value && !strings.HasPrefix(c.value, "format:") {
+				t.Errorf("%s must be kept as it is for plain fields but %s, %v", c.value, plain, e.errs)
+			}
+
+			actual := e.evaluate("api-token", reflect.ValueOf(c.value), credentialField).String()
+
+			if c.expectedErr {
+				if len(e.errs) == 0 {
+					t.Errorf("%s case is expected to fail but %s", name, actual)
+				}
+			} else if len(e.errs) > 0 {
+				t.Errorf("%s case is expected to succeed but %v", name, e.errs)
+			} else if actual != c.expected {
+				t.Errorf("%s is expected but %s", c.expected, actual)
+			} else if name == "literal" {
+				if secret.IsRegistered(actual) {
+					t.Errorf("literal values of fields without secret tag must not be secrets: %s", actual)
+				}
+			} else if !secret.IsRegistered(actual) {
+				t.Errorf("values from the sources are expected to be secrets: %s", actual)
+			}
+		})
+	}
+}
+
+func Test_splitCommandLine(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		commandLine string
+
+		expected    []string
+		expectedErr bool
+	}{
+		"spaces": {
+			commandLine: "  pass   show\tdeploygate ",
+			expected:    []string{"pass", "show", "deploygate"},
+		},
+		"double quotes": {
+			commandLine: `op read "op://vault/My Item/token" "a \"quoted\" \\ value"`,
+			expected:    []string{"op", "read", "op://vault/My Item/token", `a "quoted" \ value`},
+		},
+		"single quotes": {
+			commandLine: `echo 'a "b" \c' x'y z'`,
+			expected:    []string{"echo", `a "b" \c`, "xy z"},
+		},
+		"escapes": {
+			commandLine: `echo a\ b \'c`,
+			expected:    []string{"echo", "a b", "'c"},
+		},
+		"empty quotes": {
+			commandLine: `echo ""`,
+			expected:    []string{"echo", ""},
+		},
+		"empty": {
+			commandLine: " ",
+			expected:    nil,
+		},
+		"unclosed double quote": {
+			commandLine: `echo "a`,
+			expectedErr: true,
+		},
+		"unclosed single quote": {
+			commandLine: `echo 'a`,
+			expectedErr: true,
+		},
+		"trailing escape": {
+			commandLine: `echo a\`,
+			expectedErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := splitCommandLine(c.commandLine)
+
+			if c.expectedErr {
+				if err == nil {
+					t.Errorf("%s case is expected to fail but %v", name, actual)
+				}
+			} else if err != nil {
+				t.Errorf("%s case is expected to succeed but %v", name, err)
+			} else if !reflect.DeepEqual(c.expected, actual) {
+				t.Errorf("%q is expected but %q", c.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_evaluateValues_plainFields(t *testing.T) {
+	t.Parallel()
+
+	config := LocalConfig{
+		DestinationPath: "file:./out/app.apk",
+	}
+
+	if err := evaluateValues(&config); err != nil {
+		t.Fatalf("the config is expected to be evaluated but %v", err)
+	}
+
+	if config.DestinationPath != "file:./out/app.apk" {
+		t.Errorf("values of plain fields must not be reinterpreted but %s", config.DestinationPath)
+	}
+}
+
+func Test_evaluateValues_secretTag(t *testing.T) {
+	t.Parallel()
+
+	config := CustomServiceConfig{
+		AuthToken: "literal-auth-token-for-secret-tag",
+	}
+
+	if err := evaluateValues(&config); err != nil {
+		t.Fatalf("the config is expected to be evaluated but %v", err)
+	}
+
+	if !secret.IsRegistered(config.AuthToken) {
+		t.Errorf("values of fields that have secret tag are expected to be secrets")
+	}
+}
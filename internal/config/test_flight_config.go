@@ -14,10 +14,10 @@ type TestFlightConfig struct {
 	AppleID string `yaml:"apple-id" required:"true"`
 
 	// App-specific password
-	Password string `yaml:"password,omitempty"`
+	Password string `yaml:"password,omitempty" secret:"true"`
 
 	// Api Key
-	ApiKey string `yaml:"api-key,omitempty"`
+	ApiKey string `yaml:"api-key,omitempty" secret:"true"`
 
 	// Issuer ID of the specified api key
 	IssuerID string `yaml:"issuer-id,omitempty"`
@@ -22,6 +22,21 @@ func NewAltool(ctx context.Context) *Altool {
 }
 
 func (n *Altool) UploadApp(path, appleID string, credential *AltoolCredential) ([]byte, error) {
+	stdout, _, err := n.exec("--upload-app", uploadAppArgs(path, appleID, credential)...)
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to execute altool")
+	}
+
+	return stdout, nil
+}
+
+// UploadAppCommand returns the command line that UploadApp executes.
+func (n *Altool) UploadAppCommand(path, appleID string, credential *AltoolCredential) []string {
+	return append([]string{"xcrun", "altool", "--upload-app"}, uploadAppArgs(path, appleID, credential)...)
+}
+
+func uploadAppArgs(path, appleID string, credential *AltoolCredential) []string {
 	args := []string{
 		"-f", path,
 		"-t", "ios",
@@ -34,13 +49,7 @@ func (n *Altool) UploadApp(path, appleID string, credential *AltoolCredential) (
 		args = append(args, "--apiKey", credential.ApiKey, "--apiIssuer", credential.IssuerID)
 	}
 
-	stdout, _, err := n.exec("--upload-app", args...)
-
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to execute altool")
-	}
-
-	return stdout, nil
+	return args
 }
 
 func (n *Altool) exec(subcommand string, args ...string) ([]byte, []byte, error) {
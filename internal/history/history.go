@@ -0,0 +1,211 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const FileName = "history.jsonl"
+
+type Status = string
+
+const (
+	Succeeded Status = "succeeded"
+	Failed    Status = "failed"
+)
+
+// Record is one line of the history file. A record is appended for each file of a deployment.
+type Record struct {
+	Deployment  string            `json:"deployment"`
+	Service     string            `json:"service"`
+	Status      Status            `json:"status"`
+	FileName    string            `json:"file_name"`
+	Size        int64             `json:"size,omitempty"`
+	SHA256      string            `json:"sha256,omitempty"`
+	PackageName string            `json:"package_name,omitempty"`
+	VersionName string            `json:"version_name,omitempty"`
+	VersionCode string            `json:"version_code,omitempty"`
+	URLs        map[string]string `json:"urls,omitempty"`
+	Error       string            `json:"error,omitempty"`
+	StartedAt   time.Time         `json:"started_at"`
+	FinishedAt  time.Time         `json:"finished_at"`
+}
+
+// appendLock serializes writes from concurrent deployments so that lines are never interleaved.
+var appendLock sync.Mutex
+
+// Ledger is an append-only history file in a directory.
+type Ledger struct {
+	path string
+}
+
+func NewLedger(dir string) *Ledger {
+	return &Ledger{
+		path: filepath.Join(dir, FileName),
+	}
+}
+
+func (l *Ledger) Path() string {
+	return l.path
+}
+
+// Append writes the record as one line. The directory is created if it does not exist.
+func (l *Ledger) Append(record Record) error {
+	bytes, err := json.Marshal(record)
+
+	if err != nil {
+		return errors.Wrap(err, "cannot serialize the history record")
+	}
+
+	appendLock.Lock()
+	defer appendLock.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return errors.Wrapf(err, "cannot create the history directory of %s", l.path)
+	}
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+
+	if err != nil {
+		return errors.Wrapf(err, "cannot open %s", l.path)
+	}
+
+	//goland:noinspection GoUnhandledErrorResult
+	defer f.Close()
+
+	if _, err := f.Write(append(bytes, '\n')); err != nil {
+		return errors.Wrapf(err, "cannot write a history record to %s", l.path)
+	}
+
+	return nil
+}
+
+// Records reads all records in the order of appends. A missing file means no history.
+func (l *Ledger) Records() ([]Record, error) {
+	f, err := os.Open(l.path)
+
+	if os.IsNotExist(err) {
+		return []Record{}, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "cannot open %s", l.path)
+	}
+
+	//goland:noinspection GoUnhandledErrorResult
+	defer f.Close()
+
+	records := []Record{}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var record Record
+
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, errors.Wrapf(err, "line %d of %s is broken", line, l.path)
+		}
+
+		records = append(records, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "cannot read %s", l.path)
+	}
+
+	return records, nil
+}
+
+// LastSucceeded returns the latest successful record of the file in the deployment. nil is returned if no record is found.
+func (l *Ledger) LastSucceeded(deployment string, fileName string) (*Record, error) {
+	return l.last(func(r Record) bool {
+		return r.Deployment == deployment && r.FileName == fileName && r.Status == Succeeded
+	})
+}
+
+// LastSucceededOf returns the latest successful record of the deployment whatever the file name is. nil is returned if no record is found.
+func (l *Ledger) LastSucceededOf(deployment string) (*Record, error) {
+	return l.last(func(r Record) bool {
+		return r.Deployment == deployment && r.Status == Succeeded
+	})
+}
+
+func (l *Ledger) last(match func(r Record) bool) (*Record, error) {
+	records, err := l.Records()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for idx := len(records) - 1; idx >= 0; idx-- {
+		if r := records[idx]; match(r) {
+			return &r, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Filter selects records. Zero values match any records.
+type Filter struct {
+	Deployments []string
+	Status      Status
+	Since       time.Time // inclusive
+	Until       time.Time // exclusive
+}
+
+func (f Filter) Match(record Record) bool {
+	if len(f.Deployments) > 0 && !slices.Contains(f.Deployments, record.Deployment) {
+		return false
+	}
+
+	if f.Status != "" && f.Status != record.Status {
+		return false
+	}
+
+	if !f.Since.IsZero() && record.StartedAt.Before(f.Since) {
+		return false
+	}
+
+	if !f.Until.IsZero() && !record.StartedAt.Before(f.Until) {
+		return false
+	}
+
+	return true
+}
+
+// Apply returns the matched records.
+func (f Filter) Apply(records []Record) []Record {
+	matched := []Record{}
+
+	for _, record := range records {
+		if f.Match(record) {
+			matched = append(matched, record)
+		}
+	}
+
+	return matched
+}
+
+// ParseTime accepts a date (2006-01-02) in the local time zone or a RFC3339 timestamp.
+func ParseTime(value string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, errors.New(fmt.Sprintf("%s is neither a date (YYYY-MM-DD) nor a RFC3339 timestamp", value))
+}
@@ -0,0 +1,244 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_Ledger(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "nested", "history")
+	ledger := NewLedger(dir)
+
+	if records, err := ledger.Records(); err != nil {
+		t.Fatalf("a missing file is expected to be no history but %v", err)
+	} else if len(records) != 0 {
+		t.Errorf("no records are expected but %d", len(records))
+	}
+
+	startedAt := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	expected := []Record{
+		{
+			Deployment: "dogfooding",
+			Service:    "deploygate",
+			Status:     Succeeded,
+			FileName:   "app.apk",
+			Size:       1024,
+			SHA256:     "abc",
+			URLs: map[string]string{
+				"download": "https://example.com/app.apk",
+			},
+			StartedAt:  startedAt,
+			FinishedAt: startedAt.Add(time.Minute),
+		},
+		{
+			Deployment: "local",
+			Service:    "local",
+			Status:     Failed,
+			FileName:   "app.apk",
+			Error:      "failure",
+			StartedAt:  startedAt.Add(time.Hour),
+			FinishedAt: startedAt.Add(time.Hour),
+		},
+	}
+
+	for _, r := range expected {
+		if err := ledger.Append(r); err != nil {
+			t.Fatalf("a record is expected to be appended but %v", err)
+		}
+	}
+
+	actual, err := ledger.Records()
+
+	if err != nil {
+		t.Fatalf("records are expected to be read but %v", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("%v is expected but %v", expected, actual)
+	}
+}
+
+func Test_Ledger_Records_broken(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte("{\"deployment\":\"a\"}\n\nbroken\n"), 0644); err != nil {
+		t.Fatalf("failed to prepare a file: %v", err)
+	}
+
+	if _, err := NewLedger(dir).Records(); err == nil {
+		t.Errorf("a broken line is expected to be an error")
+	}
+}
+
+func Test_Filter_Match(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	record := Record{
+		Deployment: "dogfooding",
+		Status:     Succeeded,
+		StartedAt:  base,
+	}
+
+	cases := map[string]struct {
+		filter   Filter
+		expected bool
+	}{
+		"zero": {
+			filter:   Filter{},
+			expected: true,
+		},
+		"deployment matched": {
+			filter: Filter{
+				Deployments: []string{"production", "dogfooding"},
+			},
+			expected: true,
+		},
+		"deployment unmatched": {
+			filter: Filter{
+				Deployments: []string{"production"},
+			},
+			expected: false,
+		},
+		"status unmatched": {
+			filter: Filter{
+				Status: Failed,
+			},
+			expected: false,
+		},
+		"since is inclusive": {
+			filter: Filter{
+				Since: base,
+			},
+			expected: true,
+		},
+		"since unmatched": {
+			filter: Filter{
+				Since: base.Add(time.Second),
+			},
+			expected: false,
+		},
+		"until is exclusive": {
+			filter: Filter{
+				Until: base,
+			},
+			expected: false,
+		},
+		"until matched": {
+			filter: Filter{
+				Until: base.Add(time.Second),
+			},
+			expected: true,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if actual := c.filter.Match(record); actual != c.expected {
+				t.Errorf("%s case is expected to be %t but %t", name, c.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_ParseTime(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		value       string
+		expected    time.Time
+		expectedErr bool
+	}{
+		"date": {
+			value:    "2022-10-01",
+			expected: time.Date(2022, 10, 1, 0, 0, 0, 0, time.Local),
+		},
+		"timestamp": {
+			value:    "2022-10-01T12:00:00Z",
+			expected: time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC),
+		},
+		"invalid": {
+			value:       "yesterday",
+			expectedErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := ParseTime(c.value)
+
+			if c.expectedErr {
+				if err == nil {
+					t.Errorf("%s case is expected to be failure but not", name)
+				}
+
+				return
+			} else if err != nil {
+				t.Fatalf("%s case is expected to be success but %v", name, err)
+			}
+
+			if !c.expected.Equal(actual) {
+				t.Errorf("%v is expected but %v", c.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_Ledger_LastSucceeded(t *testing.T) {
+	t.Parallel()
+
+	ledger := NewLedger(t.TempDir())
+
+	if r, err := ledger.LastSucceeded("dogfooding", "app.apk"); err != nil || r != nil {
+		t.Errorf("no record is expected but %v, %v", r, err)
+	}
+
+	for _, r := range []Record{
+		{Deployment: "dogfooding", FileName: "app.apk", Status: Succeeded, SHA256: "old"},
+		{Deployment: "dogfooding", FileName: "app.apk", Status: Succeeded, SHA256: "new"},
+		{Deployment: "dogfooding", FileName: "app.apk", Status: Failed, SHA256: "failed"},
+		{Deployment: "dogfooding", FileName: "app.aab", Status: Succeeded, SHA256: "other file"},
+		{Deployment: "production", FileName: "app.apk", Status: Succeeded, SHA256: "other deployment"},
+	} {
+		if err := ledger.Append(r); err != nil {
+			t.Fatalf("a record is expected to be appended but %v", err)
+		}
+	}
+
+	r, err := ledger.LastSucceeded("dogfooding", "app.apk")
+
+	if err != nil {
+		t.Fatalf("a record is expected to be found but %v", err)
+	}
+
+	if r == nil || r.SHA256 != "new" {
+		t.Errorf("the latest successful record is expected but %v", r)
+	}
+
+	r, err = ledger.LastSucceededOf("dogfooding")
+
+	if err != nil {
+		t.Fatalf("a record is expected to be found but %v", err)
+	}
+
+	if r == nil || r.SHA256 != "other file" {
+		t.Errorf("the latest successful record of any file is expected but %v", r)
+	}
+}
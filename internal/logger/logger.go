@@ -1,9 +1,9 @@
 package logger
 
 import (
+	"github.com/jmatsu/splitter/internal/secret"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/pkgerrors"
-	"os"
 	"strings"
 )
 
@@ -16,7 +16,8 @@ var Logger zerolog.Logger
 func init() {
 	zerolog.ErrorStackMarshaler = pkgerrors.MarshalStack
 
-	writer := zerolog.ConsoleWriter{Out: os.Stderr}
+	// secrets resolved from the config file must not appear in logs
+	writer := zerolog.ConsoleWriter{Out: secret.NewRedactingWriter(stderr)}
 	writer.FormatTimestamp = func(i interface{}) string {
 		return ""
 	}
@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"fmt"
+	"github.com/jmatsu/splitter/internal/secret"
+	"io"
+	"os"
+	"sync"
+)
+
+// stderr is shared by logs and progress lines so that they are never interleaved.
+var stderr = &terminalWriter{
+	w: os.Stderr,
+}
+
+// terminalWriter serializes writes. A progress line is transient and cleared before any other output.
+type terminalWriter struct {
+	lock      sync.Mutex
+	w         io.Writer
+	transient bool
+}
+
+func (t *terminalWriter) Write(p []byte) (int, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.clear()
+
+	return t.w.Write(p)
+}
+
+func (t *terminalWriter) clear() {
+	if t.transient {
+		_, _ = fmt.Fprint(t.w, "\r\033[K")
+		t.transient = false
+	}
+}
+
+func (t *terminalWriter) writeProgressLine(line string, final bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	// overwrite the current line
+	_, _ = fmt.Fprintf(t.w, "\r\033[K%s", secret.Redact(line))
+
+	if final {
+		_, _ = fmt.Fprintln(t.w)
+	}
+
+	t.transient = !final
+}
+
+// WriteProgressLine overwrites the current line of stderr by the line. The final line is kept, otherwise the line is cleared before logs.
+func WriteProgressLine(line string, final bool) {
+	stderr.writeProgressLine(line, final)
+}
@@ -0,0 +1,28 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_terminalWriter(t *testing.T) {
+	t.Parallel()
+
+	var b bytes.Buffer
+
+	w := &terminalWriter{
+		w: &b,
+	}
+
+	w.writeProgressLine("uploading 10%", false)
+	_, _ = w.Write([]byte("a log line\n"))
+	w.writeProgressLine("uploading 50%", false)
+	w.writeProgressLine("uploading 100%", true)
+	_, _ = w.Write([]byte("another log line\n"))
+
+	expected := "\r\033[Kuploading 10%" + "\r\033[Ka log line\n" + "\r\033[Kuploading 50%" + "\r\033[Kuploading 100%\n" + "another log line\n"
+
+	if b.String() != expected {
+		t.Errorf("%q is expected but %q", expected, b.String())
+	}
+}
@@ -1,7 +1,6 @@
 package net
 
 import (
-	"bytes"
 	"fmt"
 	"github.com/jmatsu/splitter/internal/logger"
 	"io"
@@ -86,54 +85,121 @@ func (f *Form) Set(field ValueField) {
 	}
 }
 
-func (f *Form) Serialize() (string, *bytes.Buffer, error) {
-	var buffer bytes.Buffer
+// Create a multipart body that streams the fields. Files are read when the body is opened, so they are never buffered in memory as a whole.
+func (f *Form) newMultipartBody() (*multipartBody, error) {
+	body := multipartBody{
+		fields:   f.Fields,
+		boundary: multipart.NewWriter(io.Discard).Boundary(),
+	}
+
+	// measure the content length without reading files
+	var counter countingWriter
 
-	w := multipart.NewWriter(&buffer)
-	//goland:noinspection GoUnhandledErrorResult
-	defer w.Close()
+	err := body.writeTo(&counter, func(_ io.Writer, field ValueField) error {
+		if info, err := os.Stat(field.Value); err != nil {
+			return err
+		} else {
+			counter.n += info.Size()
+			return nil
+		}
+	})
 
-	for _, field := range f.Fields {
-		field := field
+	if err != nil {
+		return nil, err
+	}
+
+	body.contentLength = counter.n
+
+	return &body, nil
+}
+
+type multipartBody struct {
+	fields        []ValueField
+	boundary      string
+	contentLength int64
+}
 
-		err := func() error {
-			name, reader, err := field.Open()
+var _ requestBody = &multipartBody{}
+
+func (b *multipartBody) ContentType() string {
+	w := multipart.NewWriter(io.Discard)
+	_ = w.SetBoundary(b.boundary)
+
+	return w.FormDataContentType()
+}
+
+func (b *multipartBody) ContentLength() int64 {
+	return b.contentLength
+}
+
+// Return the names of file fields. An empty string means no file is included.
+func (b *multipartBody) progressName() string {
+	var names []string
+
+	for _, field := range b.fields {
+		if field.Kind == File {
+			names = append(names, filepath.Base(field.Value))
+		}
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// Open starts writing the form to a pipe. The writer stops when the returned reader is closed.
+func (b *multipartBody) Open() (io.ReadCloser, error) {
+	r, w := io.Pipe()
+
+	go func() {
+		err := b.writeTo(w, func(fw io.Writer, field ValueField) error {
+			_, reader, err := field.Open()
 
 			if err != nil {
 				return err
 			}
 
-			if closable, ok := reader.(io.Closer); ok {
-				//goland:noinspection GoUnhandledErrorResult
-				defer closable.Close()
-			}
+			//goland:noinspection GoUnhandledErrorResult
+			defer reader.(io.Closer).Close()
 
-			switch field.Kind {
-			case File:
-				logger.Logger.Debug().Msgf("serialize %s as file in from", name)
-				if fw, err := w.CreateFormFile(name, filepath.Base(field.Value)); err != nil {
-					return err
-				} else if _, err = io.Copy(fw, reader); err != nil {
-					return err
-				}
-			case NonFile:
-				logger.Logger.Debug().Msgf("serialize %s as string in from", name)
-				if fw, err := w.CreateFormField(name); err != nil {
-					return err
-				} else if _, err = io.Copy(fw, reader); err != nil {
-					return err
-				}
-			default:
-				panic("dead case")
-			}
+			_, err = io.Copy(fw, reader)
 
-			return nil
-		}()
+			return err
+		})
+
+		_ = w.CloseWithError(err)
+	}()
+
+	return r, nil
+}
+
+func (b *multipartBody) writeTo(w io.Writer, copyFile func(fw io.Writer, field ValueField) error) error {
+	mw := multipart.NewWriter(w)
 
-		if err != nil {
-			return "", nil, err
+	if err := mw.SetBoundary(b.boundary); err != nil {
+		return err
+	}
+
+	for _, field := range b.fields {
+		switch field.Kind {
+		case File:
+			logger.Logger.Debug().Msgf("serialize %s as file in from", field.FieldName)
+
+			if fw, err := mw.CreateFormFile(field.FieldName, filepath.Base(field.Value)); err != nil {
+				return err
+			} else if err := copyFile(fw, field); err != nil {
+				return err
+			}
+		case NonFile:
+			logger.Logger.Debug().Msgf("serialize %s as string in from", field.FieldName)
+
+			if fw, err := mw.CreateFormField(field.FieldName); err != nil {
+				return err
+			} else if _, err = io.WriteString(fw, field.Value); err != nil {
+				return err
+			}
+		default:
+			panic(fmt.Sprintf("unsupported field kind: %v", field.Kind))
 		}
 	}
 
-	return w.FormDataContentType(), &buffer, nil
+	return mw.Close()
 }
@@ -1,9 +1,14 @@
 package net
 
 import (
+	"bytes"
 	"io"
+	"mime"
+	"mime/multipart"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -128,3 +133,70 @@ func Test_Form_Serialize(t *testing.T) {
 		})
 	}
 }
+
+func Test_Form_newMultipartBody(t *testing.T) {
+	t.Parallel()
+
+	filePath := filepath.Join(t.TempDir(), "app.apk")
+
+	if err := os.WriteFile(filePath, []byte(strings.Repeat("x", 64*1024)), 0644); err != nil {
+		t.Fatalf("failed to create a file: %v", err)
+	}
+
+	form := Form{}
+	form.Set(StringField("message", "hello"))
+	form.Set(FileField("file", filePath))
+	form.Set(BooleanField("flag", true))
+
+	body, err := form.newMultipartBody()
+
+	if err != nil {
+		t.Fatalf("failed to create a body: %v", err)
+	}
+
+	// the body can be read several times
+	for i := 0; i < 2; i++ {
+		b, err := readAll(body)
+
+		if err != nil {
+			t.Fatalf("failed to read the body: %v", err)
+		}
+
+		if int64(len(b)) != body.ContentLength() {
+			t.Errorf("content length is expected to be %d but %d", body.ContentLength(), len(b))
+		}
+
+		_, params, err := mime.ParseMediaType(body.ContentType())
+
+		if err != nil {
+			t.Fatalf("failed to parse the content type: %v", err)
+		}
+
+		parsed, err := multipart.NewReader(bytes.NewReader(b), params["boundary"]).ReadForm(1024)
+
+		if err != nil {
+			t.Fatalf("failed to parse the body: %v", err)
+		}
+
+		if v := parsed.Value["message"]; !reflect.DeepEqual([]string{"hello"}, v) {
+			t.Errorf("message is not expected: %v", v)
+		}
+
+		if v := parsed.Value["flag"]; !reflect.DeepEqual([]string{"true"}, v) {
+			t.Errorf("flag is not expected: %v", v)
+		}
+
+		if v := parsed.File["file"]; len(v) != 1 || v[0].Filename != "app.apk" || v[0].Size != 64*1024 {
+			t.Errorf("file is not expected: %v", v)
+		}
+	}
+}
+
+func Test_Form_newMultipartBody_missingFile(t *testing.T) {
+	form := Form{}
+	form.Set(FileField("file", filepath.Join(t.TempDir(), "missing.apk")))
+
+	if _, err := form.newMultipartBody(); err == nil {
+		t.Errorf("a missing file must be an error")
+	}
+}
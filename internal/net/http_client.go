@@ -3,18 +3,23 @@ package net
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"github.com/jmatsu/splitter/internal"
 	"github.com/jmatsu/splitter/internal/config"
 	"github.com/jmatsu/splitter/internal/logger"
 	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
 	"golang.org/x/exp/maps"
 	"io"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
-	"os"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
 func NewHttpClient(baseUrl string) *HttpClient {
@@ -29,7 +34,10 @@ func NewHttpClient(baseUrl string) *HttpClient {
 		client: &http.Client{
 			Timeout: config.CurrentConfig().NetworkTimeout(),
 		},
-		baseURL: *baseURL,
+		retryPolicy: config.CurrentConfig().RetryPolicy(nil),
+		logger:      logger.Logger,
+		sleep:       sleepContext,
+		baseURL:     *baseURL,
 		headers: http.Header{
 			"User-Agent": {
 				fmt.Sprintf("splitter/%s (build: %s)", internal.Version, internal.Commit),
@@ -42,8 +50,9 @@ func NewHttpClient(baseUrl string) *HttpClient {
 }
 
 type HttpResponse struct {
-	Code  int
-	bytes []byte
+	Code    int
+	bytes   []byte
+	headers http.Header
 }
 
 type TypedHttpResponse interface {
@@ -74,14 +83,23 @@ func (r *HttpResponse) ParseJson(v any) (any, error) {
 	return v, nil
 }
 
+// Header returns the first value of the response header.
+func (r *HttpResponse) Header(name string) string {
+	return r.headers.Get(name)
+}
+
 func (r *HttpResponse) RawJson() string {
 	return string(r.bytes)
 }
 
 type HttpClient struct {
-	client  *http.Client
-	baseURL url.URL
-	headers http.Header
+	client      *http.Client
+	retryPolicy config.RetryPolicy
+	logger      zerolog.Logger
+	sleep       func(ctx context.Context, d time.Duration) error
+	baseURL     url.URL
+	headers     http.Header
+	recorder    *Recorder
 }
 
 func (c *HttpClient) WithHeaders(headers http.Header) *HttpClient {
@@ -96,6 +114,62 @@ func (c *HttpClient) WithHeaders(headers http.Header) *HttpClient {
 	return &newClient
 }
 
+// WithBaseURL returns a client that sends requests to another base URL. Query parameters of the URL are kept.
+func (c *HttpClient) WithBaseURL(baseUrl string) (*HttpClient, error) {
+	baseURL, err := url.ParseRequestURI(baseUrl)
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s is invalid", baseUrl)
+	}
+
+	newClient := c.clone(func(newClient *HttpClient) {
+		newClient.baseURL = *baseURL
+	})
+
+	return &newClient, nil
+}
+
+// WithRecorder returns a client that records requests to the recorder instead of sending them.
+func (c *HttpClient) WithRecorder(recorder *Recorder) *HttpClient {
+	newClient := c.clone(func(newClient *HttpClient) {
+		newClient.recorder = recorder
+	})
+
+	return &newClient
+}
+
+// WithRetry returns a client that follows the retry config. The values of the config take priority over the global retry config.
+func (c *HttpClient) WithRetry(retry *config.RetryConfig) *HttpClient {
+	newClient := c.clone(func(newClient *HttpClient) {
+		newClient.retryPolicy = config.CurrentConfig().RetryPolicy(retry)
+	})
+
+	return &newClient
+}
+
+// WithoutRetry returns a client that sends each request only once. This is for callers that recover by themselves like resumable uploads.
+func (c *HttpClient) WithoutRetry() *HttpClient {
+	newClient := c.clone(func(newClient *HttpClient) {
+		newClient.retryPolicy.MaxAttempts = 1
+	})
+
+	return &newClient
+}
+
+// WithLogger returns a client that logs requests and attempts through the logger.
+func (c *HttpClient) WithLogger(logger zerolog.Logger) *HttpClient {
+	newClient := c.clone(func(newClient *HttpClient) {
+		newClient.logger = logger
+	})
+
+	return &newClient
+}
+
+// IsDryRun returns true if this client does not send any request.
+func (c *HttpClient) IsDryRun() bool {
+	return c.recorder != nil
+}
+
 func (c *HttpClient) setDefaultHeaders(headers http.Header) {
 	if headers == nil {
 		return
@@ -108,9 +182,53 @@ func (c *HttpClient) DoGet(ctx context.Context, paths []string, queries map[stri
 	return c.do(ctx, paths, queries, http.MethodGet, "", nil)
 }
 
+// DoGetSHA256 downloads the absolute URL and returns the SHA-256 of the content without buffering it. An empty string is returned without reading the content if its size differs from the expected size.
+// Neither the headers of this client nor the retry policy are applied because the URL is usually a signed URL of another host.
+func (c *HttpClient) DoGetSHA256(ctx context.Context, rawURL string, expectedSize int64) (string, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+
+	if err != nil {
+		return "", errors.Wrap(err, "failed to build the request")
+	}
+
+	request.Header.Set("User-Agent", c.headers.Get("User-Agent"))
+
+	// the query of a signed URL is a credential
+	c.logger.Debug().Msgf("GET %s://%s%s", request.URL.Scheme, request.URL.Host, request.URL.Path)
+
+	resp, err := c.client.Do(request)
+
+	if err != nil {
+		return "", err
+	}
+
+	//goland:noinspection GoUnhandledErrorResult
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || 300 <= resp.StatusCode {
+		return "", errors.New(fmt.Sprintf("status code = %d", resp.StatusCode))
+	}
+
+	if resp.ContentLength >= 0 && resp.ContentLength != expectedSize {
+		c.logger.Debug().Msgf("the size is %d but %d is expected", resp.ContentLength, expectedSize)
+		return "", nil
+	}
+
+	h := sha256.New()
+
+	if n, err := io.Copy(h, io.LimitReader(resp.Body, expectedSize+1)); err != nil {
+		return "", errors.Wrap(err, "failed to read the content")
+	} else if n != expectedSize {
+		c.logger.Debug().Msgf("the size is not %d", expectedSize)
+		return "", nil
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 func (c *HttpClient) DoPut(ctx context.Context, paths []string, queries map[string][]string, contentType string, requestBody *bytes.Buffer) (*HttpResponse, error) {
 	if requestBody != nil {
-		return c.do(ctx, paths, queries, http.MethodPut, contentType, requestBody)
+		return c.do(ctx, paths, queries, http.MethodPut, contentType, bytesBody(requestBody.Bytes()))
 	} else {
 		return c.do(ctx, paths, queries, http.MethodPut, contentType, nil)
 	}
@@ -118,7 +236,7 @@ func (c *HttpClient) DoPut(ctx context.Context, paths []string, queries map[stri
 
 func (c *HttpClient) DoPatch(ctx context.Context, paths []string, queries map[string][]string, contentType string, requestBody *bytes.Buffer) (*HttpResponse, error) {
 	if requestBody != nil {
-		return c.do(ctx, paths, queries, http.MethodPatch, contentType, requestBody)
+		return c.do(ctx, paths, queries, http.MethodPatch, contentType, bytesBody(requestBody.Bytes()))
 	} else {
 		return c.do(ctx, paths, queries, http.MethodPatch, contentType, nil)
 	}
@@ -126,34 +244,184 @@ func (c *HttpClient) DoPatch(ctx context.Context, paths []string, queries map[st
 
 func (c *HttpClient) DoPost(ctx context.Context, paths []string, queries map[string][]string, contentType string, requestBody *bytes.Buffer) (*HttpResponse, error) {
 	if requestBody != nil {
-		return c.do(ctx, paths, queries, http.MethodPost, contentType, requestBody)
+		return c.do(ctx, paths, queries, http.MethodPost, contentType, bytesBody(requestBody.Bytes()))
 	} else {
 		return c.do(ctx, paths, queries, http.MethodPost, contentType, nil)
 	}
 }
 
 func (c *HttpClient) DoPostFileBody(ctx context.Context, paths []string, queries map[string][]string, filePath string) (*HttpResponse, error) {
-	if f, err := os.Open(filePath); err != nil {
-		return nil, errors.Wrapf(err, "%s is not found", filePath)
-	} else if b, err := io.ReadAll(f); err != nil {
-		return nil, errors.Wrapf(err, "%s cannot be read", filePath)
+	if c.IsDryRun() {
+		return c.record(ctx, paths, queries, http.MethodPost, "application/octet-stream", nil, fmt.Sprintf("<binary of %s>", filePath))
+	}
+
+	if body, err := newFileBody(filePath); err != nil {
+		return nil, err
 	} else {
-		buffer := bytes.NewBuffer(b)
-		return c.DoPost(ctx, paths, queries, "application/octet-stream", buffer)
+		return c.do(ctx, paths, queries, http.MethodPost, "application/octet-stream", body)
+	}
+}
+
+// DoPostFileChunk sends the part of the file from the offset as binary.
+func (c *HttpClient) DoPostFileChunk(ctx context.Context, paths []string, queries map[string][]string, filePath string, offset int64, length int64) (*HttpResponse, error) {
+	if c.IsDryRun() {
+		return c.record(ctx, paths, queries, http.MethodPost, "application/octet-stream", nil, fmt.Sprintf("<binary of %s from %d to %d>", filePath, offset, offset+length))
+	}
+
+	if body, err := newFileChunkBody(filePath, offset, length); err != nil {
+		return nil, err
+	} else {
+		return c.do(ctx, paths, queries, http.MethodPost, "application/octet-stream", body)
 	}
 }
 
 func (c *HttpClient) DoPostMultipartForm(ctx context.Context, paths []string, queries map[string][]string, form *Form) (*HttpResponse, error) {
-	contentType, buffer, err := form.Serialize()
+	if c.IsDryRun() {
+		var fields []RecordedField
+
+		for _, field := range form.Fields {
+			fields = append(fields, RecordedField{
+				Name:   field.FieldName,
+				Value:  field.Value,
+				IsFile: field.Kind == File,
+			})
+		}
+
+		return c.record(ctx, paths, queries, http.MethodPost, "multipart/form-data", fields, "")
+	}
+
+	body, err := form.newMultipartBody()
 
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to serialize the request form")
 	}
 
-	return c.DoPost(ctx, paths, queries, contentType, buffer)
+	return c.do(ctx, paths, queries, http.MethodPost, body.ContentType(), body)
 }
 
-func (c *HttpClient) do(ctx context.Context, paths []string, queries map[string][]string, method string, contentType string, requestBody io.Reader) (*HttpResponse, error) {
+func (c *HttpClient) do(ctx context.Context, paths []string, queries map[string][]string, method string, contentType string, body requestBody) (*HttpResponse, error) {
+	if c.IsDryRun() {
+		var content string
+
+		if body != nil {
+			if b, err := readAll(body); err != nil {
+				return nil, errors.Wrap(err, "failed to read the request body")
+			} else {
+				content = string(b)
+			}
+		}
+
+		return c.record(ctx, paths, queries, method, contentType, nil, content)
+	}
+
+	return c.doWithRetry(ctx, func() (*http.Request, error) {
+		return c.newRequest(ctx, paths, queries, method, contentType, body)
+	})
+}
+
+func readAll(body requestBody) ([]byte, error) {
+	r, err := body.Open()
+
+	if err != nil {
+		return nil, err
+	}
+
+	//goland:noinspection GoUnhandledErrorResult
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+// Send requests until an attempt succeeds or the retry policy gives up. newRequest must return a fresh request for each attempt.
+func (c *HttpClient) doWithRetry(ctx context.Context, newRequest func() (*http.Request, error)) (*HttpResponse, error) {
+	maxAttempts := c.retryPolicy.MaxAttempts
+
+	for attempt := 1; ; attempt++ {
+		request, err := newRequest()
+
+		if err != nil {
+			return nil, err
+		}
+
+		c.logger.Debug().Msgf("attempt %d/%d: %s %s", attempt, maxAttempts, request.Method, request.URL.Redacted())
+
+		// WroteRequest is called on another goroutine
+		var written atomic.Bool
+
+		request = request.WithContext(httptrace.WithClientTrace(request.Context(), &httptrace.ClientTrace{
+			WroteRequest: func(info httptrace.WroteRequestInfo) {
+				written.Store(info.Err == nil)
+			},
+		}))
+
+		response, err := c.send(request)
+
+		if attempt >= maxAttempts || !shouldRetry(ctx, c.retryPolicy, request.Method, written.Load(), response, err) {
+			if err != nil && attempt < maxAttempts && written.Load() && !isIdempotent(request.Method) && ctx.Err() == nil {
+				c.logger.Warn().Msgf("attempt %d/%d failed after the request was sent. %s is not retried because the server may have processed it", attempt, maxAttempts, request.Method)
+			}
+
+			return response, err
+		}
+
+		delay := retryDelay(c.retryPolicy, attempt, response, time.Now())
+
+		if err != nil {
+			c.logger.Warn().Err(err).Msgf("attempt %d/%d failed. retrying in %s", attempt, maxAttempts, delay)
+		} else {
+			c.logger.Warn().Msgf("attempt %d/%d failed with status %d. retrying in %s", attempt, maxAttempts, response.Code, delay)
+		}
+
+		if err := c.sleep(ctx, delay); err != nil {
+			return nil, errors.Wrap(err, "retry has been canceled")
+		}
+	}
+}
+
+func (c *HttpClient) send(request *http.Request) (*HttpResponse, error) {
+	resp, err := c.client.Do(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	//goland:noinspection GoUnhandledErrorResult
+	defer resp.Body.Close()
+
+	if //goland:noinspection GoImportUsedAsName
+	bytes, err := io.ReadAll(resp.Body); err != nil {
+		return nil, err
+	} else {
+		if 200 <= resp.StatusCode && resp.StatusCode < 300 {
+			c.logger.Trace().Msg(string(bytes))
+		}
+
+		return &HttpResponse{
+			Code:    resp.StatusCode,
+			bytes:   bytes,
+			headers: resp.Header,
+		}, nil
+	}
+}
+
+// Record the request that would be sent and return an empty successful response.
+func (c *HttpClient) record(ctx context.Context, paths []string, queries map[string][]string, method string, contentType string, fields []RecordedField, body string) (*HttpResponse, error) {
+	request, err := c.newRequest(ctx, paths, queries, method, contentType, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.recorder.record(request, fields, body)
+
+	return &HttpResponse{
+		Code:  http.StatusOK,
+		bytes: []byte("{}"),
+	}, nil
+}
+
+// Build a new request. The body is opened for each request so that the request can be sent again.
+func (c *HttpClient) newRequest(ctx context.Context, paths []string, queries map[string][]string, method string, contentType string, body requestBody) (*http.Request, error) {
 	if queries == nil {
 		queries = map[string][]string{}
 	}
@@ -177,12 +445,35 @@ func (c *HttpClient) do(ctx context.Context, paths []string, queries map[string]
 
 	logger.Logger.Debug().Msgf("%s %s", method, uri.String())
 
-	request, err := http.NewRequestWithContext(ctx, method, uri.String(), requestBody)
+	request, err := http.NewRequestWithContext(ctx, method, uri.String(), nil)
 
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to build the request")
 	}
 
+	if body != nil {
+		if request.Body, err = body.Open(); err != nil {
+			return nil, errors.Wrap(err, "failed to open the request body")
+		}
+
+		if namer, ok := body.(progressNamer); ok && namer.progressName() != "" {
+			request.Body = c.newProgressReader(request.Body, namer.progressName(), body.ContentLength())
+		}
+
+		request.GetBody = body.Open
+
+		if n := body.ContentLength(); n >= 0 {
+			logger.Logger.Debug().Msgf("Content-Length: %d", n)
+			request.ContentLength = n
+		}
+
+		if request.ContentLength == 0 {
+			// otherwise, the body is regarded as unknown length
+			_ = request.Body.Close()
+			request.Body = http.NoBody
+		}
+	}
+
 	for name, values := range c.headers {
 		var added bool
 
@@ -211,33 +502,13 @@ func (c *HttpClient) do(ctx context.Context, paths []string, queries map[string]
 		}
 	}
 
-	resp, err := c.client.Do(request)
-
-	if err != nil {
-		return nil, err
-	}
-
-	//goland:noinspection GoUnhandledErrorResult
-	defer resp.Body.Close()
-
-	if //goland:noinspection GoImportUsedAsName
-	bytes, err := io.ReadAll(resp.Body); err != nil {
-		return nil, err
-	} else {
-		if 200 <= resp.StatusCode && resp.StatusCode < 300 {
-			logger.Logger.Trace().Msg(string(bytes))
-		}
-
-		return &HttpResponse{
-			Code:  resp.StatusCode,
-			bytes: bytes,
-		}, nil
-	}
+	return request, nil
 }
 
 func (c *HttpClient) clone(mapper func(newClient *HttpClient)) HttpClient {
 	//goland:noinspection SpellCheckingInspection
 	copiee := *c
+	copiee.headers = c.headers.Clone() // headers must not be shared with the original
 	mapper(&copiee)
 	return copiee
 }
@@ -1,9 +1,13 @@
 package net
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"github.com/jmatsu/splitter/internal/config"
+	"github.com/jmatsu/splitter/internal/secret"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -11,7 +15,9 @@ import (
 	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func Test_NewHttpClient(t *testing.T) {
@@ -353,3 +359,360 @@ func Test_HttpResponse_ParseJson(t *testing.T) {
 		t.Fatalf("failed to set a raw response")
 	}
 }
+
+func Test_HttpClient_WithRecorder(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("no request is expected but %s %s", r.Method, r.RequestURI)
+	}))
+
+	defer server.Close()
+
+	recorder := NewRecorder()
+	secret.Register("secret-value")
+
+	client := NewHttpClient(server.URL).WithHeaders(map[string][]string{
+		"Authorization": {"Bearer secret-value"},
+		"X-Custom":      {"secret-value"},
+	}).WithRecorder(recorder)
+
+	if !client.IsDryRun() {
+		t.Fatalf("the client is expected to be dry-run mode")
+	}
+
+	ctx := context.TODO()
+
+	if _, err := client.DoGet(ctx, []string{"path1"}, map[string][]string{"token": {"secret-value"}}); err != nil {
+		t.Fatalf("failed to record: %v", err)
+	}
+
+	if _, err := client.DoPostMultipartForm(ctx, []string{"path2"}, nil, &Form{
+		Fields: []ValueField{
+			StringField("token", "secret-value"),
+			FileField("file", "path/to/file"),
+		},
+	}); err != nil {
+		t.Fatalf("failed to record: %v", err)
+	}
+
+	records := recorder.Records()
+
+	if len(records) != 2 {
+		t.Fatalf("2 records are expected but %d", len(records))
+	}
+
+	for _, record := range records {
+		if bytes, err := json.Marshal(record); err != nil {
+			t.Fatalf("failed to marshal: %v", err)
+		} else if strings.Contains(string(bytes), "secret-value") {
+			t.Errorf("secrets must be masked: %s", string(bytes))
+		}
+	}
+
+	if expected := fmt.Sprintf("%s/path1?token=****", server.URL); records[0].URL != expected {
+		t.Errorf("%s is expected but %s", expected, records[0].URL)
+	}
+
+	if expected := []RecordedField{{Name: "token", Value: "****"}, {Name: "file", Value: "path/to/file", IsFile: true}}; !reflect.DeepEqual(expected, records[1].Fields) {
+		t.Errorf("%v is expected but %v", expected, records[1].Fields)
+	}
+}
+
+func Test_HttpClient_doWithRetry(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		statuses     []int
+		maxAttempts  int
+		withoutRetry bool
+
+		expectedCode     int
+		expectedAttempts int
+	}{
+		"success at first": {
+			statuses:    []int{http.StatusOK},
+			maxAttempts: 3,
+
+			expectedCode:     http.StatusOK,
+			expectedAttempts: 1,
+		},
+		"success after retries": {
+			statuses:    []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			maxAttempts: 3,
+
+			expectedCode:     http.StatusOK,
+			expectedAttempts: 3,
+		},
+		"give up": {
+			statuses:    []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK},
+			maxAttempts: 2,
+
+			expectedCode:     http.StatusServiceUnavailable,
+			expectedAttempts: 2,
+		},
+		"non-retryable": {
+			statuses:    []int{http.StatusBadRequest, http.StatusOK},
+			maxAttempts: 3,
+
+			expectedCode:     http.StatusBadRequest,
+			expectedAttempts: 1,
+		},
+		"without retry": {
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusOK},
+			maxAttempts:  3,
+			withoutRetry: true,
+
+			expectedCode:     http.StatusServiceUnavailable,
+			expectedAttempts: 1,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var attempts int
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if b, err := io.ReadAll(r.Body); err != nil || string(b) != "payload" {
+					t.Errorf("the body is not rewound at %d: %s", attempts, string(b))
+				}
+
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(c.statuses[attempts])
+				attempts++
+			}))
+
+			t.Cleanup(server.Close)
+
+			client := NewHttpClient(server.URL)
+			client.retryPolicy = config.RetryPolicy{
+				MaxAttempts:       c.maxAttempts,
+				BaseDelay:         time.Second,
+				MaxDelay:          time.Second,
+				RetryableStatuses: config.DefaultRetryableStatuses,
+			}
+
+			var delays []time.Duration
+
+			client.sleep = func(ctx context.Context, d time.Duration) error {
+				delays = append(delays, d)
+				return nil
+			}
+
+			if c.withoutRetry {
+				client = client.WithoutRetry()
+			}
+
+			response, err := client.DoPost(context.Background(), nil, nil, "text/plain", bytes.NewBufferString("payload"))
+
+			if err != nil {
+				t.Fatalf("failed to send: %v", err)
+			}
+
+			if response.Code != c.expectedCode {
+				t.Errorf("%d is expected but %d", c.expectedCode, response.Code)
+			}
+
+			if attempts != c.expectedAttempts {
+				t.Errorf("%d attempts are expected but %d", c.expectedAttempts, attempts)
+			}
+
+			if len(delays) != c.expectedAttempts-1 {
+				t.Errorf("%d delays are expected but %v", c.expectedAttempts-1, delays)
+			}
+		})
+	}
+}
+
+func Test_HttpClient_WithHeaders_isolation(t *testing.T) {
+	client := NewHttpClient("https://example.com")
+	_ = client.WithHeaders(http.Header{"Authorization": {"Bearer token"}})
+
+	if v := client.headers.Get("Authorization"); v != "" {
+		t.Errorf("the original client must not be affected but %s", v)
+	}
+}
+
+func Test_HttpClient_DoPostFileBody(t *testing.T) {
+	t.Parallel()
+
+	filePath := filepath.Join(t.TempDir(), "app.aab")
+	content := strings.Repeat("y", 128*1024)
+
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create a file: %v", err)
+	}
+
+	var attempts int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+
+		if r.ContentLength != int64(len(content)) {
+			t.Errorf("content length is expected to be %d but %d", len(content), r.ContentLength)
+		}
+
+		if b, err := io.ReadAll(r.Body); err != nil || string(b) != content {
+			t.Errorf("the body is not the file content at %d", attempts)
+		}
+
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+
+	t.Cleanup(server.Close)
+
+	client := NewHttpClient(server.URL)
+	client.retryPolicy = config.RetryPolicy{
+		MaxAttempts:       2,
+		RetryableStatuses: config.DefaultRetryableStatuses,
+	}
+
+	if response, err := client.DoPostFileBody(context.Background(), nil, nil, filePath); err != nil {
+		t.Fatalf("failed to send: %v", err)
+	} else if response.Code != http.StatusOK {
+		t.Errorf("200 is expected but %d", response.Code)
+	}
+
+	if attempts != 2 {
+		t.Errorf("2 attempts are expected but %d", attempts)
+	}
+}
+
+func Test_HttpClient_DoGetSHA256(t *testing.T) {
+	t.Parallel()
+
+	content := "the binary"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("the headers of the client must not be sent")
+		}
+
+		_, _ = w.Write([]byte(content))
+	}))
+
+	t.Cleanup(server.Close)
+
+	client := NewHttpClient("https://example.com").WithHeaders(http.Header{"Authorization": {"Bearer token"}})
+
+	cases := map[string]struct {
+		expectedSize int64
+
+		expected string
+	}{
+		"same size": {
+			expectedSize: int64(len(content)),
+			expected:     fmt.Sprintf("%x", sha256.Sum256([]byte(content))),
+		},
+		"different size": {
+			expectedSize: int64(len(content)) + 1,
+			expected:     "",
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if v, err := client.DoGetSHA256(context.Background(), server.URL+"/app.apk?signature=xxx", c.expectedSize); err != nil {
+				t.Errorf("failed to download: %v", err)
+			} else if v != c.expected {
+				t.Errorf("%s is expected but %s", c.expected, v)
+			}
+		})
+	}
+}
+
+func Test_HttpClient_doWithRetry_connectionLost(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		method string
+
+		expectedErr      bool
+		expectedAttempts int
+	}{
+		"non-idempotent": {
+			method:           http.MethodPost,
+			expectedErr:      true,
+			expectedAttempts: 1,
+		},
+		"idempotent": {
+			method:           http.MethodPut,
+			expectedErr:      false,
+			expectedAttempts: 2,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var mu sync.Mutex
+			var attempts int
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				_, _ = io.ReadAll(r.Body)
+				attempts++
+
+				if attempts == 1 {
+					// the request has been received but the response is lost
+					conn, _, err := w.(http.Hijacker).Hijack()
+
+					if err != nil {
+						t.Fatalf("failed to hijack: %v", err)
+					}
+
+					_ = conn.Close()
+					return
+				}
+
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			t.Cleanup(server.Close)
+
+			client := NewHttpClient(server.URL)
+			client.retryPolicy = config.RetryPolicy{
+				MaxAttempts:       3,
+				RetryableStatuses: config.DefaultRetryableStatuses,
+			}
+
+			var err error
+
+			if c.method == http.MethodPost {
+				_, err = client.DoPost(context.Background(), nil, nil, "text/plain", bytes.NewBufferString("payload"))
+			} else {
+				_, err = client.DoPut(context.Background(), nil, nil, "text/plain", bytes.NewBufferString("payload"))
+			}
+
+			if c.expectedErr && err == nil {
+				t.Errorf("the request is expected to fail")
+			} else if !c.expectedErr && err != nil {
+				t.Errorf("the request is expected to succeed but %v", err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if attempts != c.expectedAttempts {
+				t.Errorf("%d attempts are expected but %d", c.expectedAttempts, attempts)
+			}
+		})
+	}
+}
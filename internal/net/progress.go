@@ -0,0 +1,165 @@
+package net
+
+import (
+	"fmt"
+	"github.com/jmatsu/splitter/internal/logger"
+	"github.com/jmatsu/splitter/internal/util"
+	"github.com/mattn/go-isatty"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	terminalProgressInterval = 200 * time.Millisecond
+	logProgressInterval      = 10 * time.Second
+)
+
+var isTerminal = isatty.IsTerminal(os.Stderr.Fd()) || isatty.IsCygwinTerminal(os.Stderr.Fd())
+
+// activeProgressReaders is the number of uploads in progress. Only a single upload can overwrite the line of the terminal.
+var activeProgressReaders struct {
+	lock  sync.Mutex
+	count int
+}
+
+// progressNamer is implemented by request bodies that are worth reporting the progress.
+type progressNamer interface {
+	progressName() string
+}
+
+// progressReader reports how many bytes have been read from the reader. The report is throttled by the interval except the last one.
+type progressReader struct {
+	reader io.ReadCloser
+	name   string
+	total  int64
+
+	sent       int64
+	startedAt  time.Time
+	reportedAt time.Time
+	finished   bool
+
+	interval time.Duration
+	now      func() time.Time
+	report   func(line string, final bool)
+	release  func() // called once the reader is finished or closed
+}
+
+func (c *HttpClient) newProgressReader(reader io.ReadCloser, name string, total int64) *progressReader {
+	r := progressReader{
+		reader: reader,
+		name:   name,
+		total:  total,
+		now:    time.Now,
+	}
+
+	activeProgressReaders.lock.Lock()
+	activeProgressReaders.count++
+	activeProgressReaders.lock.Unlock()
+
+	var once sync.Once
+
+	r.release = func() {
+		once.Do(func() {
+			activeProgressReaders.lock.Lock()
+			activeProgressReaders.count--
+			activeProgressReaders.lock.Unlock()
+		})
+	}
+
+	var loggedAt time.Time
+
+	logLine := func(line string, final bool) {
+		if now := r.now(); final || now.Sub(loggedAt) >= logProgressInterval {
+			loggedAt = now
+			c.logger.Info().Msg(line)
+		}
+	}
+
+	if isTerminal {
+		r.interval = terminalProgressInterval
+		r.report = func(line string, final bool) {
+			activeProgressReaders.lock.Lock()
+			concurrent := activeProgressReaders.count > 1
+			activeProgressReaders.lock.Unlock()
+
+			if concurrent {
+				// several uploads would overwrite each other's line
+				logLine(line, final)
+			} else {
+				logger.WriteProgressLine(line, final)
+			}
+		}
+	} else {
+		r.interval = logProgressInterval
+		r.report = logLine
+	}
+
+	r.startedAt = r.now()
+	r.reportedAt = r.startedAt
+	loggedAt = r.startedAt
+
+	return &r
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.sent += int64(n)
+
+	now := r.now()
+
+	if err == io.EOF {
+		if !r.finished {
+			r.finished = true
+			r.report(r.line(now, true), true)
+			r.done()
+		}
+	} else if now.Sub(r.reportedAt) >= r.interval {
+		r.reportedAt = now
+		r.report(r.line(now, false), false)
+	}
+
+	return n, err
+}
+
+func (r *progressReader) Close() error {
+	r.done()
+	return r.reader.Close()
+}
+
+func (r *progressReader) done() {
+	if r.release != nil {
+		r.release()
+	}
+}
+
+func (r *progressReader) line(now time.Time, final bool) string {
+	elapsed := now.Sub(r.startedAt)
+
+	var throughput float64
+
+	if elapsed > 0 {
+		throughput = float64(r.sent) / elapsed.Seconds()
+	}
+
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("uploading %s: %s", r.name, util.FormatByteSize(float64(r.sent))))
+
+	if r.total > 0 {
+		b.WriteString(fmt.Sprintf(" / %s (%.1f%%)", util.FormatByteSize(float64(r.total)), float64(r.sent)*100/float64(r.total)))
+	}
+
+	b.WriteString(fmt.Sprintf(", %s/s", util.FormatByteSize(throughput)))
+
+	if final {
+		b.WriteString(fmt.Sprintf(", done in %s", elapsed.Round(time.Millisecond)))
+	} else if r.total > 0 && r.sent < r.total && throughput > 0 {
+		eta := time.Duration(float64(r.total-r.sent) / throughput * float64(time.Second))
+		b.WriteString(fmt.Sprintf(", ETA %s", eta.Round(time.Second)))
+	}
+
+	return b.String()
+}
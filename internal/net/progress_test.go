@@ -0,0 +1,98 @@
+package net
+
+import (
+	"bytes"
+	"github.com/rs/zerolog"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_progressReader(t *testing.T) {
+	t.Parallel()
+
+	startedAt := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := startedAt
+
+	var lines []string
+	var finals int
+
+	r := progressReader{
+		reader:     io.NopCloser(strings.NewReader(strings.Repeat("x", 4*1024*1024))),
+		name:       "app.aab",
+		total:      4 * 1024 * 1024,
+		startedAt:  startedAt,
+		reportedAt: startedAt,
+		interval:   10 * time.Second,
+		now: func() time.Time {
+			// each read takes 4 seconds
+			now = now.Add(4 * time.Second)
+			return now
+		},
+		report: func(line string, final bool) {
+			lines = append(lines, line)
+
+			if final {
+				finals++
+			}
+		},
+	}
+
+	buf := make([]byte, 1024*1024)
+
+	for {
+		if _, err := r.Read(buf); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("failed to read: %v", err)
+		}
+	}
+
+	// throttled: 12s and the final one at 20s
+	if len(lines) != 2 {
+		t.Fatalf("2 lines are expected but %d: %v", len(lines), lines)
+	}
+
+	if expected := "uploading app.aab: 3.0 MiB / 4.0 MiB (75.0%), 256.0 KiB/s, ETA 4s"; lines[0] != expected {
+		t.Errorf("%s is expected but %s", expected, lines[0])
+	}
+
+	if finals != 1 || !strings.Contains(lines[1], "4.0 MiB / 4.0 MiB (100.0%)") || !strings.Contains(lines[1], "done in 20s") {
+		t.Errorf("the final line is not expected: %s", lines[1])
+	}
+}
+
+func Test_progressReader_concurrent(t *testing.T) {
+	terminal := isTerminal
+	isTerminal = true
+
+	t.Cleanup(func() {
+		isTerminal = terminal
+	})
+
+	var b bytes.Buffer
+
+	client := &HttpClient{
+		logger: zerolog.New(&b),
+	}
+
+	r1 := client.newProgressReader(io.NopCloser(strings.NewReader("first")), "app1.apk", 5)
+	r2 := client.newProgressReader(io.NopCloser(strings.NewReader("second")), "app2.apk", 6)
+
+	if _, err := io.ReadAll(r1); err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+
+	_ = r1.Close()
+	_ = r2.Close()
+
+	// the terminal line is shared so the progress must be logged instead
+	if !strings.Contains(b.String(), "uploading app1.apk") {
+		t.Errorf("the final progress is expected to be logged but %s", b.String())
+	}
+
+	if activeProgressReaders.count != 0 {
+		t.Errorf("all readers are expected to be released but %d", activeProgressReaders.count)
+	}
+}
@@ -0,0 +1,75 @@
+package net
+
+import (
+	"github.com/jmatsu/splitter/internal/secret"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// RequestRecord is a snapshot of a request that would have been sent.
+type RequestRecord struct {
+	Method  string              `json:"method"`
+	URL     string              `json:"url"`
+	Headers map[string][]string `json:"headers"`
+	Fields  []RecordedField     `json:"form_fields,omitempty"`
+	Body    string              `json:"body,omitempty"`
+}
+
+// RecordedField is a form field of a recorded request. The value of a file field is its file path.
+type RecordedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	IsFile bool   `json:"is_file"`
+}
+
+// Recorder collects requests instead of sending them. Registered secrets are masked in all records. See secret.Register.
+type Recorder struct {
+	lock    sync.Mutex
+	records []RequestRecord
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+func (r *Recorder) Records() []RequestRecord {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return append([]RequestRecord{}, r.records...)
+}
+
+func (r *Recorder) record(request *http.Request, fields []RecordedField, body string) {
+	record := RequestRecord{
+		Method:  request.Method,
+		URL:     secret.Redact(request.URL.String()),
+		Headers: map[string][]string{},
+		Body:    secret.Redact(body),
+	}
+
+	for name, values := range request.Header {
+		for _, value := range values {
+			if name == "Authorization" {
+				// never expose credentials even if they are not registered as secrets
+				if scheme, _, found := strings.Cut(value, " "); found {
+					value = scheme + " " + secret.Mask
+				} else {
+					value = secret.Mask
+				}
+			}
+
+			record.Headers[name] = append(record.Headers[name], secret.Redact(value))
+		}
+	}
+
+	for _, field := range fields {
+		field.Value = secret.Redact(field.Value)
+		record.Fields = append(record.Fields, field)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.records = append(r.records, record)
+}
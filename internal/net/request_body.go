@@ -0,0 +1,128 @@
+package net
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/jmatsu/splitter/internal/util"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// requestBody provides the same content as a fresh reader for each attempt so that the content is never buffered in memory as a whole.
+type requestBody interface {
+	// Open returns a new reader from the beginning of the content.
+	Open() (io.ReadCloser, error)
+
+	// ContentLength returns the size of the content. -1 means unknown.
+	ContentLength() int64
+}
+
+type bytesBody []byte
+
+var _ requestBody = bytesBody{}
+
+func (b bytesBody) Open() (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+
+func (b bytesBody) ContentLength() int64 {
+	return int64(len(b))
+}
+
+type fileBody struct {
+	path string
+	size int64
+}
+
+var _ requestBody = &fileBody{}
+
+func newFileBody(path string) (*fileBody, error) {
+	if info, err := os.Stat(path); err != nil {
+		return nil, errors.Wrapf(err, "%s is not found", path)
+	} else if info.IsDir() {
+		return nil, errors.New(fmt.Sprintf("%s is a directory", path))
+	} else {
+		return &fileBody{
+			path: path,
+			size: info.Size(),
+		}, nil
+	}
+}
+
+func (b *fileBody) Open() (io.ReadCloser, error) {
+	if f, err := os.Open(b.path); err != nil {
+		return nil, errors.Wrapf(err, "%s cannot be read", b.path)
+	} else {
+		return f, nil
+	}
+}
+
+func (b *fileBody) ContentLength() int64 {
+	return b.size
+}
+
+func (b *fileBody) progressName() string {
+	return filepath.Base(b.path)
+}
+
+type fileChunkBody struct {
+	path   string
+	offset int64
+	length int64
+}
+
+var _ requestBody = &fileChunkBody{}
+
+func newFileChunkBody(path string, offset int64, length int64) (*fileChunkBody, error) {
+	if body, err := newFileBody(path); err != nil {
+		return nil, err
+	} else if offset < 0 || length < 0 || body.size < offset+length {
+		return nil, errors.New(fmt.Sprintf("%d bytes from %d are out of %s (%d bytes)", length, offset, path, body.size))
+	}
+
+	return &fileChunkBody{
+		path:   path,
+		offset: offset,
+		length: length,
+	}, nil
+}
+
+func (b *fileChunkBody) Open() (io.ReadCloser, error) {
+	f, err := os.Open(b.path)
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s cannot be read", b.path)
+	}
+
+	if _, err := f.Seek(b.offset, io.SeekStart); err != nil {
+		_ = f.Close()
+		return nil, errors.Wrapf(err, "%s cannot be read from %d", b.path, b.offset)
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: io.LimitReader(f, b.length),
+		Closer: f,
+	}, nil
+}
+
+func (b *fileChunkBody) ContentLength() int64 {
+	return b.length
+}
+
+func (b *fileChunkBody) progressName() string {
+	return fmt.Sprintf("%s (from %s)", filepath.Base(b.path), util.FormatByteSize(float64(b.offset)))
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
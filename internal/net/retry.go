@@ -0,0 +1,96 @@
+package net
+
+import (
+	"context"
+	"github.com/jmatsu/splitter/internal/config"
+	"golang.org/x/exp/slices"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Check if the attempt should be retried. The retryable statuses are retried unless the context is done.
+// Connection errors are retried if the request has not been written yet. Otherwise, only idempotent requests are retried because the server may have processed the request, e.g. an upload.
+func shouldRetry(ctx context.Context, policy config.RetryPolicy, method string, written bool, response *HttpResponse, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		return !written || isIdempotent(method)
+	}
+
+	return slices.Contains(policy.RetryableStatuses, response.Code)
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// Calculate the delay before the next attempt. The exponential backoff never exceeds the max delay.
+// Retry-After of 429 and 503 is used as given instead, but it is capped by the max delay as well so that the remaining attempts are used.
+func retryDelay(policy config.RetryPolicy, attempt int, response *HttpResponse, now time.Time) time.Duration {
+	if response != nil && (response.Code == http.StatusTooManyRequests || response.Code == http.StatusServiceUnavailable) {
+		if v, ok := parseRetryAfter(response.headers.Get("Retry-After"), now); ok {
+			if v > policy.MaxDelay {
+				return policy.MaxDelay
+			}
+
+			return v
+		}
+	}
+
+	delay := policy.BaseDelay
+
+	for i := 1; i < attempt && delay < policy.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > policy.MaxDelay {
+		delay = policy.MaxDelay
+	}
+
+	return delay
+}
+
+// Retry-After is either delay-seconds or HTTP-date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+
+		return 0, true
+	}
+
+	return 0, false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,177 @@
+package net
+
+import (
+	"context"
+	"github.com/jmatsu/splitter/internal/config"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func Test_retryDelay(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	policy := config.RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   time.Second,
+		MaxDelay:    10 * time.Second,
+	}
+
+	cases := map[string]struct {
+		attempt  int
+		response *HttpResponse
+
+		expected time.Duration
+	}{
+		"first": {
+			attempt:  1,
+			expected: time.Second,
+		},
+		"exponential": {
+			attempt:  3,
+			expected: 4 * time.Second,
+		},
+		"capped": {
+			attempt:  10,
+			expected: 10 * time.Second,
+		},
+		"retry-after seconds": {
+			attempt: 1,
+			response: &HttpResponse{
+				Code:    http.StatusTooManyRequests,
+				headers: http.Header{"Retry-After": {"3"}},
+			},
+			expected: 3 * time.Second,
+		},
+		"retry-after date": {
+			attempt: 1,
+			response: &HttpResponse{
+				Code:    http.StatusServiceUnavailable,
+				headers: http.Header{"Retry-After": {now.Add(5 * time.Second).Format(http.TimeFormat)}},
+			},
+			expected: 5 * time.Second,
+		},
+		"retry-after over max delay": {
+			attempt: 1,
+			response: &HttpResponse{
+				Code:    http.StatusServiceUnavailable,
+				headers: http.Header{"Retry-After": {"120"}},
+			},
+			expected: 10 * time.Second,
+		},
+		"retry-after over the backoff": {
+			attempt: 1,
+			response: &HttpResponse{
+				Code:    http.StatusTooManyRequests,
+				headers: http.Header{"Retry-After": {"10"}},
+			},
+			expected: 10 * time.Second,
+		},
+		"retry-after of other statuses": {
+			attempt: 2,
+			response: &HttpResponse{
+				Code:    http.StatusBadGateway,
+				headers: http.Header{"Retry-After": {"5"}},
+			},
+			expected: 2 * time.Second,
+		},
+		"invalid retry-after": {
+			attempt: 1,
+			response: &HttpResponse{
+				Code:    http.StatusTooManyRequests,
+				headers: http.Header{"Retry-After": {"soon"}},
+			},
+			expected: time.Second,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if actual := retryDelay(policy, c.attempt, c.response, now); actual != c.expected {
+				t.Errorf("%s is expected but %s", c.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_shouldRetry(t *testing.T) {
+	t.Parallel()
+
+	policy := config.RetryPolicy{
+		RetryableStatuses: []int{http.StatusServiceUnavailable},
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cases := map[string]struct {
+		ctx      context.Context
+		method   string
+		written  bool
+		response *HttpResponse
+		err      error
+
+		expected bool
+	}{
+		"retryable status": {
+			ctx:      context.Background(),
+			response: &HttpResponse{Code: http.StatusServiceUnavailable},
+			expected: true,
+		},
+		"non-retryable status": {
+			ctx:      context.Background(),
+			response: &HttpResponse{Code: http.StatusBadRequest},
+			expected: false,
+		},
+		"connection error before writing": {
+			ctx:      context.Background(),
+			method:   http.MethodPost,
+			err:      context.DeadlineExceeded,
+			expected: true,
+		},
+		"connection error after writing a non-idempotent request": {
+			ctx:      context.Background(),
+			method:   http.MethodPost,
+			written:  true,
+			err:      context.DeadlineExceeded,
+			expected: false,
+		},
+		"connection error after writing an idempotent request": {
+			ctx:      context.Background(),
+			method:   http.MethodPut,
+			written:  true,
+			err:      context.DeadlineExceeded,
+			expected: true,
+		},
+		"retryable status of a non-idempotent request": {
+			ctx:      context.Background(),
+			method:   http.MethodPost,
+			written:  true,
+			response: &HttpResponse{Code: http.StatusServiceUnavailable},
+			expected: true,
+		},
+		"canceled": {
+			ctx:      canceled,
+			err:      context.Canceled,
+			expected: false,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if actual := shouldRetry(c.ctx, policy, c.method, c.written, c.response, c.err); actual != c.expected {
+				t.Errorf("%t is expected but %t", c.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package secret
+
+import (
+	"golang.org/x/exp/slices"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	Mask      = "****" // the replacement of secrets
+	minLength = 6      // shorter values are never secrets. Masking them would break unrelated text.
+)
+
+var (
+	lock       sync.RWMutex
+	registered []string
+)
+
+// Register marks the values as secrets so that Redact masks them. Empty or too short values are ignored.
+func Register(values ...string) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	for _, value := range values {
+		if len(value) < minLength || slices.Contains(registered, value) {
+			continue
+		}
+
+		registered = append(registered, value)
+	}
+
+	// longer secrets first so that a secret containing another secret is masked entirely
+	slices.SortStableFunc(registered, func(a, b string) int {
+		return len(b) - len(a)
+	})
+}
+
+// IsRegistered returns true if the value is marked as a secret.
+func IsRegistered(value string) bool {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	return slices.Contains(registered, value)
+}
+
+// Redact masks every registered secret in the text.
+func Redact(text string) string {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	return MaskValues(text, registered...)
+}
+
+// MaskValues replaces all occurrences of the secrets in the text. URL-encoded forms of the secrets are also replaced.
+func MaskValues(text string, secrets ...string) string {
+	secrets = slices.Clone(secrets)
+
+	slices.SortStableFunc(secrets, func(a, b string) int {
+		return len(b) - len(a)
+	})
+
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+
+		text = strings.ReplaceAll(text, secret, Mask)
+
+		if escaped := url.QueryEscape(secret); escaped != secret {
+			text = strings.ReplaceAll(text, escaped, Mask)
+		}
+	}
+
+	return text
+}
+
+// NewRedactingWriter returns a writer that masks registered secrets before writing to w.
+// Each write must contain whole secrets like log lines.
+func NewRedactingWriter(w io.Writer) io.Writer {
+	return &redactingWriter{
+		w: w,
+	}
+}
+
+type redactingWriter struct {
+	w io.Writer
+}
+
+func (r *redactingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(r.w, Redact(string(p))); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
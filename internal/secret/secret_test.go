@@ -0,0 +1,87 @@
+package secret
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_Redact(t *testing.T) {
+	Register("")
+	Register("main")
+	Register("short-secret")
+	Register("short-secret-but-longer")
+	Register("secret/with/slash")
+
+	cases := map[string]struct {
+		text     string
+		expected string
+	}{
+		"no secret": {
+			text:     "nothing to hide",
+			expected: "nothing to hide",
+		},
+		"too short value": {
+			text:     "branch=main",
+			expected: "branch=main",
+		},
+		"secret": {
+			text:     "token=short-secret",
+			expected: "token=" + Mask,
+		},
+		"url-encoded secret": {
+			text:     "token=secret%2Fwith%2Fslash",
+			expected: "token=" + Mask,
+		},
+		"longer secret that contains another": {
+			text:     "token=short-secret-but-longer;",
+			expected: "token=" + Mask + ";",
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+		t.Run(name, func(t *testing.T) {
+			if actual := Redact(c.text); actual != c.expected {
+				t.Errorf("%s case is expected to be %s but %s", name, c.expected, actual)
+			}
+
+			var b bytes.Buffer
+
+			if _, err := NewRedactingWriter(&b).Write([]byte(c.text)); err != nil {
+				t.Errorf("%s case is expected to be written but %v", name, err)
+			} else if b.String() != c.expected {
+				t.Errorf("%s case is expected to write %s but %s", name, c.expected, b.String())
+			}
+		})
+	}
+
+	if IsRegistered("") {
+		t.Errorf("empty values must not be secrets")
+	}
+}
+
+func Test_MaskValues(t *testing.T) {
+	cases := map[string]struct {
+		value   string
+		secrets []string
+
+		expected string
+	}{
+		"no secrets":       {value: "Bearer token", secrets: nil, expected: "Bearer token"},
+		"single secret":    {value: "Bearer token", secrets: []string{"token"}, expected: "Bearer " + Mask},
+		"multiple secrets": {value: "user:pass@token", secrets: []string{"pass", "token"}, expected: "user:" + Mask + "@" + Mask},
+		"escaped secret":   {value: "https://example.com?token=a%2Fb", secrets: []string{"a/b"}, expected: "https://example.com?token=" + Mask},
+		"empty secret":     {value: "Bearer token", secrets: []string{""}, expected: "Bearer token"},
+		"empty":            {value: "", secrets: []string{"token"}, expected: ""},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			if actual := MaskValues(c.value, c.secrets...); actual != c.expected {
+				t.Fatalf("%s is expected but %s", c.expected, actual)
+			}
+		})
+	}
+}
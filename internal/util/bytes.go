@@ -0,0 +1,71 @@
+package util
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var byteUnits = []struct {
+	suffix string
+	size   int64
+}{
+	// longer suffixes first
+	{"KiB", 1 << 10},
+	{"MiB", 1 << 20},
+	{"GiB", 1 << 30},
+	{"KB", 1000},
+	{"MB", 1000 * 1000},
+	{"GB", 1000 * 1000 * 1000},
+	{"B", 1},
+}
+
+// ParseByteSize parses a size like 8MiB, 500KB or 1024. A number without any unit means bytes.
+func ParseByteSize(value string) (int64, error) {
+	v := strings.TrimSpace(value)
+	unit := int64(1)
+
+	for _, u := range byteUnits {
+		if strings.HasSuffix(v, u.suffix) {
+			v = strings.TrimSpace(strings.TrimSuffix(v, u.suffix))
+			unit = u.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(v, 10, 64)
+
+	if err != nil {
+		return 0, errors.Wrapf(err, "%s is not a valid size", value)
+	}
+
+	if n < 0 {
+		return 0, errors.New(fmt.Sprintf("%s must be positive", value))
+	}
+
+	if n > math.MaxInt64/unit {
+		return 0, errors.New(fmt.Sprintf("%s is too large", value))
+	}
+
+	return n * unit, nil
+}
+
+// FormatByteSize formats the size in binary units like 1.5 MiB. The size can be fractional like throughput.
+func FormatByteSize(n float64) string {
+	units := []string{"B", "KiB", "MiB", "GiB"}
+
+	i := 0
+
+	for n >= 1024 && i < len(units)-1 {
+		n /= 1024
+		i++
+	}
+
+	if i == 0 {
+		return fmt.Sprintf("%.0f %s", n, units[i])
+	}
+
+	return fmt.Sprintf("%.1f %s", n, units[i])
+}
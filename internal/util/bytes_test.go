@@ -0,0 +1,69 @@
+package util
+
+import (
+	"math"
+	"testing"
+)
+
+func Test_ParseByteSize(t *testing.T) {
+	cases := map[string]struct {
+		value string
+
+		expected    int64
+		expectedErr bool
+	}{
+		"bytes":    {value: "1024", expected: 1024},
+		"bytes B":  {value: "1024B", expected: 1024},
+		"KiB":      {value: "256KiB", expected: 256 * 1024},
+		"MiB":      {value: "8 MiB", expected: 8 * 1024 * 1024},
+		"GiB":      {value: "1GiB", expected: 1024 * 1024 * 1024},
+		"MB":       {value: "5MB", expected: 5 * 1000 * 1000},
+		"decimal":  {value: "1.5MiB", expectedErr: true},
+		"negative": {value: "-1", expectedErr: true},
+		"unknown":  {value: "1TB", expectedErr: true},
+		"empty":    {value: "", expectedErr: true},
+		"overflow": {value: "99999999999GiB", expectedErr: true},
+		"max":      {value: "9223372036854775807", expected: math.MaxInt64},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			actual, err := ParseByteSize(c.value)
+
+			if c.expectedErr {
+				if err == nil {
+					t.Errorf("%s case is expected to be failure but not: %d", name, actual)
+				}
+
+				return
+			} else if err != nil {
+				t.Fatalf("%s case is expected to be success but not: %v", name, err)
+			}
+
+			if actual != c.expected {
+				t.Errorf("%d is expected but %d", c.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_FormatByteSize(t *testing.T) {
+	t.Parallel()
+
+	cases := map[float64]string{
+		0:                      "0 B",
+		1023:                   "1023 B",
+		1024:                   "1.0 KiB",
+		1536:                   "1.5 KiB",
+		300 * 1024 * 1024:      "300.0 MiB",
+		3 * 1024 * 1024 * 1024: "3.0 GiB",
+	}
+
+	for n, expected := range cases {
+		if actual := FormatByteSize(n); actual != expected {
+			t.Errorf("%s is expected but %s", expected, actual)
+		}
+	}
+}
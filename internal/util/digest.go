@@ -0,0 +1,85 @@
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileDigest is the fingerprint of a file.
+type FileDigest struct {
+	Size   int64
+	SHA256 string // hex-encoded
+}
+
+type cachedDigest struct {
+	FileDigest
+	modTime time.Time
+}
+
+var digests = struct {
+	lock   sync.Mutex
+	values map[string]cachedDigest
+}{
+	values: map[string]cachedDigest{},
+}
+
+// DigestFile computes the fingerprint of the file. The file is read as a stream.
+// The result is cached while the size and the modification time of the file are unchanged so that the same file is hashed only once.
+func DigestFile(filePath string) (*FileDigest, error) {
+	path, err := filepath.Abs(filePath)
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot resolve %s", filePath)
+	}
+
+	info, err := os.Stat(path)
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s is not found", filePath)
+	}
+
+	digests.lock.Lock()
+	cached, ok := digests.values[path]
+	digests.lock.Unlock()
+
+	if ok && cached.Size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		return &cached.FileDigest, nil
+	}
+
+	f, err := os.Open(path)
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s is not found", filePath)
+	}
+
+	//goland:noinspection GoUnhandledErrorResult
+	defer f.Close()
+
+	h := sha256.New()
+
+	size, err := io.Copy(h, f)
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s cannot be read", filePath)
+	}
+
+	digest := FileDigest{
+		Size:   size,
+		SHA256: hex.EncodeToString(h.Sum(nil)),
+	}
+
+	digests.lock.Lock()
+	digests.values[path] = cachedDigest{
+		FileDigest: digest,
+		modTime:    info.ModTime(),
+	}
+	digests.lock.Unlock()
+
+	return &digest, nil
+}
@@ -0,0 +1,36 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_DigestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.apk")
+
+	if err := os.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatalf("failed to create a file: %v", err)
+	}
+
+	if digest, err := DigestFile(path); err != nil {
+		t.Fatalf("failed to digest: %v", err)
+	} else if digest.Size != 5 || digest.SHA256 != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" {
+		t.Errorf("the digest is not expected: %v", digest)
+	}
+
+	// a modified file must be hashed again
+	if err := os.WriteFile(path, []byte("hello, world"), 0644); err != nil {
+		t.Fatalf("failed to modify the file: %v", err)
+	}
+
+	if digest, err := DigestFile(path); err != nil {
+		t.Fatalf("failed to digest: %v", err)
+	} else if digest.Size != 12 || digest.SHA256 != "09ca7e4eaa6e8ae9c7d261167129184883644d07dfba7cbfbc4c8a2e08360d5b" {
+		t.Errorf("the digest is not expected: %v", digest)
+	}
+
+	if _, err := DigestFile(filepath.Join(t.TempDir(), "missing.apk")); err == nil {
+		t.Errorf("a missing file is expected to fail")
+	}
+}
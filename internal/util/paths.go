@@ -0,0 +1,49 @@
+package util
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+	"path/filepath"
+)
+
+// ExpandPaths expands glob patterns into file paths. A pattern without any match is an error, but a plain path is kept as it is.
+// The result keeps the order of the patterns and does not contain duplicates.
+func ExpandPaths(patterns []string) ([]string, error) {
+	var paths []string
+
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s is not a valid pattern", pattern)
+		}
+
+		if len(matches) == 0 {
+			if isGlob(pattern) {
+				return nil, errors.New(fmt.Sprintf("no file matches %s", pattern))
+			}
+
+			matches = []string{pattern}
+		}
+
+		for _, match := range matches {
+			if !slices.Contains(paths, match) {
+				paths = append(paths, match)
+			}
+		}
+	}
+
+	return paths, nil
+}
+
+func isGlob(pattern string) bool {
+	for _, r := range pattern {
+		switch r {
+		case '*', '?', '[', '\\':
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,68 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_ExpandPaths(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{"app-arm64-v8a.apk", "app-x86_64.apk", "app.aab"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("failed to create a file: %v", err)
+		}
+	}
+
+	cases := map[string]struct {
+		patterns []string
+
+		expected    []string
+		expectedErr bool
+	}{
+		"plain path": {
+			patterns: []string{filepath.Join(dir, "app.aab")},
+			expected: []string{filepath.Join(dir, "app.aab")},
+		},
+		"missing plain path": {
+			patterns: []string{filepath.Join(dir, "missing.aab")},
+			expected: []string{filepath.Join(dir, "missing.aab")},
+		},
+		"glob": {
+			patterns: []string{filepath.Join(dir, "*.apk")},
+			expected: []string{filepath.Join(dir, "app-arm64-v8a.apk"), filepath.Join(dir, "app-x86_64.apk")},
+		},
+		"duplicates": {
+			patterns: []string{filepath.Join(dir, "app.aab"), filepath.Join(dir, "*")},
+			expected: []string{filepath.Join(dir, "app.aab"), filepath.Join(dir, "app-arm64-v8a.apk"), filepath.Join(dir, "app-x86_64.apk")},
+		},
+		"no match": {
+			patterns:    []string{filepath.Join(dir, "*.ipa")},
+			expectedErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			actual, err := ExpandPaths(c.patterns)
+
+			if c.expectedErr {
+				if err == nil {
+					t.Errorf("%s case is expected to be failure but not: %v", name, actual)
+				}
+
+				return
+			} else if err != nil {
+				t.Fatalf("%s case is expected to be success but not: %v", name, err)
+			}
+
+			if !reflect.DeepEqual(c.expected, actual) {
+				t.Errorf("%v is expected but %v", c.expected, actual)
+			}
+		})
+	}
+}
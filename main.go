@@ -11,6 +11,8 @@ import (
 	"os"
 )
 
+//go:generate sh -c "go run . config schema --document > splitter.document.yml"
+
 func main() {
 	app := &cli.App{
 		Name:      "splitter",
@@ -19,22 +21,40 @@ func main() {
 		Copyright: "Jumpei Matsuda (@jmatsu)",
 		Compiled:  internal.CompiledAt,
 		Flags: []cli.Flag{
-			&cli.PathFlag{
+			&cli.StringSliceFlag{
 				Name:     "config",
-				Usage:    "A path to a config file.",
+				Usage:    "A path to a config file. Repeat this option to merge several files in order.",
 				Required: false,
-				Action: func(context *cli.Context, s cli.Path) error {
-					if _, err := os.Stat(s); err == nil {
-						return nil
-					} else {
-						return errors.New(fmt.Sprintf("%s is not found", s))
+				Action: func(context *cli.Context, paths []string) error {
+					for _, s := range paths {
+						if _, err := os.Stat(s); err != nil {
+							return errors.New(fmt.Sprintf("%s is not found", s))
+						}
 					}
+
+					return nil
 				},
 				EnvVars: []string{
 					config.ToEnvName("CONFIG_FILE"),
 				},
 				TakesFile: true,
 			},
+			&cli.StringFlag{
+				Name:     "profile",
+				Usage:    "A profile in the config file to override values of deployments.",
+				Required: false,
+				EnvVars: []string{
+					config.ToEnvName("PROFILE"),
+				},
+			},
+			&cli.BoolFlag{
+				Name:     "allow-config-override",
+				Usage:    "Allow later config files to override deployments, services and groups of the same name.",
+				Required: false,
+				EnvVars: []string{
+					config.ToEnvName("ALLOW_CONFIG_OVERRIDE"),
+				},
+			},
 			&cli.StringFlag{
 				Name:     "format",
 				Usage:    "The output style of command outputs.",
@@ -71,19 +91,31 @@ func main() {
 					config.ToEnvName("WAIT_TIMEOUT"),
 				},
 			},
+			&cli.PathFlag{
+				Name:        "history-dir",
+				Usage:       "A directory to record the deployment history.",
+				Required:    false,
+				DefaultText: config.DefaultHistoryDir,
+				EnvVars: []string{
+					config.ToEnvName("HISTORY_DIR"),
+				},
+			},
 		},
 		Before: func(context *cli.Context) error {
 			if logLevel := context.String("log-level"); context.IsSet("log-level") {
 				logger.SetLogLevel(logLevel)
 			}
 
-			var path *string
+			// validate command reports the problems of the loaded config file by itself
+			validating := context.Args().First() == "validate"
 
-			if v := context.Path("config"); context.IsSet("config") {
-				path = &v
+			options := config.LoadOptions{
+				Paths:         context.StringSlice("config"),
+				AllowOverride: context.Bool("allow-config-override"),
+				Profile:       context.String("profile"),
 			}
 
-			if err := config.LoadGlobalConfig(path); err != nil {
+			if err := config.LoadGlobalConfig(options); err != nil && !(validating && config.IsProblems(err)) {
 				return err
 			}
 
@@ -99,9 +131,13 @@ func main() {
 				config.SetGlobalWaitTimeout(v)
 			}
 
+			if v := context.Path("history-dir"); context.IsSet("history-dir") {
+				config.SetGlobalHistoryDir(v)
+			}
+
 			c := config.CurrentConfig()
 
-			if err := c.Validate(); err != nil {
+			if err := c.Validate(); err != nil && !validating {
 				return errors.Wrap(err, "options contain invalid values or conflict with the current config file")
 			}
 
@@ -109,6 +145,7 @@ func main() {
 				Str("network-timeout", c.NetworkTimeout().String()).
 				Str("wait-timeout", c.WaitTimeout().String()).
 				Str("format-style", c.FormatStyle()).
+				Str("profile", c.Profile()).
 				Msg("configuration has been initialized")
 
 			return nil
@@ -122,6 +159,9 @@ func main() {
 			command.AddDeploymentConfig("add-deployment", []string{}),
 			command.CustomService("service", []string{}),
 			command.TestFlight("test-flight", []string{"tf"}),
+			command.History("history", []string{}),
+			command.Config("config", []string{}),
+			command.Validate("validate", []string{}),
 		},
 	}
 
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"encoding/json"
+	"github.com/jmatsu/splitter/internal/util"
+	"path/filepath"
+)
+
+// Artifact identifies the source file that has been deployed.
+type Artifact struct {
+	FileName string `json:"file_name"`
+	Size     int64  `json:"size"`
+	SHA256   string `json:"sha256"`
+}
+
+// NewArtifact computes the fingerprint of the file. See util.DigestFile.
+func NewArtifact(filePath string) (*Artifact, error) {
+	digest, err := util.DigestFile(filePath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Artifact{
+		FileName: filepath.Base(filePath),
+		Size:     digest.Size,
+		SHA256:   digest.SHA256,
+	}, nil
+}
+
+// Wrap the raw response of a service with the artifact so that the output always has the same structure.
+//
+//	{"artifact": {...}, "response": <the raw response>}
+func wrapRawJson(artifact Artifact, raw string) string {
+	var response any = raw
+
+	if json.Valid([]byte(raw)) {
+		response = json.RawMessage(raw)
+	}
+
+	if bytes, err := json.Marshal(map[string]any{
+		"artifact": artifact,
+		"response": response,
+	}); err != nil {
+		panic(err)
+	} else {
+		return string(bytes)
+	}
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_NewArtifact(t *testing.T) {
+	t.Parallel()
+
+	filePath := filepath.Join(t.TempDir(), "app.apk")
+
+	if err := os.WriteFile(filePath, []byte("hello"), 0644); err != nil {
+		t.Fatalf("failed to prepare a file: %v", err)
+	}
+
+	artifact, err := NewArtifact(filePath)
+
+	if err != nil {
+		t.Fatalf("an artifact is expected to be computed but not: %v", err)
+	}
+
+	expected := Artifact{
+		FileName: "app.apk",
+		Size:     5,
+		SHA256:   "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+	}
+
+	if *artifact != expected {
+		t.Errorf("%v is expected but %v", expected, *artifact)
+	}
+
+	if _, err := NewArtifact(filepath.Join(t.TempDir(), "missing.apk")); err == nil {
+		t.Errorf("a missing file is expected to be an error")
+	}
+}
+
+func Test_wrapRawJson(t *testing.T) {
+	t.Parallel()
+
+	artifact := Artifact{
+		FileName: "app.apk",
+		Size:     5,
+		SHA256:   "abc",
+	}
+
+	cases := map[string]struct {
+		raw      string
+		expected string
+	}{
+		"json object": {
+			raw:      `{"key":"value"}`,
+			expected: `{"artifact":{"file_name":"app.apk","size":5,"sha256":"abc"},"response":{"key":"value"}}`,
+		},
+		"non-json": {
+			raw:      "plain text",
+			expected: `{"artifact":{"file_name":"app.apk","size":5,"sha256":"abc"},"response":"plain text"}`,
+		},
+		"empty": {
+			raw:      "",
+			expected: `{"artifact":{"file_name":"app.apk","size":5,"sha256":"abc"},"response":""}`,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if actual := wrapRawJson(artifact, c.raw); actual != c.expected {
+				t.Errorf("%s is expected but %s", c.expected, actual)
+			}
+		})
+	}
+}
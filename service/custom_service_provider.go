@@ -5,6 +5,7 @@ import (
 	"github.com/jmatsu/splitter/internal/config"
 	"github.com/jmatsu/splitter/internal/logger"
 	"github.com/jmatsu/splitter/internal/net"
+	"github.com/jmatsu/splitter/internal/secret"
 	"github.com/jmatsu/splitter/internal/util"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
@@ -19,11 +20,17 @@ func init() {
 func NewCustomServiceProvider(ctx context.Context, definition *config.CustomServiceDefinition, conf *config.CustomServiceConfig) *CustomServiceProvider {
 	baseUrl, path := util.CutEndpoint(definition.Endpoint)
 
+	client := net.NewHttpClient(baseUrl)
+
+	if client != nil {
+		client = client.WithRetry(conf.Retry).WithLogger(customServiceLogger)
+	}
+
 	return &CustomServiceProvider{
 		CustomServiceConfig:     *conf,
 		CustomServiceDefinition: *definition,
 		ctx:                     ctx,
-		client:                  net.NewHttpClient(baseUrl),
+		client:                  client,
 		path:                    path,
 	}
 }
@@ -77,18 +84,25 @@ func (r *CustomServiceDeployRequest) NewUploadRequest() *CustomServiceUploadAppR
 
 type CustomServiceDeployResult struct {
 	CustomServiceUploadResponse
+	Artifact Artifact
 }
 
 var _ DeployResult = &CustomServiceDeployResult{}
 
 func (r *CustomServiceDeployResult) RawJsonResponse() string {
-	return r.CustomServiceUploadResponse.RawResponse.RawJson()
+	return wrapRawJson(r.Artifact, r.CustomServiceUploadResponse.RawResponse.RawJson())
 }
 
 func (r *CustomServiceDeployResult) ValueResponse() any {
 	return *r
 }
 
+func (r *CustomServiceDeployResult) Summary() DeploySummary {
+	return DeploySummary{
+		Artifact: r.Artifact,
+	}
+}
+
 func (p *CustomServiceProvider) Deploy(filePath string, builder func(req *CustomServiceDeployRequest) error) (*CustomServiceDeployResult, error) {
 	request := &CustomServiceDeployRequest{
 		filePath: filePath,
@@ -102,11 +116,32 @@ func (p *CustomServiceProvider) Deploy(filePath string, builder func(req *Custom
 		customServiceLogger.Debug().Msgf("the request has been built: %v", *request)
 	}
 
+	artifact, err := NewArtifact(filePath)
+
+	if err != nil {
+		return nil, err
+	}
+
 	if r, err := p.upload(request.NewUploadRequest()); err != nil {
 		return nil, err
 	} else {
 		return &CustomServiceDeployResult{
 			CustomServiceUploadResponse: *r,
+			Artifact:                    *artifact,
 		}, nil
 	}
 }
+
+// DryRun builds the requests in the same way as Deploy but never sends them.
+func (p *CustomServiceProvider) DryRun(filePath string, builder func(req *CustomServiceDeployRequest) error) (*DryRunResult, error) {
+	recorder := net.NewRecorder()
+	secret.Register(p.AuthToken)
+
+	p.client = p.client.WithRecorder(recorder)
+
+	if _, err := p.Deploy(filePath, builder); err != nil {
+		return nil, err
+	}
+
+	return newDryRunResult(recorder), nil
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/jmatsu/splitter/internal/config"
 	logger2 "github.com/jmatsu/splitter/internal/logger"
 	"github.com/jmatsu/splitter/internal/net"
+	"github.com/jmatsu/splitter/internal/secret"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 )
@@ -19,7 +20,7 @@ func NewDeployGateProvider(ctx context.Context, config *config.DeployGateConfig)
 	return &DeployGateProvider{
 		DeployGateConfig: *config,
 		ctx:              ctx,
-		client:           net.NewHttpClient("https://deploygate.com"),
+		client:           net.NewHttpClient("https://deploygate.com").WithRetry(config.Retry).WithLogger(deployGateLogger),
 	}
 }
 
@@ -69,18 +70,39 @@ func (r *DeployGateDeployRequest) NewUploadRequest() *DeployGateUploadAppRequest
 
 type DeployGateDeployResult struct {
 	DeployGateUploadResponse
+	Artifact Artifact
 }
 
 var _ DeployResult = &DeployGateDeployResult{}
 
 func (r *DeployGateDeployResult) RawJsonResponse() string {
-	return r.DeployGateUploadResponse.RawResponse.RawJson()
+	return wrapRawJson(r.Artifact, r.DeployGateUploadResponse.RawResponse.RawJson())
 }
 
 func (r *DeployGateDeployResult) ValueResponse() any {
 	return *r
 }
 
+func (r *DeployGateDeployResult) Summary() DeploySummary {
+	urls := map[string]string{}
+
+	if v := r.Results.DownloadUrl; v != "" {
+		urls["download"] = v
+	}
+
+	if d := r.Results.Distribution; d != nil && d.Url != "" {
+		urls["distribution"] = d.Url
+	}
+
+	return DeploySummary{
+		Artifact:    r.Artifact,
+		PackageName: r.Results.PackageName,
+		VersionName: r.Results.VersionName,
+		VersionCode: r.Results.VersionCode,
+		URLs:        urls,
+	}
+}
+
 func (p *DeployGateProvider) Deploy(filePath string, builder func(req *DeployGateDeployRequest) error) (*DeployGateDeployResult, error) {
 	request := &DeployGateDeployRequest{
 		filePath: filePath,
@@ -92,11 +114,32 @@ func (p *DeployGateProvider) Deploy(filePath string, builder func(req *DeployGat
 		deployGateLogger.Debug().Msgf("the request has been built: %v", *request)
 	}
 
+	artifact, err := NewArtifact(filePath)
+
+	if err != nil {
+		return nil, err
+	}
+
 	if r, err := p.upload(request.NewUploadRequest()); err != nil {
 		return nil, err
 	} else {
 		return &DeployGateDeployResult{
 			DeployGateUploadResponse: *r,
+			Artifact:                 *artifact,
 		}, nil
 	}
 }
+
+// DryRun builds the requests in the same way as Deploy but never sends them.
+func (p *DeployGateProvider) DryRun(filePath string, builder func(req *DeployGateDeployRequest) error) (*DryRunResult, error) {
+	recorder := net.NewRecorder()
+	secret.Register(p.ApiToken)
+
+	p.client = p.client.WithRecorder(recorder)
+
+	if _, err := p.Deploy(filePath, builder); err != nil {
+		return nil, err
+	}
+
+	return newDryRunResult(recorder), nil
+}
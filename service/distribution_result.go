@@ -4,3 +4,12 @@ type DeployResult interface {
 	ValueResponse() any
 	RawJsonResponse() string
 }
+
+// DeploySummary is a service-agnostic digest of a deployment. Services fill what they know.
+type DeploySummary struct {
+	Artifact    Artifact
+	PackageName string
+	VersionName string
+	VersionCode string
+	URLs        map[string]string // e.g. download => https://...
+}
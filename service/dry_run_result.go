@@ -0,0 +1,33 @@
+package service
+
+import (
+	"encoding/json"
+	"github.com/jmatsu/splitter/internal/net"
+)
+
+// DryRunResult holds what a provider would do instead of actual responses.
+type DryRunResult struct {
+	Profile    string              `json:"profile,omitempty"`
+	Requests   []net.RequestRecord `json:"requests,omitempty"`
+	Operations []string            `json:"operations,omitempty"`
+}
+
+var _ DeployResult = &DryRunResult{}
+
+func newDryRunResult(recorder *net.Recorder) *DryRunResult {
+	return &DryRunResult{
+		Requests: recorder.Records(),
+	}
+}
+
+func (r *DryRunResult) RawJsonResponse() string {
+	if bytes, err := json.Marshal(r); err != nil {
+		panic(err)
+	} else {
+		return string(bytes)
+	}
+}
+
+func (r *DryRunResult) ValueResponse() any {
+	return *r
+}
@@ -39,6 +39,21 @@ var _ net.TypedHttpResponse = &FirebaseAppDistributionV1UploadReleaseResponse{}
 
 // Wait until the processing in app distribution has done
 func (p *FirebaseAppDistributionProvider) waitForOperationDone(request *firebaseAppDistributionGetOperationStateRequest) (*FirebaseAppDistributionGetOperationStateResponse, error) {
+	if p.client.IsDryRun() {
+		firebaseAppDistributionLogger.Debug().Msg("skip waiting for the operation in dry-run mode")
+
+		// a placeholder release to build the following requests
+		return &FirebaseAppDistributionGetOperationStateResponse{
+			OperationName: request.operationName,
+			Done:          true,
+			Response: &FirebaseAppDistributionV1UploadReleaseResponse{
+				Release: FirebaseAppDistributionReleaseFragment{
+					Name: fmt.Sprintf("projects/%s/apps/%s/releases/RELEASE_ID", p.ProjectNumber(), p.AppId),
+				},
+			},
+		}, nil
+	}
+
 	waitTimeout := config.CurrentConfig().WaitTimeout()
 
 	var retryCount int
@@ -2,9 +2,11 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"github.com/jmatsu/splitter/internal/config"
 	logger2 "github.com/jmatsu/splitter/internal/logger"
 	"github.com/jmatsu/splitter/internal/net"
+	"github.com/jmatsu/splitter/internal/secret"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	"path/filepath"
@@ -21,7 +23,7 @@ func NewFirebaseAppDistributionProvider(ctx context.Context, config *config.Fire
 	return &FirebaseAppDistributionProvider{
 		FirebaseAppDistributionConfig: *config,
 		ctx:                           ctx,
-		client:                        net.NewHttpClient("https://firebaseappdistribution.googleapis.com"),
+		client:                        net.NewHttpClient("https://firebaseappdistribution.googleapis.com").WithRetry(config.Retry).WithLogger(firebaseAppDistributionLogger),
 	}
 }
 
@@ -37,18 +39,45 @@ type FirebaseAppDistributionDeployResult struct {
 	AabInfo      *FirebaseAppDistributionAabInfoResponse
 	GroupAliases []string
 	TesterEmails []string
+	Artifact     Artifact
 }
 
 var _ DeployResult = &FirebaseAppDistributionDeployResult{}
 
 func (r *FirebaseAppDistributionDeployResult) RawJsonResponse() string {
-	return r.FirebaseAppDistributionGetOperationStateResponse.RawResponse.RawJson()
+	return wrapRawJson(r.Artifact, r.FirebaseAppDistributionGetOperationStateResponse.RawResponse.RawJson())
 }
 
 func (r *FirebaseAppDistributionDeployResult) ValueResponse() any {
 	return *r
 }
 
+func (r *FirebaseAppDistributionDeployResult) Summary() DeploySummary {
+	summary := DeploySummary{
+		Artifact: r.Artifact,
+		URLs:     map[string]string{},
+	}
+
+	if r.Response == nil {
+		return summary
+	}
+
+	release := r.Response.Release
+
+	summary.VersionName = release.DisplayVersion
+	summary.VersionCode = release.BuildVersion
+
+	if v := release.FirebaseConsoleUri; v != "" {
+		summary.URLs["console"] = v
+	}
+
+	if v := release.TestingUri; v != "" {
+		summary.URLs["testing"] = v
+	}
+
+	return summary
+}
+
 type FirebaseAppDistributionDeployRequest struct {
 	projectNumber string
 	appId         string
@@ -88,6 +117,12 @@ func (r *FirebaseAppDistributionDeployRequest) NewUploadRequest() *FirebaseAppDi
 
 func (p *FirebaseAppDistributionProvider) fetchToken() error {
 	if p.AccessToken == "" && p.GoogleCredentialsPath != "" {
+		if p.client.IsDryRun() {
+			firebaseAppDistributionLogger.Debug().Msg("skip fetching a token in dry-run mode")
+			p.AccessToken = fmt.Sprintf("<a token of %s>", p.GoogleCredentialsPath)
+			return nil
+		}
+
 		if t, err := FirebaseToken(p.ctx, p.GoogleCredentialsPath); err != nil {
 			return errors.Wrap(err, "cannot fetch a token")
 		} else {
@@ -127,13 +162,19 @@ func (p *FirebaseAppDistributionProvider) Deploy(filePath string, builder func(r
 			projectNumber: request.projectNumber,
 		})
 
-		if request.fileType() == "aab" {
+		if request.fileType() == "aab" && !p.client.IsDryRun() {
 			if err := checkAppBundleIntegrationState(aabInfo.IntegrationState); err != nil {
 				return nil, err
 			}
 		}
 	}
 
+	artifact, err := NewArtifact(request.filePath)
+
+	if err != nil {
+		return nil, err
+	}
+
 	var operation string
 
 	if r, err := p.upload(request.NewUploadRequest()); err != nil {
@@ -154,10 +195,12 @@ func (p *FirebaseAppDistributionProvider) Deploy(filePath string, builder func(r
 		response = resp
 	}
 
+	release := response.Response.Release
+
 	if request.releaseNote != "" {
 		firebaseAppDistributionLogger.Debug().Msg("start updating the release note")
 
-		req := response.Response.Release.NewUpdateRequest(request.releaseNote)
+		req := release.NewUpdateRequest(request.releaseNote)
 
 		if resp, err := p.updateReleaseNote(req); err != nil {
 			firebaseAppDistributionLogger.Warn().Err(err).Msg("failed to update the release note")
@@ -168,7 +211,8 @@ func (p *FirebaseAppDistributionProvider) Deploy(filePath string, builder func(r
 
 	result := FirebaseAppDistributionDeployResult{
 		FirebaseAppDistributionGetOperationStateResponse: *response,
-		AabInfo: aabInfo,
+		AabInfo:  aabInfo,
+		Artifact: *artifact,
 	}
 
 	if len(request.groupAliases) > 0 || len(request.testerEmails) > 0 {
@@ -185,7 +229,7 @@ func (p *FirebaseAppDistributionProvider) Deploy(filePath string, builder func(r
 			testerEmails = request.testerEmails
 		}
 
-		req := response.Response.Release.NewDistributeRequest(testerEmails, groupAliases)
+		req := release.NewDistributeRequest(testerEmails, groupAliases)
 
 		if err := p.distributeRelease(req); err != nil {
 			firebaseAppDistributionLogger.Warn().Err(err).Msg("failed to distribute the release")
@@ -197,3 +241,17 @@ func (p *FirebaseAppDistributionProvider) Deploy(filePath string, builder func(r
 
 	return &result, nil
 }
+
+// DryRun builds the requests in the same way as Deploy but never sends them.
+func (p *FirebaseAppDistributionProvider) DryRun(filePath string, builder func(req *FirebaseAppDistributionDeployRequest) error) (*DryRunResult, error) {
+	recorder := net.NewRecorder()
+	secret.Register(p.AccessToken)
+
+	p.client = p.client.WithRecorder(recorder)
+
+	if _, err := p.Deploy(filePath, builder); err != nil {
+		return nil, err
+	}
+
+	return newDryRunResult(recorder), nil
+}
@@ -14,6 +14,9 @@ type FirebaseAppDistributionReleaseFragment struct {
 	BuildVersion   string                                      `json:"buildVersion"`
 	CreatedAt      string                                      `json:"createTime"`
 	ReleaseNote    *FirebaseAppDistributionReleaseNoteFragment `json:"releaseNotes"`
+
+	FirebaseConsoleUri string `json:"firebaseConsoleUri"`
+	TestingUri         string `json:"testingUri"`
 }
 
 type FirebaseAppDistributionReleaseNoteFragment struct {
@@ -121,3 +124,72 @@ func (p *FirebaseAppDistributionProvider) distributeRelease(request *firebaseApp
 		return errors.Wrap(resp.Err(), "failed to distribute the release to testers")
 	}
 }
+
+type firebaseAppDistributionListReleasesResponse struct {
+	Releases []struct {
+		Name string `json:"name"`
+
+		// A signed URL. Never print this.
+		BinaryDownloadUri string `json:"binaryDownloadUri"`
+	} `json:"releases"`
+
+	RawResponse *net.HttpResponse `json:"-"`
+}
+
+var _ net.TypedHttpResponse = &firebaseAppDistributionListReleasesResponse{}
+
+func (r *firebaseAppDistributionListReleasesResponse) Set(v *net.HttpResponse) {
+	r.RawResponse = v
+}
+
+func (p *FirebaseAppDistributionProvider) listLatestRelease() (*firebaseAppDistributionListReleasesResponse, error) {
+	path := fmt.Sprintf("/v1/projects/%s/apps/%s/releases", p.ProjectNumber(), p.AppId)
+
+	client := p.client.WithHeaders(map[string][]string{
+		"Authorization": {fmt.Sprintf("Bearer %s", p.AccessToken)},
+	})
+
+	// releases are ordered by createTime in descending order by default
+	resp, err := client.DoGet(p.ctx, []string{path}, map[string][]string{
+		"pageSize": {"1"},
+	})
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get a response from list releases api")
+	}
+
+	if resp.Successful() {
+		if v, err := resp.ParseJson(&firebaseAppDistributionListReleasesResponse{}); err != nil {
+			return nil, errors.Wrap(err, "succeeded to list releases but something went wrong")
+		} else {
+			return v.(*firebaseAppDistributionListReleasesResponse), nil
+		}
+	} else {
+		return nil, errors.Wrap(resp.Err(), "failed to list releases")
+	}
+}
+
+// LatestReleaseSHA256 returns the SHA-256 of the binary of the latest release. The binary is downloaded only if its size is same to the given size.
+// An empty string is returned if no release is found or the size differs.
+func (p *FirebaseAppDistributionProvider) LatestReleaseSHA256(size int64) (string, error) {
+	if err := p.fetchToken(); err != nil {
+		return "", errors.Wrap(err, "a valid token is required to make requests")
+	}
+
+	resp, err := p.listLatestRelease()
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(resp.Releases) == 0 || resp.Releases[0].BinaryDownloadUri == "" {
+		firebaseAppDistributionLogger.Debug().Msg("no binary of the latest release is found")
+		return "", nil
+	}
+
+	if v, err := p.client.DoGetSHA256(p.ctx, resp.Releases[0].BinaryDownloadUri, size); err != nil {
+		return "", errors.Wrapf(err, "failed to download the binary of %s", resp.Releases[0].Name)
+	} else {
+		return v, nil
+	}
+}
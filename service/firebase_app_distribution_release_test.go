@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"github.com/jmatsu/splitter/internal/config"
+	"github.com/jmatsu/splitter/internal/net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_FirebaseAppDistributionProvider_LatestReleaseSHA256(t *testing.T) {
+	t.Parallel()
+
+	content := "the latest binary"
+
+	cases := map[string]struct {
+		releases string
+		size     int64
+
+		expected string
+	}{
+		"same binary": {
+			releases: `{"releases":[{"name":"projects/123/apps/1:123:android:abc/releases/1","binaryDownloadUri":"{server}/binary"}]}`,
+			size:     int64(len(content)),
+			expected: fmt.Sprintf("%x", sha256.Sum256([]byte(content))),
+		},
+		"different size": {
+			releases: `{"releases":[{"name":"projects/123/apps/1:123:android:abc/releases/1","binaryDownloadUri":"{server}/binary"}]}`,
+			size:     int64(len(content)) + 1,
+			expected: "",
+		},
+		"no release": {
+			releases: `{}`,
+			size:     int64(len(content)),
+			expected: "",
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var server *httptest.Server
+
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/v1/projects/123/apps/1:123:android:abc/releases":
+					if r.Header.Get("Authorization") != "Bearer token" {
+						t.Errorf("the token is not sent")
+					}
+
+					if r.URL.Query().Get("pageSize") != "1" {
+						t.Errorf("only the latest release is expected to be listed")
+					}
+
+					_, _ = w.Write([]byte(strings.ReplaceAll(c.releases, "{server}", server.URL)))
+				case "/binary":
+					if r.Header.Get("Authorization") != "" {
+						t.Errorf("the token must not be sent to the signed URL")
+					}
+
+					_, _ = w.Write([]byte(content))
+				default:
+					t.Errorf("%s is unexpected", r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+
+			t.Cleanup(server.Close)
+
+			provider := FirebaseAppDistributionProvider{
+				FirebaseAppDistributionConfig: config.FirebaseAppDistributionConfig{
+					AppId:       "1:123:android:abc",
+					AccessToken: "token",
+				},
+				ctx:    context.Background(),
+				client: net.NewHttpClient(server.URL),
+			}
+
+			if v, err := provider.LatestReleaseSHA256(c.size); err != nil {
+				t.Errorf("failed to get the hash: %v", err)
+			} else if v != c.expected {
+				t.Errorf("%s is expected but %s", c.expected, v)
+			}
+		})
+	}
+}
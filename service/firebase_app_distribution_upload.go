@@ -1,10 +1,13 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"github.com/jmatsu/splitter/internal/net"
 	"github.com/pkg/errors"
+	"os"
 	"path/filepath"
+	"strconv"
 )
 
 type firebaseAppDistributionUploadResponse struct {
@@ -25,30 +28,204 @@ type FirebaseAppDistributionUploadAppRequest struct {
 	filePath      string
 }
 
+// The maximum number of times to resume an interrupted upload in a row. It is reset whenever a chunk is uploaded.
+const firebaseAppDistributionMaxResumptions = 5
+
 // https://firebase.google.com/docs/reference/app-distribution/rest/v1/upload.v1.projects.apps.releases/upload
 // required: firebaseappdistro.releases.update
+//
+// The file is uploaded by the resumable upload protocol. An upload session is started first, and then the file is sent chunk by chunk.
+// If a chunk fails, the received size is queried and the upload resumes from there. Requests of the session are never retried by the HTTP client
+// because resending a chunk at the old offset is rejected once the server has stored a part of it.
 func (p *FirebaseAppDistributionProvider) upload(request *FirebaseAppDistributionUploadAppRequest) (*firebaseAppDistributionUploadResponse, error) {
+	info, err := os.Stat(request.filePath)
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s is not found", request.filePath)
+	}
+
+	session, err := p.startUploadSession(request, info.Size())
+
+	if err != nil {
+		return nil, err
+	}
+
+	chunkSize := p.ChunkSize()
+
+	if session.granularity > 0 && chunkSize%session.granularity != 0 {
+		chunkSize = (chunkSize/session.granularity + 1) * session.granularity
+	}
+
+	var offset int64
+	var resumptions int
+
+	for {
+		length := chunkSize
+
+		if remaining := info.Size() - offset; remaining < length {
+			length = remaining
+		}
+
+		command := "upload"
+
+		if offset+length == info.Size() {
+			command = "upload, finalize"
+		}
+
+		firebaseAppDistributionLogger.Debug().Msgf("%s %d bytes from %d", command, length, offset)
+
+		resp, err := session.client.WithHeaders(map[string][]string{
+			"X-Goog-Upload-Command": {command},
+			"X-Goog-Upload-Offset":  {strconv.FormatInt(offset, 10)},
+		}).DoPostFileChunk(p.ctx, nil, nil, request.filePath, offset, length)
+
+		if err == nil && resp.Successful() {
+			if command == "upload" {
+				offset += length
+				resumptions = 0 // only consecutive interruptions count
+				continue
+			}
+
+			return parseFirebaseAppDistributionUploadResponse(resp)
+		}
+
+		if err == nil && resp.Code/100 == 4 {
+			return nil, errors.Wrap(resp.Err(), "failed to upload your app to Firebase App Distribution")
+		} else if err == nil {
+			err = resp.Err()
+		}
+
+		status, err := session.resume(p.ctx, offset, &resumptions, err)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if status.final {
+			return parseFirebaseAppDistributionUploadResponse(status.response)
+		}
+
+		offset = status.received
+	}
+}
+
+func parseFirebaseAppDistributionUploadResponse(resp *net.HttpResponse) (*firebaseAppDistributionUploadResponse, error) {
+	if v, err := resp.ParseJson(&firebaseAppDistributionUploadResponse{}); err != nil {
+		return nil, errors.Wrap(err, "succeeded to upload but something went wrong")
+	} else {
+		return v.(*firebaseAppDistributionUploadResponse), nil
+	}
+}
+
+type firebaseAppDistributionUploadSession struct {
+	client      *net.HttpClient
+	granularity int64
+}
+
+type firebaseAppDistributionUploadStatus struct {
+	final    bool
+	received int64
+	response *net.HttpResponse
+}
+
+func (p *FirebaseAppDistributionProvider) startUploadSession(request *FirebaseAppDistributionUploadAppRequest, size int64) (*firebaseAppDistributionUploadSession, error) {
 	path := fmt.Sprintf("/upload/v1/projects/%s/apps/%s/releases:upload", request.projectNumber, request.appId)
 
 	client := p.client.WithHeaders(map[string][]string{
-		"Authorization":           {fmt.Sprintf("Bearer %s", p.AccessToken)},
-		"X-Goog-Upload-File-Name": {filepath.Base(request.filePath)},
-		"X-Goog-Upload-Protocol":  {"raw"},
+		"Authorization":                       {fmt.Sprintf("Bearer %s", p.AccessToken)},
+		"X-Goog-Upload-File-Name":             {filepath.Base(request.filePath)},
+		"X-Goog-Upload-Protocol":              {"resumable"},
+		"X-Goog-Upload-Command":               {"start"},
+		"X-Goog-Upload-Header-Content-Length": {strconv.FormatInt(size, 10)},
+		"X-Goog-Upload-Header-Content-Type":   {"application/octet-stream"},
 	})
 
-	resp, err := client.DoPostFileBody(p.ctx, []string{path}, nil, request.filePath)
+	resp, err := client.DoPost(p.ctx, []string{path}, nil, "", nil)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to start an upload session")
+	} else if !resp.Successful() {
+		return nil, errors.Wrap(resp.Err(), "failed to start an upload session")
+	}
+
+	uploadURL := resp.Header("X-Goog-Upload-URL")
+
+	if p.client.IsDryRun() {
+		uploadURL = fmt.Sprintf("https://firebaseappdistribution.googleapis.com%s?upload_id=UPLOAD_ID&upload_protocol=resumable", path)
+	} else if uploadURL == "" {
+		return nil, errors.New("an upload URL is not returned")
+	}
+
+	sessionClient, err := p.client.WithBaseURL(uploadURL)
 
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to distribute to Firebase App Distribution")
+		return nil, errors.Wrap(err, "an upload URL is invalid")
+	}
+
+	session := firebaseAppDistributionUploadSession{
+		client: sessionClient.WithoutRetry().WithHeaders(map[string][]string{
+			"Authorization":          {fmt.Sprintf("Bearer %s", p.AccessToken)},
+			"X-Goog-Upload-Protocol": {"resumable"},
+		}),
+	}
+
+	if v := resp.Header("X-Goog-Upload-Chunk-Granularity"); v != "" {
+		if granularity, err := strconv.ParseInt(v, 10, 64); err == nil && granularity > 0 {
+			session.granularity = granularity
+		}
 	}
 
-	if resp.Successful() {
-		if v, err := resp.ParseJson(&firebaseAppDistributionUploadResponse{}); err != nil {
-			return nil, errors.Wrap(err, "succeeded to upload but something went wrong")
+	return &session, nil
+}
+
+// Query the received size until the session answers. Each query consumes a resumption because queries are not retried by the HTTP client either.
+func (s *firebaseAppDistributionUploadSession) resume(ctx context.Context, offset int64, resumptions *int, cause error) (*firebaseAppDistributionUploadStatus, error) {
+	for {
+		if *resumptions >= firebaseAppDistributionMaxResumptions {
+			return nil, errors.Wrap(cause, "failed to upload your app to Firebase App Distribution")
+		}
+
+		*resumptions++
+
+		firebaseAppDistributionLogger.Warn().Err(cause).Msgf("the upload has been interrupted at %d. querying the received size (%d/%d)", offset, *resumptions, firebaseAppDistributionMaxResumptions)
+
+		if status, err := s.query(ctx); err != nil {
+			cause = errors.Wrap(err, "cannot query the received size")
 		} else {
-			return v.(*firebaseAppDistributionUploadResponse), nil
+			return status, nil
 		}
-	} else {
-		return nil, errors.Wrap(resp.Err(), "failed to upload your app to Firebase App Distribution")
+	}
+}
+
+// Query the status of the upload session.
+func (s *firebaseAppDistributionUploadSession) query(ctx context.Context) (*firebaseAppDistributionUploadStatus, error) {
+	resp, err := s.client.WithHeaders(map[string][]string{
+		"X-Goog-Upload-Command": {"query"},
+	}).DoPost(ctx, nil, nil, "", nil)
+
+	if err != nil {
+		return nil, err
+	} else if !resp.Successful() {
+		return nil, resp.Err()
+	}
+
+	switch status := resp.Header("X-Goog-Upload-Status"); status {
+	case "final":
+		return &firebaseAppDistributionUploadStatus{
+			final:    true,
+			response: resp,
+		}, nil
+	case "active":
+		received, err := strconv.ParseInt(resp.Header("X-Goog-Upload-Size-Received"), 10, 64)
+
+		if err != nil {
+			return nil, errors.Wrap(err, "the received size is unknown")
+		}
+
+		return &firebaseAppDistributionUploadStatus{
+			received: received,
+		}, nil
+	default:
+		return nil, errors.New(fmt.Sprintf("the upload session is not resumable: %s", status))
 	}
 }
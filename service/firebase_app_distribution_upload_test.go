@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmatsu/splitter/internal/config"
+	"github.com/jmatsu/splitter/internal/net"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func Test_FirebaseAppDistributionProvider_upload(t *testing.T) {
+	t.Parallel()
+
+	content := strings.Repeat("0123456789", 100) // 1000 bytes
+	filePath := filepath.Join(t.TempDir(), "app.apk")
+
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create a file: %v", err)
+	}
+
+	var mu sync.Mutex
+	var received []byte
+	var commands []string
+	var interrupted, queried bool
+
+	var server *httptest.Server
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		command := r.Header.Get("X-Goog-Upload-Command")
+		commands = append(commands, command)
+
+		switch command {
+		case "start":
+			if r.Header.Get("X-Goog-Upload-Header-Content-Length") != "1000" {
+				t.Errorf("the content length is not expected: %s", r.Header.Get("X-Goog-Upload-Header-Content-Length"))
+			}
+
+			w.Header().Set("X-Goog-Upload-URL", fmt.Sprintf("%s/session?upload_id=1", server.URL))
+			w.Header().Set("X-Goog-Upload-Chunk-Granularity", "256")
+		case "upload", "upload, finalize":
+			if r.URL.Query().Get("upload_id") != "1" {
+				t.Errorf("the upload URL is not used: %s", r.URL.String())
+			}
+
+			if offset := r.Header.Get("X-Goog-Upload-Offset"); offset != strconv.Itoa(len(received)) {
+				t.Errorf("the offset is expected to be %d but %s", len(received), offset)
+			}
+
+			b, _ := io.ReadAll(r.Body)
+
+			if len(received) > 0 && !interrupted {
+				// accept a half of the chunk and fail
+				interrupted = true
+				received = append(received, b[:len(b)/2]...)
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
+			received = append(received, b...)
+
+			if command == "upload, finalize" {
+				_, _ = w.Write([]byte(`{"name":"operations/1"}`))
+			}
+		case "query":
+			if !queried {
+				// the query can be interrupted as well
+				queried = true
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
+			w.Header().Set("X-Goog-Upload-Status", "active")
+			w.Header().Set("X-Goog-Upload-Size-Received", strconv.Itoa(len(received)))
+		default:
+			t.Errorf("%s is an unexpected command", command)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+
+	t.Cleanup(server.Close)
+
+	provider := FirebaseAppDistributionProvider{
+		FirebaseAppDistributionConfig: config.FirebaseAppDistributionConfig{
+			AppId:           "1:123:android:abc",
+			AccessToken:     "token",
+			UploadChunkSize: "300", // rounded up to 512
+		},
+		ctx: context.Background(),
+		// requests of the session must not be retried even if the retry is enabled
+		client: net.NewHttpClient(server.URL).WithRetry(&config.RetryConfig{MaxAttempts: 3, BaseDelay: "1ms"}),
+	}
+
+	response, err := provider.upload(&FirebaseAppDistributionUploadAppRequest{
+		projectNumber: "123",
+		appId:         "1:123:android:abc",
+		filePath:      filePath,
+	})
+
+	if err != nil {
+		t.Fatalf("failed to upload: %v", err)
+	}
+
+	if response.OperationName != "operations/1" {
+		t.Errorf("operations/1 is expected but %s", response.OperationName)
+	}
+
+	if string(received) != content {
+		t.Errorf("the received content is broken: %d bytes", len(received))
+	}
+
+	expected := []string{"start", "upload", "upload, finalize", "query", "query", "upload, finalize"}
+
+	if strings.Join(commands, "|") != strings.Join(expected, "|") {
+		t.Errorf("%v is expected but %v", expected, commands)
+	}
+}
+
+func Test_FirebaseAppDistributionProvider_upload_resumptions(t *testing.T) {
+	t.Parallel()
+
+	// every chunk is interrupted once, so the interruptions exceed the max resumptions in total but never in a row
+	chunks := firebaseAppDistributionMaxResumptions + 2
+	content := strings.Repeat("0", 256*chunks)
+	filePath := filepath.Join(t.TempDir(), "app.apk")
+
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create a file: %v", err)
+	}
+
+	var mu sync.Mutex
+	var received int
+	interrupted := map[int]bool{}
+
+	var server *httptest.Server
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch command := r.Header.Get("X-Goog-Upload-Command"); command {
+		case "start":
+			w.Header().Set("X-Goog-Upload-URL", fmt.Sprintf("%s/session?upload_id=1", server.URL))
+			w.Header().Set("X-Goog-Upload-Chunk-Granularity", "256")
+		case "upload", "upload, finalize":
+			b, _ := io.ReadAll(r.Body)
+
+			if !interrupted[received] {
+				interrupted[received] = true
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
+			received += len(b)
+
+			if command == "upload, finalize" {
+				_, _ = w.Write([]byte(`{"name":"operations/1"}`))
+			}
+		case "query":
+			w.Header().Set("X-Goog-Upload-Status", "active")
+			w.Header().Set("X-Goog-Upload-Size-Received", strconv.Itoa(received))
+		default:
+			t.Errorf("%s is an unexpected command", command)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+
+	t.Cleanup(server.Close)
+
+	provider := FirebaseAppDistributionProvider{
+		FirebaseAppDistributionConfig: config.FirebaseAppDistributionConfig{
+			AppId:           "1:123:android:abc",
+			AccessToken:     "token",
+			UploadChunkSize: "256",
+		},
+		ctx:    context.Background(),
+		client: net.NewHttpClient(server.URL),
+	}
+
+	if _, err := provider.upload(&FirebaseAppDistributionUploadAppRequest{
+		projectNumber: "123",
+		appId:         "1:123:android:abc",
+		filePath:      filePath,
+	}); err != nil {
+		t.Fatalf("failed to upload: %v", err)
+	}
+
+	if len(interrupted) != chunks {
+		t.Errorf("%d interruptions are expected but %d", chunks, len(interrupted))
+	}
+}
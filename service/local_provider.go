@@ -3,11 +3,16 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/jmatsu/splitter/internal/config"
 	"github.com/jmatsu/splitter/internal/logger"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
+	"golang.org/x/exp/slices"
+	"net/url"
 	"os"
+	"path/filepath"
+	"time"
 )
 
 var localLogger zerolog.Logger
@@ -20,12 +25,14 @@ func NewLocalProvider(ctx context.Context, config *config.LocalConfig) *LocalPro
 	return &LocalProvider{
 		LocalConfig: *config,
 		ctx:         ctx,
+		rename:      os.Rename,
 	}
 }
 
 type LocalProvider struct {
 	config.LocalConfig
-	ctx context.Context
+	ctx    context.Context
+	rename func(oldpath, newpath string) error
 }
 
 type LocalDeployRequest struct {
@@ -33,7 +40,21 @@ type LocalDeployRequest struct {
 	destinationFilePath string
 	allowOverwrite      bool
 	fileMode            os.FileMode
+	dirMode             os.FileMode
 	deleteResource      bool
+	sidecarFilePaths    []string
+	deploymentName      string
+	version             string
+}
+
+func (r *LocalDeployRequest) SetDeploymentName(value string) {
+	r.deploymentName = value
+}
+
+// SetVersion stores the file in the version that other files share. latest is not updated until LocalProvider.Publish is called.
+// This is available only for versioning.
+func (r *LocalDeployRequest) SetVersion(value string) {
+	r.version = value
 }
 
 func (r *LocalDeployRequest) NewMoveRequest() *LocalMoveRequest {
@@ -42,7 +63,9 @@ func (r *LocalDeployRequest) NewMoveRequest() *LocalMoveRequest {
 		destinationFilePath: r.destinationFilePath,
 		allowOverwrite:      r.allowOverwrite,
 		fileMode:            r.fileMode,
+		dirMode:             r.dirMode,
 		deleteResource:      r.deleteResource,
+		sidecarFilePaths:    r.sidecarFilePaths,
 	}
 
 	return &request
@@ -50,43 +73,500 @@ func (r *LocalDeployRequest) NewMoveRequest() *LocalMoveRequest {
 
 type LocalDeployResult struct {
 	LocalMoveResponse
-	RawJson string
+	Artifact Artifact
+	RawJson  string
 }
 
 var _ DeployResult = &LocalDeployResult{}
 
 func (r *LocalDeployResult) RawJsonResponse() string {
-	return r.RawJson
+	return wrapRawJson(r.Artifact, r.RawJson)
 }
 
 func (r *LocalDeployResult) ValueResponse() any {
 	return *r
 }
 
-func (p *LocalProvider) Deploy(filePath string) (*LocalDeployResult, error) {
+func (r *LocalDeployResult) Summary() DeploySummary {
+	urls := map[string]string{}
+
+	if path, err := filepath.Abs(r.DestinationFilePath); err == nil {
+		urls["destination"] = (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+	}
+
+	if r.LatestFilePath != "" {
+		if path, err := filepath.Abs(r.LatestFilePath); err == nil {
+			urls["latest"] = (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+		}
+	}
+
+	if r.InstallPage != nil {
+		urls["install_page"] = r.InstallPage.URL
+	}
+
+	return DeploySummary{
+		Artifact: r.Artifact,
+		URLs:     urls,
+	}
+}
+
+func (p *LocalProvider) newDeployRequest(filePath string) *LocalDeployRequest {
 	request := LocalDeployRequest{
 		sourceFilePath:      filePath,
 		destinationFilePath: p.DestinationPath,
 		allowOverwrite:      p.AllowOverwrite,
+		dirMode:             p.ParentDirMode(),
 		deleteResource:      p.DeleteSource,
 	}
 
+	request.sidecarFilePaths = p.sidecarFilePaths(request.destinationFilePath)
+
 	if p.FileMode != 0 {
 		request.fileMode = p.FileMode
 	} else if v, err := os.Stat(request.sourceFilePath); err == nil { // Do not validate the request here
 		request.fileMode = v.Mode()
 	}
 
+	return &request
+}
+
+func (p *LocalProvider) versions() *localVersions {
+	return &localVersions{
+		dir:      p.DestinationPath,
+		strategy: p.Versioning.LatestStrategy(),
+	}
+}
+
+// Versioning stores the file in a version directory so the destination file is never overwritten.
+// A new version is created unless the builder sets a shared version. The second value is true if the version is shared.
+func (p *LocalProvider) buildVersionedDeployRequest(filePath string, builder func(req *LocalDeployRequest) error) (*LocalDeployRequest, bool, error) {
+	request, err := p.buildDeployRequest(p.newDeployRequest(filePath), builder)
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	shared := request.version != ""
+
+	if !shared {
+		request.version = NewLocalVersion(time.Now())
+	}
+
+	request.destinationFilePath = p.versions().versionPath(request.version, filepath.Base(filePath))
+	request.allowOverwrite = false
+	request.sidecarFilePaths = p.sidecarFilePaths(request.destinationFilePath)
+
+	return request, shared, nil
+}
+
+func (p *LocalProvider) Deploy(filePath string, builder func(req *LocalDeployRequest) error) (*LocalDeployResult, error) {
+	if p.Versioning != nil {
+		return p.deployVersion(filePath, builder)
+	}
+
+	request, err := p.buildDeployRequest(p.newDeployRequest(filePath), builder)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// compute this before moving the file
+	artifact, err := NewArtifact(filePath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var response LocalMoveResponse
+
+	if bytes, err := p.move(request.NewMoveRequest()); err != nil {
+		return nil, err
+	} else if err := json.Unmarshal(bytes, &response); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	if paths, err := p.writeSidecars(request, artifact); err != nil {
+		return nil, errors.Wrapf(err, "%s has been deployed but the sidecar files cannot be written", response.DestinationFilePath)
+	} else {
+		response.SidecarFilePaths = paths
+	}
+
+	// the page keeps listing the other files in the directory
+	if p.InstallPage != nil {
+		if page, err := p.installPage(filepath.Dir(response.DestinationFilePath), true).write(response.DestinationFilePath); err != nil {
+			return nil, errors.Wrapf(err, "%s has been deployed but the install page cannot be written", response.DestinationFilePath)
+		} else {
+			response.InstallPage = page
+		}
+	}
+
+	if bytes, err := json.Marshal(response); err != nil {
+		panic(err)
+	} else {
+		return &LocalDeployResult{
+			LocalMoveResponse: response,
+			Artifact:          *artifact,
+			RawJson:           string(bytes),
+		}, nil
+	}
+}
+
+func (p *LocalProvider) buildDeployRequest(request *LocalDeployRequest, builder func(req *LocalDeployRequest) error) (*LocalDeployRequest, error) {
+	if err := builder(request); err != nil {
+		return nil, errors.Wrapf(err, "could not build the request")
+	} else {
+		localLogger.Debug().Msgf("the request has been built: %v", *request)
+	}
+
+	return request, nil
+}
+
+func (p *LocalProvider) installPage(dir string, keep bool) *localInstallPage {
+	return &localInstallPage{
+		LocalInstallPageConfig: *p.InstallPage,
+		dir:                    dir,
+		keep:                   keep,
+	}
+}
+
+func (p *LocalProvider) deployVersion(filePath string, builder func(req *LocalDeployRequest) error) (*LocalDeployResult, error) {
+	request, shared, err := p.buildVersionedDeployRequest(filePath, builder)
+
+	if err != nil {
+		return nil, err
+	}
+
+	version := request.version
+
+	// compute this before moving the file
+	artifact, err := NewArtifact(filePath)
+
+	if err != nil {
+		return nil, err
+	}
+
 	var response LocalMoveResponse
 
 	if bytes, err := p.move(request.NewMoveRequest()); err != nil {
 		return nil, err
 	} else if err := json.Unmarshal(bytes, &response); err != nil {
 		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	response.Version = version
+
+	if paths, err := p.writeSidecars(request, artifact); err != nil {
+		return nil, errors.Wrapf(err, "version %s has been stored but the sidecar files cannot be written", version)
+	} else {
+		response.SidecarFilePaths = paths
+	}
+
+	// the caller publishes the shared version after all files are stored
+	if !shared {
+		if published, err := p.Publish(version); err != nil {
+			return nil, err
+		} else {
+			response.LatestFilePath = published.LatestFilePaths[0]
+			response.PrunedVersions = published.PrunedVersions
+			response.InstallPage = published.InstallPage
+		}
+	}
+
+	if bytes, err := json.Marshal(response); err != nil {
+		panic(err)
 	} else {
 		return &LocalDeployResult{
 			LocalMoveResponse: response,
+			Artifact:          *artifact,
 			RawJson:           string(bytes),
 		}, nil
 	}
 }
+
+type LocalPublishResult struct {
+	Version         string   `json:"version"`
+	LatestFilePaths []string `json:"latest_file_paths"`
+	PrunedVersions  []string `json:"pruned_versions,omitempty"`
+
+	// available only if the install page is enabled
+	InstallPage *LocalInstallPage `json:"install_page,omitempty"`
+}
+
+var _ DeployResult = &LocalPublishResult{}
+
+func (r *LocalPublishResult) RawJsonResponse() string {
+	if bytes, err := json.Marshal(r); err != nil {
+		panic(err)
+	} else {
+		return string(bytes)
+	}
+}
+
+func (r *LocalPublishResult) ValueResponse() any {
+	return *r
+}
+
+// Publish points latest to the version that has been stored, prunes the versions beyond the retention and writes the install page.
+func (p *LocalProvider) Publish(version string) (*LocalPublishResult, error) {
+	if p.Versioning == nil {
+		return nil, errors.New("publish is available only for versioning")
+	}
+
+	versions := p.versions()
+
+	latestPaths, err := versions.point(version)
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "version %s has been stored but latest cannot be updated", version)
+	}
+
+	result := &LocalPublishResult{
+		Version:         version,
+		LatestFilePaths: latestPaths,
+	}
+
+	if pruned, err := versions.prune(p.Versioning.Retention); err != nil {
+		return nil, err
+	} else {
+		result.PrunedVersions = pruned
+	}
+
+	// the page is put next to latest and refers to the version files that never change
+	if p.InstallPage != nil {
+		if filePaths, err := versions.filePaths(version); err != nil {
+			return nil, err
+		} else if page, err := p.installPage(p.DestinationPath, false).write(filePaths...); err != nil {
+			return nil, errors.Wrapf(err, "version %s has been stored but the install page cannot be written", version)
+		} else {
+			result.InstallPage = page
+		}
+	}
+
+	return result, nil
+}
+
+// DryRunPublish checks the publication in the same way as Publish but never touches any file. The version may not exist yet so the file names are required.
+func (p *LocalProvider) DryRunPublish(version string, fileNames []string) (*DryRunResult, error) {
+	if p.Versioning == nil {
+		return nil, errors.New("publish is available only for versioning")
+	}
+
+	versions := p.versions()
+
+	existing, err := versions.list()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var versionFilePaths []string
+
+	for _, fileName := range fileNames {
+		versionFilePaths = append(versionFilePaths, versions.versionPath(version, fileName))
+	}
+
+	var operations []string
+
+	for _, latestPath := range versions.latestPaths(versionFilePaths) {
+		operations = append(operations, fmt.Sprintf("point %s to version %s by %s", latestPath, version, versions.strategy))
+	}
+
+	if !slices.Contains(existing, version) {
+		existing = append(existing, version)
+	}
+
+	for _, v := range versions.prunable(existing, p.Versioning.Retention, version) {
+		operations = append(operations, fmt.Sprintf("prune version %s", v))
+	}
+
+	return &DryRunResult{
+		Operations: append(operations, p.planInstallPage(p.DestinationPath)...),
+	}, nil
+}
+
+// DryRun checks the request in the same way as Deploy but never touches any file.
+func (p *LocalProvider) DryRun(filePath string, builder func(req *LocalDeployRequest) error) (*DryRunResult, error) {
+	if p.Versioning != nil {
+		return p.dryRunVersion(filePath, builder)
+	}
+
+	deployRequest, err := p.buildDeployRequest(p.newDeployRequest(filePath), builder)
+
+	if err != nil {
+		return nil, err
+	}
+
+	request := deployRequest.NewMoveRequest()
+
+	if sideEffect, err := p.plan(request); err != nil {
+		return nil, err
+	} else {
+		operations := append(planParentDir(request), fmt.Sprintf("%s: %s -> %s (file mode %s)", sideEffect, request.sourceFilePath, request.destinationFilePath, request.fileMode.String()))
+		operations = append(operations, planSidecars(request)...)
+
+		return &DryRunResult{
+			Operations: append(operations, p.planInstallPage(filepath.Dir(request.destinationFilePath))...),
+		}, nil
+	}
+}
+
+func planParentDir(request *LocalMoveRequest) []string {
+	if dir := filepath.Dir(request.destinationFilePath); !fileExists(dir) {
+		return []string{
+			fmt.Sprintf("create %s (mode %s)", dir, request.dirMode.String()),
+		}
+	}
+
+	return nil
+}
+
+func (p *LocalProvider) planInstallPage(dir string) []string {
+	if p.InstallPage == nil {
+		return nil
+	}
+
+	return []string{
+		fmt.Sprintf("write the install page to %s (served at %s)", filepath.Join(dir, localInstallPageIndexFileName), p.InstallPage.BaseURL),
+	}
+}
+
+func (p *LocalProvider) dryRunVersion(filePath string, builder func(req *LocalDeployRequest) error) (*DryRunResult, error) {
+	deployRequest, shared, err := p.buildVersionedDeployRequest(filePath, builder)
+
+	if err != nil {
+		return nil, err
+	}
+
+	request := deployRequest.NewMoveRequest()
+
+	sideEffect, err := p.plan(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	operations := append(
+		planParentDir(request),
+		fmt.Sprintf("%s: %s -> %s (file mode %s)", sideEffect, request.sourceFilePath, request.destinationFilePath, request.fileMode.String()),
+	)
+
+	operations = append(operations, planSidecars(request)...)
+
+	if shared {
+		return &DryRunResult{
+			Operations: operations,
+		}, nil
+	}
+
+	if published, err := p.DryRunPublish(deployRequest.version, []string{filepath.Base(filePath)}); err != nil {
+		return nil, err
+	} else {
+		return &DryRunResult{
+			Operations: append(operations, published.Operations...),
+		}, nil
+	}
+}
+
+type LocalRollbackResult struct {
+	Version         string   `json:"version"`
+	PreviousVersion string   `json:"previous_version"`
+	LatestFilePaths []string `json:"latest_file_paths"`
+
+	// available only if the install page is enabled
+	InstallPage *LocalInstallPage `json:"install_page,omitempty"`
+}
+
+var _ DeployResult = &LocalRollbackResult{}
+
+func (r *LocalRollbackResult) RawJsonResponse() string {
+	if bytes, err := json.Marshal(r); err != nil {
+		panic(err)
+	} else {
+		return string(bytes)
+	}
+}
+
+func (r *LocalRollbackResult) ValueResponse() any {
+	return *r
+}
+
+// rollbackTarget returns the current version and the previous version of it.
+func (p *LocalProvider) rollbackTarget() (string, string, error) {
+	if p.Versioning == nil {
+		return "", "", errors.New("rollback is available only for versioning")
+	}
+
+	versions := p.versions()
+
+	current, err := versions.current()
+
+	if err != nil {
+		return "", "", err
+	} else if current == "" {
+		return "", "", errors.New(fmt.Sprintf("no version is deployed to %s", p.DestinationPath))
+	}
+
+	list, err := versions.list()
+
+	if err != nil {
+		return "", "", err
+	}
+
+	if idx := slices.Index(list, current); idx < 0 {
+		return "", "", errors.New(fmt.Sprintf("the latest version %s does not exist in %s", current, p.DestinationPath))
+	} else if idx == 0 {
+		return "", "", errors.New(fmt.Sprintf("the latest version %s is the oldest one in %s", current, p.DestinationPath))
+	} else {
+		return current, list[idx-1], nil
+	}
+}
+
+// Rollback points latest to the version before the current latest version.
+func (p *LocalProvider) Rollback() (*LocalRollbackResult, error) {
+	current, previous, err := p.rollbackTarget()
+
+	if err != nil {
+		return nil, err
+	}
+
+	versions := p.versions()
+
+	latestPaths, err := versions.point(previous)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LocalRollbackResult{
+		Version:         previous,
+		PreviousVersion: current,
+		LatestFilePaths: latestPaths,
+	}
+
+	if p.InstallPage != nil {
+		if filePaths, err := versions.filePaths(previous); err != nil {
+			return nil, err
+		} else if page, err := p.installPage(p.DestinationPath, false).write(filePaths...); err != nil {
+			return nil, errors.Wrapf(err, "latest has been rolled back to %s but the install page cannot be written", previous)
+		} else {
+			result.InstallPage = page
+		}
+	}
+
+	return result, nil
+}
+
+// DryRunRollback checks the rollback in the same way as Rollback but never touches any file.
+func (p *LocalProvider) DryRunRollback() (*DryRunResult, error) {
+	current, previous, err := p.rollbackTarget()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &DryRunResult{
+		Operations: append([]string{
+			fmt.Sprintf("point latest in %s to version %s from %s", p.DestinationPath, previous, current),
+		}, p.planInstallPage(p.DestinationPath)...),
+	}, nil
+}
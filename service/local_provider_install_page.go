@@ -0,0 +1,402 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/jmatsu/splitter/internal/appinfo"
+	"github.com/jmatsu/splitter/internal/config"
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+	"html/template"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	localInstallPageIndexFileName    = "index.html"
+	localInstallPageManifestFileName = "manifest.json"
+	localInstallPagePlistExt         = ".plist" // <file name>.plist
+)
+
+type LocalInstallPage struct {
+	URL              string            `json:"url"`
+	IndexFilePath    string            `json:"index_file_path"`
+	ManifestFilePath string            `json:"manifest_file_path"`
+	Apps             []LocalInstallApp `json:"apps"`
+}
+
+// LocalInstallApp is an app file that is listed in the install page.
+type LocalInstallApp struct {
+	FileName      string `json:"file_name"`
+	InstallURL    string `json:"install_url"`
+	PlistFilePath string `json:"plist_file_path,omitempty"` // available only for ipa files
+}
+
+// localInstallManifest is the content of manifest.json.
+type localInstallManifest struct {
+	Title     string                    `json:"title"`
+	UpdatedAt time.Time                 `json:"updated_at"`
+	Apps      []localInstallManifestApp `json:"apps"`
+}
+
+type localInstallManifestApp struct {
+	Title       string    `json:"title"`
+	FileName    string    `json:"file_name"`
+	URL         string    `json:"url"`
+	InstallURL  string    `json:"install_url"`
+	PlistURL    string    `json:"plist_url,omitempty"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	PackageName string    `json:"package_name,omitempty"`
+	VersionName string    `json:"version_name,omitempty"`
+	VersionCode string    `json:"version_code,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// localInstallPage writes the install page of app files into a directory that is served at the base URL.
+// The page lists the given files. If keep is true, the apps that the page already lists are kept as long as their files exist in the directory.
+type localInstallPage struct {
+	config.LocalInstallPageConfig
+	dir  string
+	keep bool
+}
+
+// resolveURL returns the URL of the file in the directory.
+func (p *localInstallPage) resolveURL(filePath string) (string, error) {
+	rel, err := filepath.Rel(p.dir, filePath)
+
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", errors.New(fmt.Sprintf("%s is not in %s", filePath, p.dir))
+	}
+
+	u, err := url.Parse(p.BaseURL)
+
+	if err != nil {
+		return "", errors.Wrapf(err, "%s is not a valid url", p.BaseURL)
+	}
+
+	u.Path = path.Join("/", u.Path, filepath.ToSlash(rel))
+
+	return u.String(), nil
+}
+
+func (p *localInstallPage) plistFilePath(fileName string) string {
+	return filepath.Join(p.dir, fileName+localInstallPagePlistExt)
+}
+
+// read returns the apps that manifest.json in the directory lists. A missing or broken manifest is regarded as empty.
+func (p *localInstallPage) read() []localInstallManifestApp {
+	bytes, err := os.ReadFile(filepath.Join(p.dir, localInstallPageManifestFileName))
+
+	if err != nil {
+		return nil
+	}
+
+	var manifest localInstallManifest
+
+	if err := json.Unmarshal(bytes, &manifest); err != nil {
+		localLogger.Debug().Err(err).Msgf("the existing manifest in %s is ignored", p.dir)
+		return nil
+	}
+
+	return manifest.Apps
+}
+
+// newApp reads the app file and generates its plist if the file is an ipa.
+func (p *localInstallPage) newApp(filePath string) (*localInstallManifestApp, error) {
+	artifact, err := NewArtifact(filePath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	isIpa := strings.EqualFold(filepath.Ext(filePath), ".ipa")
+
+	info, err := appinfo.Read(filePath)
+
+	if err != nil {
+		// itms-services requires the bundle identifier and the version
+		if isIpa {
+			return nil, errors.Wrap(err, "ipa files require the app metadata for the install page")
+		}
+
+		localLogger.Debug().Err(err).Msgf("the install page of %s does not contain the app metadata", filePath)
+		info = &appinfo.AppInfo{}
+	}
+
+	if isIpa && (info.PackageName == "" || info.VersionName == "") {
+		return nil, errors.New(fmt.Sprintf("the bundle identifier and the version of %s are required for the install page", filePath))
+	}
+
+	app := localInstallManifestApp{
+		Title:       info.PackageName,
+		FileName:    artifact.FileName,
+		Size:        artifact.Size,
+		SHA256:      artifact.SHA256,
+		PackageName: info.PackageName,
+		VersionName: info.VersionName,
+		VersionCode: info.VersionCode,
+		UpdatedAt:   time.Now().UTC(),
+	}
+
+	if app.Title == "" {
+		app.Title = artifact.FileName
+	}
+
+	if app.URL, err = p.resolveURL(filePath); err != nil {
+		return nil, err
+	}
+
+	app.InstallURL = app.URL
+
+	if isIpa {
+		plistFilePath := p.plistFilePath(app.FileName)
+
+		if app.PlistURL, err = p.resolveURL(plistFilePath); err != nil {
+			return nil, err
+		}
+
+		app.InstallURL = "itms-services://?action=download-manifest&url=" + url.QueryEscape(app.PlistURL)
+
+		if plist, err := renderInstallPlist(&app); err != nil {
+			return nil, err
+		} else if err := writeFileAtomically(plistFilePath, plist, 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	return &app, nil
+}
+
+// write generates the files of the install page. The files are replaced atomically.
+func (p *localInstallPage) write(filePaths ...string) (*LocalInstallPage, error) {
+	var apps []localInstallManifestApp
+
+	for _, filePath := range filePaths {
+		if app, err := p.newApp(filePath); err != nil {
+			return nil, err
+		} else {
+			apps = append(apps, *app)
+		}
+	}
+
+	manifest := localInstallManifest{
+		Title:     p.Title,
+		UpdatedAt: time.Now().UTC(),
+	}
+
+	if manifest.Title == "" && len(apps) > 0 {
+		manifest.Title = apps[0].Title
+	}
+
+	for _, existing := range p.read() {
+		if slices.IndexFunc(apps, func(app localInstallManifestApp) bool { return app.FileName == existing.FileName }) >= 0 {
+			continue
+		}
+
+		if p.keep && fileExists(filepath.Join(p.dir, existing.FileName)) {
+			apps = append(apps, existing)
+		} else if existing.PlistURL != "" {
+			_ = os.Remove(p.plistFilePath(existing.FileName))
+		}
+	}
+
+	slices.SortStableFunc(apps, func(a, b localInstallManifestApp) int {
+		return strings.Compare(a.FileName, b.FileName)
+	})
+
+	manifest.Apps = apps
+
+	result := &LocalInstallPage{
+		IndexFilePath:    filepath.Join(p.dir, localInstallPageIndexFileName),
+		ManifestFilePath: filepath.Join(p.dir, localInstallPageManifestFileName),
+	}
+
+	var err error
+
+	if result.URL, err = p.resolveURL(result.IndexFilePath); err != nil {
+		return nil, err
+	}
+
+	for _, app := range apps {
+		installApp := LocalInstallApp{
+			FileName:   app.FileName,
+			InstallURL: app.InstallURL,
+		}
+
+		if app.PlistURL != "" {
+			installApp.PlistFilePath = p.plistFilePath(app.FileName)
+		}
+
+		result.Apps = append(result.Apps, installApp)
+	}
+
+	if bytes, err := json.MarshalIndent(manifest, "", "  "); err != nil {
+		panic(err)
+	} else if err := writeFileAtomically(result.ManifestFilePath, append(bytes, '\n'), 0644); err != nil {
+		return nil, err
+	}
+
+	if html, err := renderInstallPage(&manifest); err != nil {
+		return nil, err
+	} else if err := writeFileAtomically(result.IndexFilePath, html, 0644); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// the values are escaped by html/template
+var localInstallPageTemplate = template.Must(template.New(localInstallPageIndexFileName).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{- range .Apps}}
+<h2>{{.Title}}</h2>
+<dl>
+{{- if .PackageName}}
+<dt>Package Name</dt><dd>{{.PackageName}}</dd>
+{{- end}}
+{{- if .VersionName}}
+<dt>Version</dt><dd>{{.VersionName}}{{if .VersionCode}} ({{.VersionCode}}){{end}}</dd>
+{{- end}}
+<dt>File Name</dt><dd>{{.FileName}}</dd>
+<dt>Size</dt><dd>{{.Size}} bytes</dd>
+<dt>SHA-256</dt><dd><code>{{.SHA256}}</code></dd>
+<dt>Updated At</dt><dd>{{.UpdatedAt.Format "2006-01-02T15:04:05Z07:00"}}</dd>
+</dl>
+<p><a href="{{.InstallURL}}">Install</a></p>
+{{- if .PlistURL}}
+<p><a href="{{.URL}}">Download</a></p>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
+
+func renderInstallPage(manifest *localInstallManifest) ([]byte, error) {
+	var b bytes.Buffer
+
+	// html/template rejects unknown schemes so itms-services must be trusted explicitly
+	type app struct {
+		localInstallManifestApp
+		InstallURL template.URL
+	}
+
+	data := struct {
+		*localInstallManifest
+		Apps []app
+	}{
+		localInstallManifest: manifest,
+	}
+
+	for _, a := range manifest.Apps {
+		data.Apps = append(data.Apps, app{
+			localInstallManifestApp: a,
+			InstallURL:              template.URL(a.InstallURL),
+		})
+	}
+
+	if err := localInstallPageTemplate.Execute(&b, data); err != nil {
+		return nil, errors.Wrap(err, "cannot render the install page")
+	}
+
+	return b.Bytes(), nil
+}
+
+// renderInstallPlist renders the manifest that itms-services downloads.
+func renderInstallPlist(app *localInstallManifestApp) ([]byte, error) {
+	escape := func(v string) (string, error) {
+		var b strings.Builder
+
+		if err := xml.EscapeText(&b, []byte(v)); err != nil {
+			return "", errors.Wrapf(err, "cannot escape %s", v)
+		}
+
+		return b.String(), nil
+	}
+
+	var values []any
+
+	for _, v := range []string{app.URL, app.PackageName, app.VersionName, app.Title} {
+		if escaped, err := escape(v); err != nil {
+			return nil, err
+		} else {
+			values = append(values, escaped)
+		}
+	}
+
+	return []byte(fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>items</key>
+	<array>
+		<dict>
+			<key>assets</key>
+			<array>
+				<dict>
+					<key>kind</key>
+					<string>software-package</string>
+					<key>url</key>
+					<string>%s</string>
+				</dict>
+			</array>
+			<key>metadata</key>
+			<dict>
+				<key>bundle-identifier</key>
+				<string>%s</string>
+				<key>bundle-version</key>
+				<string>%s</string>
+				<key>kind</key>
+				<string>software</string>
+				<key>title</key>
+				<string>%s</string>
+			</dict>
+		</dict>
+	</array>
+</dict>
+</plist>
+`, values...)), nil
+}
+
+// writeFileAtomically writes the data to a temp file in the same directory and renames it to the path.
+func writeFileAtomically(filePath string, data []byte, mode os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".splitter-*")
+
+	if err != nil {
+		return errors.Wrapf(err, "failed to create a temp file for %s", filePath)
+	}
+
+	_, err = tmp.Write(data)
+
+	if err == nil {
+		err = tmp.Chmod(mode)
+	}
+
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), filePath)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return errors.Wrapf(err, "failed to write %s", filePath)
+	}
+
+	return nil
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"github.com/jmatsu/splitter/internal/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testInfoPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>CFBundleIdentifier</key>
+	<string>com.example.app</string>
+	<key>CFBundleShortVersionString</key>
+	<string>1.2.3</string>
+	<key>CFBundleVersion</key>
+	<string>123</string>
+</dict>
+</plist>`
+
+func writeTestIpa(t *testing.T, path string, infoPlist string) {
+	t.Helper()
+
+	f, err := os.Create(path)
+
+	if err != nil {
+		t.Fatalf("failed to create %s: %v", path, err)
+	}
+
+	w := zip.NewWriter(f)
+
+	if fw, err := w.Create("Payload/App.app/Info.plist"); err != nil {
+		t.Fatalf("failed to create Info.plist: %v", err)
+	} else if _, err := fw.Write([]byte(infoPlist)); err != nil {
+		t.Fatalf("failed to write Info.plist: %v", err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close the zip: %v", err)
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatalf("failed to close %s: %v", path, err)
+	}
+}
+
+func Test_LocalProvider_Deploy_installPage(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		fileName   string
+		infoPlist  string
+		versioning bool
+
+		expectedErr        bool
+		expectedURL        string
+		expectedInstallURL string
+		expectedTitle      string
+	}{
+		"ipa": {
+			fileName:           "app.ipa",
+			infoPlist:          testInfoPlist,
+			expectedURL:        "https://example.com/dogfooding/app.ipa",
+			expectedInstallURL: "itms-services://?action=download-manifest&url=https%3A%2F%2Fexample.com%2Fdogfooding%2Fapp.ipa.plist",
+			expectedTitle:      "com.example.app",
+		},
+		"ipa without metadata": {
+			fileName:    "app.ipa",
+			infoPlist:   "broken",
+			expectedErr: true,
+		},
+		"apk without metadata": {
+			fileName:           "app.apk",
+			expectedURL:        "https://example.com/dogfooding/app.apk",
+			expectedInstallURL: "https://example.com/dogfooding/app.apk",
+			expectedTitle:      "app.apk",
+		},
+		"versioning": {
+			fileName:      "app.apk",
+			versioning:    true,
+			expectedTitle: "app.apk",
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			sourcePath := filepath.Join(t.TempDir(), c.fileName)
+			destinationDir := filepath.Join(t.TempDir(), "drive")
+
+			if c.infoPlist != "" {
+				writeTestIpa(t, sourcePath, c.infoPlist)
+			} else if err := os.WriteFile(sourcePath, []byte("hello"), 0644); err != nil {
+				t.Fatalf("failed to prepare a file: %v", err)
+			}
+
+			conf := &config.LocalConfig{
+				DestinationPath: filepath.Join(destinationDir, c.fileName),
+				InstallPage: &config.LocalInstallPageConfig{
+					BaseURL: "https://example.com/dogfooding/",
+				},
+			}
+
+			if c.versioning {
+				conf.DestinationPath = destinationDir
+				conf.Versioning = &config.LocalVersioningConfig{
+					Retention: 1,
+				}
+			}
+
+			result, err := NewLocalProvider(context.TODO(), conf).Deploy(sourcePath, func(req *LocalDeployRequest) error {
+				return nil
+			})
+
+			if c.expectedErr {
+				if err == nil {
+					t.Errorf("%s case is expected to fail", name)
+				}
+
+				return
+			} else if err != nil {
+				t.Fatalf("%s case is expected to succeed but %v", name, err)
+			}
+
+			page := result.InstallPage
+
+			if page == nil {
+				t.Fatalf("the install page is expected to be written")
+			}
+
+			if page.URL != "https://example.com/dogfooding/index.html" {
+				t.Errorf("the page url is wrong: %s", page.URL)
+			}
+
+			manifest := readTestInstallManifest(t, destinationDir)
+
+			if len(manifest.Apps) != 1 || len(page.Apps) != 1 {
+				t.Fatalf("only %s is expected to be listed but %v", c.fileName, manifest.Apps)
+			}
+
+			app := manifest.Apps[0]
+
+			if c.versioning {
+				// the page refers to the version file
+				c.expectedURL = "https://example.com/dogfooding/versions/" + result.Version + "/app.apk"
+				c.expectedInstallURL = c.expectedURL
+			}
+
+			if app.URL != c.expectedURL || app.InstallURL != c.expectedInstallURL || page.Apps[0].InstallURL != c.expectedInstallURL {
+				t.Errorf("%s and %s are expected but %v", c.expectedURL, c.expectedInstallURL, app)
+			}
+
+			if manifest.Title != c.expectedTitle || app.SHA256 != result.Artifact.SHA256 {
+				t.Errorf("the manifest is wrong: %v", manifest)
+			}
+
+			if html, err := os.ReadFile(page.IndexFilePath); err != nil {
+				t.Errorf("index.html is expected to exist but %v", err)
+			} else if !strings.Contains(string(html), `href="`+strings.ReplaceAll(c.expectedInstallURL, "&", "&amp;")+`"`) {
+				t.Errorf("index.html is expected to link to %s but %s", c.expectedInstallURL, html)
+			}
+
+			if strings.HasSuffix(c.fileName, ".ipa") {
+				if plist, err := os.ReadFile(filepath.Join(destinationDir, "app.ipa.plist")); err != nil {
+					t.Errorf("the plist is expected to exist but %v", err)
+				} else if !strings.Contains(string(plist), "<string>com.example.app</string>") || !strings.Contains(string(plist), "<string>"+c.expectedURL+"</string>") {
+					t.Errorf("the plist is wrong: %s", plist)
+				}
+			} else if page.Apps[0].PlistFilePath != "" {
+				t.Errorf("the plist is expected only for ipa files")
+			}
+		})
+	}
+}
+
+func Test_LocalProvider_Deploy_installPage_sameDirectory(t *testing.T) {
+	t.Parallel()
+
+	destinationDir := t.TempDir()
+
+	deploy := func(fileName string) *LocalDeployResult {
+		sourcePath := filepath.Join(t.TempDir(), fileName)
+
+		if err := os.WriteFile(sourcePath, []byte(fileName), 0644); err != nil {
+			t.Fatalf("failed to prepare a file: %v", err)
+		}
+
+		// the same as a destination path template like dogfooding/{{.FileName}}
+		conf := &config.LocalConfig{
+			DestinationPath: filepath.Join(destinationDir, fileName),
+			AllowOverwrite:  true,
+			InstallPage: &config.LocalInstallPageConfig{
+				BaseURL: "https://example.com/dogfooding/",
+			},
+		}
+
+		result, err := NewLocalProvider(context.TODO(), conf).Deploy(sourcePath, func(req *LocalDeployRequest) error {
+			return nil
+		})
+
+		if err != nil {
+			t.Fatalf("%s is expected to be deployed but %v", fileName, err)
+		}
+
+		return result
+	}
+
+	deploy("app.apk")
+	deploy("app.aab")
+	result := deploy("app.apk") // overwrite
+
+	// one page lists all files in the directory
+	if apps := result.InstallPage.Apps; len(apps) != 2 || apps[0].FileName != "app.aab" || apps[1].FileName != "app.apk" {
+		t.Errorf("both files are expected to be listed but %v", apps)
+	}
+
+	manifest := readTestInstallManifest(t, destinationDir)
+
+	if len(manifest.Apps) != 2 {
+		t.Fatalf("both files are expected to be in the manifest but %v", manifest.Apps)
+	}
+
+	if html, err := os.ReadFile(filepath.Join(destinationDir, localInstallPageIndexFileName)); err != nil {
+		t.Fatalf("index.html is expected to exist but %v", err)
+	} else if !strings.Contains(string(html), "https://example.com/dogfooding/app.aab") || !strings.Contains(string(html), "https://example.com/dogfooding/app.apk") {
+		t.Errorf("index.html is expected to link to both files but %s", html)
+	}
+
+	// a removed file is dropped from the page
+	if err := os.Remove(filepath.Join(destinationDir, "app.aab")); err != nil {
+		t.Fatalf("failed to remove a file: %v", err)
+	}
+
+	if apps := deploy("app.apk").InstallPage.Apps; len(apps) != 1 || apps[0].FileName != "app.apk" {
+		t.Errorf("only app.apk is expected to be listed but %v", apps)
+	}
+}
+
+func readTestInstallManifest(t *testing.T, dir string) localInstallManifest {
+	t.Helper()
+
+	var manifest localInstallManifest
+
+	if bytes, err := os.ReadFile(filepath.Join(dir, localInstallPageManifestFileName)); err != nil {
+		t.Fatalf("manifest.json is expected to exist but %v", err)
+	} else if err := json.Unmarshal(bytes, &manifest); err != nil {
+		t.Fatalf("manifest.json is expected to be valid but %v", err)
+	}
+
+	return manifest
+}
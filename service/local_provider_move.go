@@ -6,6 +6,8 @@ import (
 	"github.com/pkg/errors"
 	"io"
 	"os"
+	"path/filepath"
+	"syscall"
 )
 
 type sideEffect = string
@@ -22,69 +24,112 @@ type LocalMoveRequest struct {
 	destinationFilePath string
 	allowOverwrite      bool
 	fileMode            os.FileMode
+	dirMode             os.FileMode
 	deleteResource      bool
+	sidecarFilePaths    []string
 }
 
 type LocalMoveResponse struct {
 	SourceFilePath      string     `json:"source_file_path"`
 	DestinationFilePath string     `json:"destination_file_path"`
 	SideEffect          sideEffect `json:"side_effect"`
+
+	// available only for versioning
+	Version        string   `json:"version,omitempty"`
+	LatestFilePath string   `json:"latest_file_path,omitempty"`
+	PrunedVersions []string `json:"pruned_versions,omitempty"`
+
+	// available only if the sidecar files are enabled
+	SidecarFilePaths []string `json:"sidecar_file_paths,omitempty"`
+
+	// available only if the install page is enabled
+	InstallPage *LocalInstallPage `json:"install_page,omitempty"`
 }
 
-func (p *LocalProvider) move(request *LocalMoveRequest) ([]byte, error) {
-	sideEffect, err := func() (sideEffect, error) {
-		var sideEffect sideEffect
-
-		if _, err := os.Stat(request.sourceFilePath); err != nil {
-			return "", errors.New(fmt.Sprintf("%s does not exist", request.sourceFilePath))
-		} else if di, err := os.Stat(request.destinationFilePath); err == nil {
-			if !request.allowOverwrite {
-				return "", errors.New(fmt.Sprintf("%s exists but overwriting is disabled", request.destinationFilePath))
-			} else if di.IsDir() {
-				return "", errors.New(fmt.Sprintf("directory (%s) as a destination is not supported", request.destinationFilePath))
-			}
+// Check the request and return the side effect that the request will cause.
+func (p *LocalProvider) plan(request *LocalMoveRequest) (sideEffect, error) {
+	if _, err := os.Stat(request.sourceFilePath); err != nil {
+		return "", errors.New(fmt.Sprintf("%s does not exist", request.sourceFilePath))
+	}
 
-			if request.deleteResource {
-				sideEffect = localMoveAndOverwrite
-			} else {
-				sideEffect = localCopyAndOverwrite
-			}
+	// sidecar files follow the same rule as the destination file
+	for _, path := range request.sidecarFilePaths {
+		if di, err := os.Stat(path); err != nil {
+			continue
+		} else if !request.allowOverwrite {
+			return "", errors.New(fmt.Sprintf("%s exists but overwriting is disabled", path))
+		} else if di.IsDir() {
+			return "", errors.New(fmt.Sprintf("directory (%s) as a sidecar file is not supported", path))
+		}
+	}
+
+	if di, err := os.Stat(request.destinationFilePath); err == nil {
+		if !request.allowOverwrite {
+			return "", errors.New(fmt.Sprintf("%s exists but overwriting is disabled", request.destinationFilePath))
+		} else if di.IsDir() {
+			return "", errors.New(fmt.Sprintf("directory (%s) as a destination is not supported", request.destinationFilePath))
+		}
+
+		if request.deleteResource {
+			return localMoveAndOverwrite, nil
 		} else {
-			if request.deleteResource {
-				sideEffect = localMoveOnly
-			} else {
-				sideEffect = localCopyOnly
-			}
+			return localCopyAndOverwrite, nil
 		}
+	} else {
+		if request.deleteResource {
+			return localMoveOnly, nil
+		} else {
+			return localCopyOnly, nil
+		}
+	}
+}
+
+func (p *LocalProvider) move(request *LocalMoveRequest) ([]byte, error) {
+	sideEffect, err := func() (sideEffect, error) {
+		sideEffect, err := p.plan(request)
 
-		var renameFromPath = request.sourceFilePath
+		if err != nil {
+			return "", err
+		}
 
-		if !request.deleteResource {
-			var tmp *os.File
+		dir := filepath.Dir(request.destinationFilePath)
 
-			if v, err := os.CreateTemp("", "local-dest-*"); err != nil {
-				return "", errors.Wrap(err, "failed to create a temp file")
-			} else {
-				tmp = v
-				defer tmp.Close()
-				renameFromPath = tmp.Name()
+		if !fileExists(dir) {
+			if err := os.MkdirAll(dir, request.dirMode); err != nil {
+				return "", errors.Wrapf(err, "failed to create %s", dir)
 			}
 
-			src, err := os.Open(request.sourceFilePath)
+			localLogger.Debug().Msgf("%s has been created with permission %s", dir, request.dirMode.String())
+		}
 
-			if err != nil {
-				return "", errors.Wrapf(err, "failed to open %s", request.sourceFilePath)
+		if request.deleteResource {
+			if err := p.rename(request.sourceFilePath, request.destinationFilePath); err == nil {
+				return sideEffect, nil
+			} else if !errors.Is(err, syscall.EXDEV) {
+				return "", errors.Wrapf(err, "failed to rename %s to %s", request.sourceFilePath, request.destinationFilePath)
 			}
 
-			defer src.Close()
+			localLogger.Debug().Msgf("%s and %s are on different devices so fall back to copy and delete", request.sourceFilePath, dir)
+		}
 
-			if _, err := io.Copy(tmp, src); err != nil {
-				return "", errors.Wrapf(err, "failed to copy %s to %s", request.sourceFilePath, tmp.Name())
-			}
+		// a temp file in the same directory can always be renamed to the destination atomically
+		tmpPath, err := stageCopy(request.sourceFilePath, dir, request.fileMode)
+
+		if err != nil {
+			return "", err
 		}
 
-		if err := os.Rename(renameFromPath, request.destinationFilePath); err != nil {
-			return "", errors.Wrapf(err, "failed to rename %s to %s", renameFromPath, request.destinationFilePath)
+		if err := p.rename(tmpPath, request.destinationFilePath); err != nil {
+			_ = os.Remove(tmpPath)
+			return "", errors.Wrapf(err, "failed to rename %s to %s", tmpPath, request.destinationFilePath)
+		}
+
+		syncDir(dir)
+
+		if request.deleteResource {
+			if err := os.Remove(request.sourceFilePath); err != nil {
+				return "", errors.Wrapf(err, "%s has been copied but failed to be deleted", request.sourceFilePath)
+			}
 		}
 
 		return sideEffect, nil
@@ -116,3 +161,64 @@ func (p *LocalProvider) move(request *LocalMoveRequest) ([]byte, error) {
 		return bytes, nil
 	}
 }
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// stageCopy copies the source file to a temp file in the directory and flushes it to the disk.
+// The caller must rename or remove the returned temp file. Zero mode keeps the default mode of temp files.
+func stageCopy(sourceFilePath string, dir string, mode os.FileMode) (string, error) {
+	src, err := os.Open(sourceFilePath)
+
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to open %s", sourceFilePath)
+	}
+
+	//goland:noinspection GoUnhandledErrorResult
+	defer src.Close()
+
+	tmp, err := os.CreateTemp(dir, ".splitter-*")
+
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create a temp file in %s", dir)
+	}
+
+	err = func() error {
+		if _, err := io.Copy(tmp, src); err != nil {
+			return errors.Wrapf(err, "failed to copy %s to %s", sourceFilePath, tmp.Name())
+		}
+
+		if mode != 0 {
+			if err := tmp.Chmod(mode); err != nil {
+				return errors.Wrapf(err, "failed to change file mode of %s", tmp.Name())
+			}
+		}
+
+		if err := tmp.Sync(); err != nil {
+			return errors.Wrapf(err, "failed to flush %s", tmp.Name())
+		}
+
+		return nil
+	}()
+
+	if closeErr := tmp.Close(); err == nil && closeErr != nil {
+		err = errors.Wrapf(closeErr, "failed to close %s", tmp.Name())
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", err
+	}
+
+	return tmp.Name(), nil
+}
+
+// syncDir flushes the directory entries so that a rename survives a crash. This is best-effort because some platforms do not support it.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		_ = d.Close()
+	}
+}
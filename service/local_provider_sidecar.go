@@ -0,0 +1,104 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/jmatsu/splitter/internal/ci"
+	"github.com/pkg/errors"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	localChecksumSidecarExt = ".sha256"
+	localMetadataSidecarExt = ".json"
+)
+
+// LocalMetadata is the content of the metadata sidecar file.
+type LocalMetadata struct {
+	Artifact
+	Deployment          string       `json:"deployment,omitempty"`
+	SourceFilePath      string       `json:"source_file_path"`
+	DestinationFilePath string       `json:"destination_file_path"`
+	DeployedAt          time.Time    `json:"deployed_at"`
+	CI                  *ci.Metadata `json:"ci,omitempty"`
+}
+
+// isLocalSidecar returns true if the file name is a sidecar file of another file.
+func isLocalSidecar(fileName string) bool {
+	return strings.HasSuffix(fileName, localChecksumSidecarExt) || strings.HasSuffix(fileName, localMetadataSidecarExt)
+}
+
+// sidecarFilePaths returns the enabled sidecar files of the destination file.
+func (p *LocalProvider) sidecarFilePaths(destinationFilePath string) []string {
+	var paths []string
+
+	if p.ChecksumFile {
+		paths = append(paths, destinationFilePath+localChecksumSidecarExt)
+	}
+
+	if p.MetadataFile {
+		paths = append(paths, destinationFilePath+localMetadataSidecarExt)
+	}
+
+	return paths
+}
+
+func planSidecars(request *LocalMoveRequest) []string {
+	var operations []string
+
+	for _, path := range request.sidecarFilePaths {
+		operations = append(operations, fmt.Sprintf("write %s", path))
+	}
+
+	return operations
+}
+
+// writeSidecars writes the sidecar files next to the destination file. The file mode of the destination file is used.
+func (p *LocalProvider) writeSidecars(request *LocalDeployRequest, artifact *Artifact) ([]string, error) {
+	mode := request.fileMode.Perm()
+
+	if mode == 0 {
+		mode = 0644
+	}
+
+	for _, path := range request.sidecarFilePaths {
+		var data []byte
+
+		switch {
+		case strings.HasSuffix(path, localChecksumSidecarExt):
+			// the same format as sha256sum so `sha256sum -c` works in the directory
+			data = []byte(fmt.Sprintf("%s  %s\n", artifact.SHA256, filepath.Base(request.destinationFilePath)))
+		case strings.HasSuffix(path, localMetadataSidecarExt):
+			sourceFilePath, err := filepath.Abs(request.sourceFilePath)
+
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot resolve %s", request.sourceFilePath)
+			}
+
+			metadata := LocalMetadata{
+				Artifact:            *artifact,
+				Deployment:          request.deploymentName,
+				SourceFilePath:      sourceFilePath,
+				DestinationFilePath: request.destinationFilePath,
+				DeployedAt:          time.Now().UTC(),
+				CI:                  ci.Current(),
+			}
+
+			if bytes, err := json.MarshalIndent(metadata, "", "  "); err != nil {
+				panic(err)
+			} else {
+				data = append(bytes, '\n')
+			}
+		}
+
+		if err := writeFileAtomically(path, data, mode); err != nil {
+			return nil, err
+		}
+
+		localLogger.Debug().Msgf("%s has been written", path)
+	}
+
+	return request.sidecarFilePaths, nil
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/jmatsu/splitter/internal/config"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_LocalProvider_Deploy_sidecars(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		SidecarExists bool
+		Overwrite     bool
+		Versioning    bool
+
+		expectedErr bool
+	}{
+		"new files": {},
+		"existing sidecar without overwriting": {
+			SidecarExists: true,
+			expectedErr:   true,
+		},
+		"existing sidecar with overwriting": {
+			SidecarExists: true,
+			Overwrite:     true,
+		},
+		"versioning": {
+			Versioning: true,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			sourcePath := filepath.Join(t.TempDir(), "app.apk")
+			destinationDir := t.TempDir()
+
+			if err := os.WriteFile(sourcePath, []byte("hello"), 0600); err != nil {
+				t.Fatalf("failed to prepare a file: %v", err)
+			}
+
+			conf := &config.LocalConfig{
+				DestinationPath: filepath.Join(destinationDir, "app.apk"),
+				AllowOverwrite:  c.Overwrite,
+				ChecksumFile:    true,
+				MetadataFile:    true,
+			}
+
+			if c.Versioning {
+				conf.DestinationPath = destinationDir
+				conf.Versioning = &config.LocalVersioningConfig{
+					Retention: 1,
+				}
+			}
+
+			if c.SidecarExists {
+				if err := os.WriteFile(conf.DestinationPath+".sha256", []byte("stale"), 0644); err != nil {
+					t.Fatalf("failed to prepare a sidecar file: %v", err)
+				}
+			}
+
+			result, err := NewLocalProvider(context.TODO(), conf).Deploy(sourcePath, func(req *LocalDeployRequest) error {
+				req.SetDeploymentName("shared-drive")
+				return nil
+			})
+
+			if c.expectedErr {
+				if err == nil {
+					t.Errorf("%s case is expected to fail", name)
+				} else if _, err := os.Stat(conf.DestinationPath); err == nil {
+					t.Errorf("the destination file must not be created")
+				}
+
+				return
+			} else if err != nil {
+				t.Fatalf("%s case is expected to succeed but %v", name, err)
+			}
+
+			destinationFilePath := result.DestinationFilePath
+
+			if len(result.SidecarFilePaths) != 2 {
+				t.Errorf("2 sidecar files are expected but %v", result.SidecarFilePaths)
+			}
+
+			expectedChecksum := fmt.Sprintf("%s  app.apk\n", result.Artifact.SHA256)
+
+			if bytes, err := os.ReadFile(destinationFilePath + ".sha256"); err != nil || string(bytes) != expectedChecksum {
+				t.Errorf("%s is expected but %s, %v", expectedChecksum, bytes, err)
+			}
+
+			if info, err := os.Stat(destinationFilePath + ".sha256"); err != nil || info.Mode().Perm() != 0600 {
+				t.Errorf("the sidecar file is expected to have the same mode as the destination file but %v, %v", info, err)
+			}
+
+			var metadata LocalMetadata
+
+			if bytes, err := os.ReadFile(destinationFilePath + ".json"); err != nil {
+				t.Fatalf("the metadata file is expected to exist but %v", err)
+			} else if err := json.Unmarshal(bytes, &metadata); err != nil {
+				t.Fatalf("the metadata file is expected to be valid but %v", err)
+			}
+
+			if metadata.Deployment != "shared-drive" || metadata.SHA256 != result.Artifact.SHA256 || metadata.Size != 5 || metadata.SourceFilePath != sourcePath || metadata.DeployedAt.IsZero() {
+				t.Errorf("the metadata is wrong: %v", metadata)
+			}
+
+			if c.Versioning {
+				if actual, err := (&localVersions{dir: destinationDir}).filePaths(result.Version); err != nil || len(actual) != 1 || actual[0] != destinationFilePath {
+					t.Errorf("sidecar files must not be treated as the file of the version: %s, %v", actual, err)
+				}
+			}
+		})
+	}
+}
@@ -2,10 +2,14 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"github.com/jmatsu/splitter/internal/config"
 	"os"
+	"path/filepath"
 	"strings"
+	"syscall"
 	"testing"
+	"time"
 )
 
 func Test_LocalProvider_Distribute(t *testing.T) {
@@ -133,7 +137,9 @@ func Test_LocalProvider_Distribute(t *testing.T) {
 				DestinationPath: dest.Name(),
 			})
 
-			response, err := provider.Deploy(source.Name())
+			response, err := provider.Deploy(source.Name(), func(req *LocalDeployRequest) error {
+				return nil
+			})
 
 			if err != nil {
 				if !c.Overwrite && strings.Contains(err.Error(), "overwriting is disabled") {
@@ -178,3 +184,140 @@ func Test_LocalProvider_Distribute(t *testing.T) {
 		})
 	}
 }
+
+func Test_LocalProvider_DryRun(t *testing.T) {
+	t.Parallel()
+
+	source, _ := os.CreateTemp("", "source-*")
+	defer source.Close()
+
+	dest := filepath.Join(os.TempDir(), fmt.Sprintf("dest-%d", time.Now().UnixNano()))
+
+	provider := NewLocalProvider(context.TODO(), &config.LocalConfig{
+		DestinationPath: dest,
+		DeleteSource:    true,
+	})
+
+	result, err := provider.DryRun(source.Name(), func(req *LocalDeployRequest) error {
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("failed to dry-run: %v", err)
+	}
+
+	if len(result.Operations) != 1 || !strings.HasPrefix(result.Operations[0], localMoveOnly) {
+		t.Errorf("a move operation is expected but %v", result.Operations)
+	}
+
+	if _, err := os.Stat(source.Name()); err != nil {
+		t.Errorf("the source file must be kept: %v", err)
+	}
+
+	if _, err := os.Stat(dest); err == nil {
+		t.Errorf("the destination file must not be created")
+	}
+}
+
+func Test_LocalProvider_Deploy_parentDirectories(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	sourcePath := filepath.Join(dir, "app.apk")
+	destinationPath := filepath.Join(dir, "nested", "dirs", "app.apk")
+
+	if err := os.WriteFile(sourcePath, []byte("hello"), 0644); err != nil {
+		t.Fatalf("failed to prepare a file: %v", err)
+	}
+
+	provider := NewLocalProvider(context.TODO(), &config.LocalConfig{
+		DestinationPath: destinationPath,
+		DirMode:         0700,
+	})
+
+	result, err := provider.Deploy(sourcePath, func(req *LocalDeployRequest) error {
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("the deployment is expected to succeed but %v", err)
+	}
+
+	if result.DestinationFilePath != destinationPath {
+		t.Errorf("%s is expected but %s", destinationPath, result.DestinationFilePath)
+	}
+
+	if info, err := os.Stat(filepath.Dir(destinationPath)); err != nil {
+		t.Errorf("the parent directory is expected to be created but %v", err)
+	} else if info.Mode().Perm() != 0700 {
+		t.Errorf("the parent directory is expected to have 0700 but %s", info.Mode().Perm())
+	}
+}
+
+func Test_LocalProvider_Deploy_crossDevice(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		DeleteSource bool
+	}{
+		"move": {
+			DeleteSource: true,
+		},
+		"copy": {
+			DeleteSource: false,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			sourcePath := filepath.Join(t.TempDir(), "app.apk")
+			destinationDir := t.TempDir()
+			destinationPath := filepath.Join(destinationDir, "app.apk")
+
+			if err := os.WriteFile(sourcePath, []byte("hello"), 0644); err != nil {
+				t.Fatalf("failed to prepare a file: %v", err)
+			}
+
+			provider := NewLocalProvider(context.TODO(), &config.LocalConfig{
+				DestinationPath: destinationPath,
+				DeleteSource:    c.DeleteSource,
+				FileMode:        0600,
+			})
+
+			// the source file cannot be renamed as if it's on another device
+			provider.rename = func(oldpath, newpath string) error {
+				if oldpath == sourcePath {
+					return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EXDEV}
+				}
+
+				return os.Rename(oldpath, newpath)
+			}
+
+			if _, err := provider.Deploy(sourcePath, func(req *LocalDeployRequest) error {
+				return nil
+			}); err != nil {
+				t.Fatalf("%s case is expected to succeed but %v", name, err)
+			}
+
+			if _, err := os.Stat(sourcePath); c.DeleteSource != os.IsNotExist(err) {
+				t.Errorf("the source file is expected to be deleted: %t but %v", c.DeleteSource, err)
+			}
+
+			if bytes, err := os.ReadFile(destinationPath); err != nil || string(bytes) != "hello" {
+				t.Errorf("the destination file is expected to be the same as the source but %s, %v", bytes, err)
+			}
+
+			if info, err := os.Stat(destinationPath); err != nil || info.Mode().Perm() != 0600 {
+				t.Errorf("the destination file is expected to have 0600 but %v, %v", info, err)
+			}
+
+			if entries, err := os.ReadDir(destinationDir); err != nil || len(entries) != 1 {
+				t.Errorf("temp files are expected to be cleaned up but %v, %v", entries, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"fmt"
+	"github.com/jmatsu/splitter/internal/config"
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	localVersionsDirName     = "versions"
+	localLatestName          = "latest"
+	localLatestStateFileName = ".latest" // contains the version that latest points to
+	localVersionLayout       = "20060102-150405.000000000"
+)
+
+// localVersions manages the following layout in a directory. A version contains the files that are deployed at once.
+//
+//	<dir>/versions/<version>/<file name>
+//	<dir>/latest<ext>          (a symlink or a copy of the file if the latest version has only one file)
+//	<dir>/latest-<file name>   (a symlink or a copy of each file if the latest version has several files)
+//	<dir>/.latest              (the version of latest)
+type localVersions struct {
+	dir      string
+	strategy config.LocalLatestStrategy
+}
+
+// NewLocalVersion returns a version at the time. Pass the same version to LocalDeployRequest.SetVersion to deploy several files as one version.
+func NewLocalVersion(t time.Time) string {
+	return t.UTC().Format(localVersionLayout)
+}
+
+func (v *localVersions) versionPath(version string, fileName string) string {
+	return filepath.Join(v.dir, localVersionsDirName, version, fileName)
+}
+
+// latestPaths returns the paths of latest for the files of a version.
+func (v *localVersions) latestPaths(versionFilePaths []string) []string {
+	if len(versionFilePaths) == 1 {
+		return []string{filepath.Join(v.dir, localLatestName+filepath.Ext(versionFilePaths[0]))}
+	}
+
+	var paths []string
+
+	for _, path := range versionFilePaths {
+		paths = append(paths, filepath.Join(v.dir, fmt.Sprintf("%s-%s", localLatestName, filepath.Base(path))))
+	}
+
+	return paths
+}
+
+// list returns the existing versions from the oldest.
+func (v *localVersions) list() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(v.dir, localVersionsDirName))
+
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "cannot read versions in %s", v.dir)
+	}
+
+	versions := []string{}
+
+	for _, e := range entries {
+		if e.IsDir() {
+			versions = append(versions, e.Name())
+		}
+	}
+
+	slices.Sort(versions)
+
+	return versions, nil
+}
+
+// current returns the version that latest points to. An empty string is returned if latest does not exist.
+func (v *localVersions) current() (string, error) {
+	bytes, err := os.ReadFile(filepath.Join(v.dir, localLatestStateFileName))
+
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", errors.Wrapf(err, "cannot read the latest version in %s", v.dir)
+	}
+
+	return strings.TrimSpace(string(bytes)), nil
+}
+
+// filePaths returns the files of the version except the sidecar files.
+func (v *localVersions) filePaths(version string) ([]string, error) {
+	dir := filepath.Join(v.dir, localVersionsDirName, version)
+
+	entries, err := os.ReadDir(dir)
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "version %s is not found", version)
+	}
+
+	var paths []string
+
+	for _, e := range entries {
+		if !e.IsDir() && !isLocalSidecar(e.Name()) {
+			paths = append(paths, filepath.Join(dir, e.Name()))
+		}
+	}
+
+	if len(paths) == 0 {
+		return nil, errors.New(fmt.Sprintf("version %s has no file", version))
+	}
+
+	return paths, nil
+}
+
+// point replaces latest with the files of the version and returns the paths of latest.
+func (v *localVersions) point(version string) ([]string, error) {
+	versionFilePaths, err := v.filePaths(version)
+
+	if err != nil {
+		return nil, err
+	}
+
+	latestPaths := v.latestPaths(versionFilePaths)
+
+	for idx, versionFilePath := range versionFilePaths {
+		if err := v.replaceLatest(versionFilePath, latestPaths[idx]); err != nil {
+			return nil, err
+		}
+	}
+
+	// remove latest of the previous version that the version does not have
+	if previous, err := v.current(); err == nil && previous != "" && previous != version {
+		if previousFilePaths, err := v.filePaths(previous); err == nil {
+			for _, p := range v.latestPaths(previousFilePaths) {
+				if !slices.Contains(latestPaths, p) {
+					_ = os.Remove(p)
+				}
+			}
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(v.dir, localLatestStateFileName), []byte(version+"\n"), 0644); err != nil {
+		return nil, errors.Wrapf(err, "failed to record the latest version in %s", v.dir)
+	}
+
+	return latestPaths, nil
+}
+
+func (v *localVersions) replaceLatest(versionFilePath string, latestPath string) error {
+	var tmpPath string
+
+	switch v.strategy {
+	case config.LocalLatestCopy:
+		info, err := os.Stat(versionFilePath)
+
+		if err != nil {
+			return errors.Wrapf(err, "failed to stat %s", versionFilePath)
+		}
+
+		if tmpPath, err = stageCopy(versionFilePath, v.dir, info.Mode().Perm()); err != nil {
+			return err
+		}
+	default:
+		tmpPath = filepath.Join(v.dir, fmt.Sprintf(".%s-%d", filepath.Base(latestPath), time.Now().UnixNano()))
+
+		target, err := filepath.Rel(v.dir, versionFilePath)
+
+		if err != nil {
+			return errors.Wrapf(err, "cannot resolve %s from %s", versionFilePath, v.dir)
+		}
+
+		if err := os.Symlink(target, tmpPath); err != nil {
+			return errors.Wrapf(err, "failed to create a symlink to %s", target)
+		}
+	}
+
+	// rename is atomic so readers never see a missing latest
+	if err := os.Rename(tmpPath, latestPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return errors.Wrapf(err, "failed to replace %s", latestPath)
+	}
+
+	return nil
+}
+
+// prunable returns the versions that exceed the retention from the oldest. The version of latest is never pruned.
+func (v *localVersions) prunable(versions []string, retention int, latest string) []string {
+	var pruned []string
+
+	for idx := 0; idx < len(versions)-retention; idx++ {
+		if versions[idx] != latest {
+			pruned = append(pruned, versions[idx])
+		}
+	}
+
+	return pruned
+}
+
+// prune deletes the versions that exceed the retention.
+func (v *localVersions) prune(retention int) ([]string, error) {
+	versions, err := v.list()
+
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := v.current()
+
+	if err != nil {
+		return nil, err
+	}
+
+	pruned := v.prunable(versions, retention, latest)
+
+	for _, version := range pruned {
+		if err := os.RemoveAll(filepath.Join(v.dir, localVersionsDirName, version)); err != nil {
+			return nil, errors.Wrapf(err, "failed to prune version %s", version)
+		}
+
+		localLogger.Debug().Msgf("version %s has been pruned", version)
+	}
+
+	return pruned, nil
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmatsu/splitter/internal/config"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_LocalProvider_Versioning(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		strategy config.LocalLatestStrategy
+	}{
+		"symlink": {
+			strategy: config.LocalLatestSymlink,
+		},
+		"copy": {
+			strategy: config.LocalLatestCopy,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			sourcePath := filepath.Join(dir, "app.apk")
+			destinationPath := filepath.Join(dir, "drive")
+
+			provider := NewLocalProvider(context.TODO(), &config.LocalConfig{
+				DestinationPath: destinationPath,
+				Versioning: &config.LocalVersioningConfig{
+					Retention: 2,
+					Latest:    c.strategy,
+				},
+			})
+
+			var versions []string
+
+			for i := 1; i <= 3; i++ {
+				if err := os.WriteFile(sourcePath, []byte(fmt.Sprintf("v%d", i)), 0644); err != nil {
+					t.Fatalf("failed to prepare a file: %v", err)
+				}
+
+				result, err := provider.Deploy(sourcePath, func(req *LocalDeployRequest) error {
+					return nil
+				})
+
+				if err != nil {
+					t.Fatalf("deployment %d is expected to succeed but %v", i, err)
+				}
+
+				versions = append(versions, result.Version)
+
+				if actual, _ := os.ReadFile(result.LatestFilePath); string(actual) != fmt.Sprintf("v%d", i) {
+					t.Errorf("latest is expected to be v%d but %s", i, actual)
+				}
+
+				// versions have the precision of nanoseconds but make sure they are different
+				time.Sleep(time.Millisecond)
+			}
+
+			stored, _ := (&localVersions{dir: destinationPath}).list()
+
+			if expected := versions[1:]; !reflect.DeepEqual(expected, stored) {
+				t.Errorf("%v is expected to be kept but %v", expected, stored)
+			}
+
+			result, err := provider.Rollback()
+
+			if err != nil {
+				t.Fatalf("rollback is expected to succeed but %v", err)
+			}
+
+			if result.Version != versions[1] || result.PreviousVersion != versions[2] {
+				t.Errorf("rollback from %s to %s is expected but %v", versions[2], versions[1], *result)
+			}
+
+			if actual, _ := os.ReadFile(result.LatestFilePaths[0]); len(result.LatestFilePaths) != 1 || string(actual) != "v2" {
+				t.Errorf("latest is expected to be v2 but %s", actual)
+			}
+
+			if _, err := provider.Rollback(); err == nil {
+				t.Errorf("rollback from the oldest version is expected to fail")
+			}
+		})
+	}
+}
+
+func Test_LocalProvider_Versioning_severalFiles(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		strategy config.LocalLatestStrategy
+	}{
+		"symlink": {
+			strategy: config.LocalLatestSymlink,
+		},
+		"copy": {
+			strategy: config.LocalLatestCopy,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			destinationPath := filepath.Join(dir, "drive")
+
+			provider := NewLocalProvider(context.TODO(), &config.LocalConfig{
+				DestinationPath: destinationPath,
+				Versioning: &config.LocalVersioningConfig{
+					Retention: 2,
+					Latest:    c.strategy,
+				},
+			})
+
+			// deploys the files as one version in the same way as deploy command
+			deploy := func(i int, fileNames ...string) (string, []string) {
+				version := NewLocalVersion(time.Now())
+
+				for _, fileName := range fileNames {
+					sourcePath := filepath.Join(dir, fileName)
+
+					if err := os.WriteFile(sourcePath, []byte(fmt.Sprintf("v%d %s", i, fileName)), 0644); err != nil {
+						t.Fatalf("failed to prepare a file: %v", err)
+					}
+
+					if result, err := provider.Deploy(sourcePath, func(req *LocalDeployRequest) error {
+						req.SetVersion(version)
+						return nil
+					}); err != nil {
+						t.Fatalf("%s of deployment %d is expected to succeed but %v", fileName, i, err)
+					} else if result.Version != version || result.LatestFilePath != "" {
+						t.Errorf("%s is expected to be stored in %s without updating latest but %v", fileName, version, result.LocalMoveResponse)
+					}
+				}
+
+				result, err := provider.Publish(version)
+
+				if err != nil {
+					t.Fatalf("deployment %d is expected to be published but %v", i, err)
+				}
+
+				// versions have the precision of nanoseconds but make sure they are different
+				time.Sleep(time.Millisecond)
+
+				return version, result.LatestFilePaths
+			}
+
+			assertLatest := func(latestPaths []string, i int, fileNames ...string) {
+				t.Helper()
+
+				if len(latestPaths) != len(fileNames) {
+					t.Fatalf("%d latest files are expected but %v", len(fileNames), latestPaths)
+				}
+
+				for idx, fileName := range fileNames {
+					if actual, _ := os.ReadFile(latestPaths[idx]); string(actual) != fmt.Sprintf("v%d %s", i, fileName) {
+						t.Errorf("%s is expected to be v%d of %s but %s", latestPaths[idx], i, fileName, actual)
+					}
+				}
+			}
+
+			v1, latestPaths := deploy(1, "app.apk")
+			assertLatest(latestPaths, 1, "app.apk")
+
+			v2, latestPaths := deploy(2, "app-arm64.apk", "app-x86.apk")
+			assertLatest(latestPaths, 2, "app-arm64.apk", "app-x86.apk")
+
+			if _, err := os.Lstat(filepath.Join(destinationPath, "latest.apk")); !os.IsNotExist(err) {
+				t.Errorf("latest of the previous version is expected to be removed but %v", err)
+			}
+
+			v3, latestPaths := deploy(3, "app-arm64.apk", "app-x86.apk")
+			assertLatest(latestPaths, 3, "app-arm64.apk", "app-x86.apk")
+
+			// the retention counts deployments, not files
+			if stored, _ := (&localVersions{dir: destinationPath}).list(); !reflect.DeepEqual([]string{v2, v3}, stored) {
+				t.Errorf("%s is expected to be pruned but %v", v1, stored)
+			}
+
+			result, err := provider.Rollback()
+
+			if err != nil {
+				t.Fatalf("rollback is expected to succeed but %v", err)
+			}
+
+			if result.Version != v2 || result.PreviousVersion != v3 {
+				t.Errorf("rollback from %s to %s is expected but %v", v3, v2, *result)
+			}
+
+			assertLatest(result.LatestFilePaths, 2, "app-arm64.apk", "app-x86.apk")
+		})
+	}
+}
+
+func Test_localVersions_prunable(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		versions  []string
+		retention int
+		latest    string
+
+		expected []string
+	}{
+		"within retention": {
+			versions:  []string{"1", "2"},
+			retention: 2,
+			latest:    "2",
+			expected:  nil,
+		},
+		"over retention": {
+			versions:  []string{"1", "2", "3", "4"},
+			retention: 2,
+			latest:    "4",
+			expected:  []string{"1", "2"},
+		},
+		"latest is never pruned": {
+			versions:  []string{"1", "2", "3"},
+			retention: 1,
+			latest:    "1",
+			expected:  []string{"2"},
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if actual := (&localVersions{}).prunable(c.versions, c.retention, c.latest); !reflect.DeepEqual(c.expected, actual) {
+				t.Errorf("%v is expected but %v", c.expected, actual)
+			}
+		})
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"github.com/jmatsu/splitter/internal/config"
+	"github.com/jmatsu/splitter/internal/exec"
 	"github.com/jmatsu/splitter/internal/logger"
+	"github.com/jmatsu/splitter/internal/secret"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
+	"strings"
 )
 
 var testFlightLogger zerolog.Logger
@@ -49,20 +52,27 @@ func (r *TestFlightDeployRequest) NewUploadAppRequest() *TestFlightUploadAppRequ
 
 type TestFlightDeployResult struct {
 	TestFlightUploadAppResponse
-	RawJson string
+	Artifact Artifact
+	RawJson  string
 }
 
 var _ DeployResult = &TestFlightDeployResult{}
 
 func (r *TestFlightDeployResult) RawJsonResponse() string {
-	return r.RawJson
+	return wrapRawJson(r.Artifact, r.RawJson)
 }
 
 func (r *TestFlightDeployResult) ValueResponse() any {
 	return *r
 }
 
-func (p *TestFlightProvider) Deploy(filePath string, builder func(req *TestFlightDeployRequest) error) (*TestFlightDeployResult, error) {
+func (r *TestFlightDeployResult) Summary() DeploySummary {
+	return DeploySummary{
+		Artifact: r.Artifact,
+	}
+}
+
+func (p *TestFlightProvider) newDeployRequest(filePath string, builder func(req *TestFlightDeployRequest) error) (*TestFlightDeployRequest, error) {
 	request := &TestFlightDeployRequest{
 		filePath: filePath,
 		appleID:  p.AppleID,
@@ -77,6 +87,22 @@ func (p *TestFlightProvider) Deploy(filePath string, builder func(req *TestFligh
 		testFlightLogger.Debug().Msgf("the request has been built: %v", *request)
 	}
 
+	return request, nil
+}
+
+func (p *TestFlightProvider) Deploy(filePath string, builder func(req *TestFlightDeployRequest) error) (*TestFlightDeployResult, error) {
+	request, err := p.newDeployRequest(filePath, builder)
+
+	if err != nil {
+		return nil, err
+	}
+
+	artifact, err := NewArtifact(filePath)
+
+	if err != nil {
+		return nil, err
+	}
+
 	var response TestFlightUploadAppResponse
 
 	if bytes, err := p.uploadApp(request.NewUploadAppRequest()); err != nil {
@@ -86,7 +112,26 @@ func (p *TestFlightProvider) Deploy(filePath string, builder func(req *TestFligh
 	} else {
 		return &TestFlightDeployResult{
 			TestFlightUploadAppResponse: response,
+			Artifact:                    *artifact,
 			RawJson:                     string(bytes),
 		}, nil
 	}
 }
+
+// DryRun builds the command line in the same way as Deploy but never executes it.
+func (p *TestFlightProvider) DryRun(filePath string, builder func(req *TestFlightDeployRequest) error) (*DryRunResult, error) {
+	request, err := p.newDeployRequest(filePath, builder)
+
+	if err != nil {
+		return nil, err
+	}
+
+	upload := request.NewUploadAppRequest()
+	command := exec.NewAltool(p.ctx).UploadAppCommand(upload.filePath, upload.appleID, upload.NewAltoolCredential())
+
+	return &DryRunResult{
+		Operations: []string{
+			secret.MaskValues(strings.Join(command, " "), upload.password, upload.apiKey),
+		},
+	}, nil
+}
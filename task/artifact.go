@@ -0,0 +1,20 @@
+package task
+
+import (
+	"fmt"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jmatsu/splitter/service"
+)
+
+func appendArtifactRows(w table.Writer, artifact service.Artifact) {
+	w.AppendSeparator()
+	w.AppendRows([]table.Row{
+		{"Artifact Property", ""},
+	})
+	w.AppendSeparator()
+	w.AppendRows([]table.Row{
+		{"File Name", artifact.FileName},
+		{"Size", fmt.Sprintf("%d bytes", artifact.Size)},
+		{"SHA-256", artifact.SHA256},
+	})
+}
@@ -0,0 +1,76 @@
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jmatsu/splitter/internal/config"
+	"github.com/jmatsu/splitter/service"
+)
+
+// ResolvedConfigResult is the resolved values of the config file to be rendered.
+type ResolvedConfigResult struct {
+	Profile string                 `json:"profile,omitempty"`
+	Values  []config.ResolvedValue `json:"values"`
+}
+
+var _ service.DeployResult = &ResolvedConfigResult{}
+
+func (r *ResolvedConfigResult) RawJsonResponse() string {
+	if bytes, err := json.Marshal(r); err != nil {
+		panic(err)
+	} else {
+		return string(bytes)
+	}
+}
+
+func (r *ResolvedConfigResult) ValueResponse() any {
+	return *r
+}
+
+// FormatResolvedConfig renders the resolved values of the deployments in the current format style. Every deployment is rendered if no name is given.
+func FormatResolvedConfig(names []string) error {
+	values, err := config.CurrentConfig().ResolveValues(names)
+
+	if err != nil {
+		return err
+	}
+
+	formatter := NewFormatter()
+	formatter.TableBuilder = resolvedConfigTableBuilder
+
+	return formatter.Format(&ResolvedConfigResult{
+		Profile: config.CurrentConfig().Profile(),
+		Values:  values,
+	})
+}
+
+var resolvedConfigTableBuilder = func(w table.Writer, v any) {
+	result := v.(ResolvedConfigResult)
+
+	if result.Profile != "" {
+		w.SetTitle(fmt.Sprintf("Profile: %s", result.Profile))
+	}
+
+	w.AppendHeader(table.Row{
+		"Key", "Value", "Source", "Reference",
+	})
+
+	for _, value := range result.Values {
+		source := value.Source
+
+		if value.Origin != "" {
+			source = fmt.Sprintf("%s (%s)", value.Source, value.Origin)
+		}
+
+		resolved := value.Value
+
+		if value.Error != "" {
+			resolved = fmt.Sprintf("error: %s", value.Error)
+		}
+
+		w.AppendRow(table.Row{
+			value.Key, resolved, source, value.Reference,
+		})
+	}
+}
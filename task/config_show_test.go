@@ -0,0 +1,54 @@
+package task
+
+import (
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jmatsu/splitter/internal/config"
+	"testing"
+)
+
+func Test_resolvedConfigTableBuilder(t *testing.T) {
+	cases := map[string]struct {
+		result ResolvedConfigResult
+	}{
+		"zero": {
+			result: ResolvedConfigResult{},
+		},
+		"regular": {
+			result: ResolvedConfigResult{
+				Profile: "staging",
+				Values: []config.ResolvedValue{
+					{
+						Key:    "format-style",
+						Value:  "pretty",
+						Source: config.DefaultSource,
+					},
+					{
+						Key:       "deployments.dogfooding.api-token",
+						Value:     "****",
+						Source:    config.FileSource,
+						Origin:    "/path/to/splitter.yml",
+						Reference: "env:TOKEN",
+					},
+					{
+						Key:    "deployments.dogfooding.app-owner-name",
+						Value:  "format:${OWNER}",
+						Source: config.FileSource,
+						Origin: "/path/to/splitter.yml",
+						Error:  "OWNER is not set",
+					},
+				},
+			},
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			w := table.NewWriter()
+
+			// no panic is ok
+			resolvedConfigTableBuilder(w, c.result)
+		})
+	}
+}
@@ -2,26 +2,50 @@ package task
 
 import (
 	"context"
+	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/jmatsu/splitter/internal/config"
 	"github.com/jmatsu/splitter/service"
 	"github.com/pkg/errors"
 )
 
-func DeployToCustomService(ctx context.Context, def config.CustomServiceDefinition, conf config.CustomServiceConfig, filePath string, builder func(req *service.CustomServiceDeployRequest) error) error {
+func DeployToCustomService(ctx context.Context, def config.CustomServiceDefinition, conf config.CustomServiceConfig, filePath string, builder func(req *service.CustomServiceDeployRequest) error) (*service.DeploySummary, error) {
 	if err := conf.Validate(); err != nil {
-		return errors.Wrap(err, "the built config is invalid")
+		return nil, errors.Wrap(err, "the built config is invalid")
 	}
 
 	provider := service.NewCustomServiceProvider(ctx, &def, &conf)
 
+	if config.CurrentConfig().DryRun() {
+		if result, err := provider.DryRun(filePath, builder); err != nil {
+			return nil, errors.Wrap(err, "cannot build requests for this app")
+		} else {
+			return nil, formatDryRun(result)
+		}
+	}
+
 	formatter := NewFormatter()
-	formatter.TableBuilder = nil
+	formatter.TableBuilder = customServiceTableBuilder
 
-	if response, err := provider.Deploy(filePath, builder); err != nil {
-		return errors.Wrap(err, "cannot deploy this app")
+	response, err := provider.Deploy(filePath, builder)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot deploy this app")
 	} else if err := formatter.Format(response); err != nil {
-		return errors.Wrap(err, "cannot format the response")
+		return nil, errors.Wrap(err, "cannot format the response")
 	}
 
-	return nil
+	summary := response.Summary()
+
+	return &summary, nil
+}
+
+// The response of custom services is unknown so only the artifact is rendered. Use the raw format to see the response.
+var customServiceTableBuilder = func(w table.Writer, v any) {
+	resp := v.(service.CustomServiceDeployResult)
+
+	w.AppendHeader(table.Row{
+		"Key", "Value",
+	})
+
+	appendArtifactRows(w, resp.Artifact)
 }
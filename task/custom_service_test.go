@@ -0,0 +1,37 @@
+package task
+
+import (
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jmatsu/splitter/service"
+	"testing"
+)
+
+func Test_customServiceTableBuilder(t *testing.T) {
+	cases := map[string]struct {
+		result service.CustomServiceDeployResult
+	}{
+		"zero": {
+			result: service.CustomServiceDeployResult{},
+		},
+		"regular": {
+			result: service.CustomServiceDeployResult{
+				Artifact: service.Artifact{
+					FileName: "app.apk",
+					Size:     1024,
+					SHA256:   "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+				},
+			},
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			w := table.NewWriter()
+
+			// no panic is ok
+			customServiceTableBuilder(w, c.result)
+		})
+	}
+}
@@ -9,27 +9,40 @@ import (
 	"strings"
 )
 
-func DeployToDeployGate(ctx context.Context, conf config.DeployGateConfig, filePath string, builder func(req *service.DeployGateDeployRequest) error) error {
+func DeployToDeployGate(ctx context.Context, conf config.DeployGateConfig, filePath string, builder func(req *service.DeployGateDeployRequest) error) (*service.DeploySummary, error) {
 	if err := conf.Validate(); err != nil {
-		return errors.Wrap(err, "the built config is invalid")
+		return nil, errors.Wrap(err, "the built config is invalid")
 	}
 
 	provider := service.NewDeployGateProvider(ctx, &conf)
 
+	if config.CurrentConfig().DryRun() {
+		if result, err := provider.DryRun(filePath, builder); err != nil {
+			return nil, errors.Wrap(err, "cannot build requests for this app")
+		} else {
+			return nil, formatDryRun(result)
+		}
+	}
+
 	formatter := NewFormatter()
 	formatter.TableBuilder = deployGateTableBuilder
 
-	if response, err := provider.Deploy(filePath, builder); err != nil {
-		return errors.Wrap(err, "cannot deploy this app")
+	response, err := provider.Deploy(filePath, builder)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot deploy this app")
 	} else if err := formatter.Format(response); err != nil {
-		return errors.Wrap(err, "cannot format the response")
+		return nil, errors.Wrap(err, "cannot format the response")
 	}
 
-	return nil
+	summary := response.Summary()
+
+	return &summary, nil
 }
 
 var deployGateTableBuilder = func(w table.Writer, v any) {
-	resp := v.(service.DeployGateDeployResult).Results
+	result := v.(service.DeployGateDeployResult)
+	resp := result.Results
 
 	w.AppendHeader(table.Row{
 		"Key", "Value",
@@ -89,4 +102,6 @@ var deployGateTableBuilder = func(w table.Writer, v any) {
 			{"Build SDK Version", resp.RawSdkVersion},
 		})
 	}
+
+	appendArtifactRows(w, result.Artifact)
 }
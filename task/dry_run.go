@@ -0,0 +1,84 @@
+package task
+
+import (
+	"fmt"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jmatsu/splitter/internal/config"
+	"github.com/jmatsu/splitter/service"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"strings"
+)
+
+func formatDryRun(result *service.DryRunResult) error {
+	result.Profile = config.CurrentConfig().Profile()
+
+	formatter := NewFormatter()
+	formatter.TableBuilder = dryRunTableBuilder
+
+	return formatter.Format(result)
+}
+
+var dryRunTableBuilder = func(w table.Writer, v any) {
+	result := v.(service.DryRunResult)
+
+	w.SetTitle("Dry-run: nothing has been sent")
+
+	w.AppendHeader(table.Row{
+		"Key", "Value",
+	})
+
+	if result.Profile != "" {
+		w.AppendRow(table.Row{
+			"Profile", result.Profile,
+		})
+		w.AppendSeparator()
+	}
+
+	for idx, request := range result.Requests {
+		if idx > 0 {
+			w.AppendSeparator()
+		}
+
+		w.AppendRows([]table.Row{
+			{fmt.Sprintf("Request %d", idx+1), fmt.Sprintf("%s %s", request.Method, request.URL)},
+		})
+
+		names := maps.Keys(request.Headers)
+		slices.Sort(names)
+
+		for _, name := range names {
+			w.AppendRow(table.Row{
+				fmt.Sprintf("Header %s", name), strings.Join(request.Headers[name], ", "),
+			})
+		}
+
+		for _, field := range request.Fields {
+			if field.IsFile {
+				w.AppendRow(table.Row{
+					fmt.Sprintf("Form %s", field.Name), fmt.Sprintf("@%s", field.Value),
+				})
+			} else {
+				w.AppendRow(table.Row{
+					fmt.Sprintf("Form %s", field.Name), field.Value,
+				})
+			}
+		}
+
+		if request.Body != "" {
+			w.AppendRow(table.Row{
+				"Body", request.Body,
+			})
+		}
+	}
+
+	if len(result.Requests) > 0 && len(result.Operations) > 0 {
+		w.AppendSeparator()
+	}
+
+	for idx, operation := range result.Operations {
+		w.AppendRow(table.Row{
+			fmt.Sprintf("Operation %d", idx+1), operation,
+		})
+	}
+}
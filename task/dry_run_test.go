@@ -0,0 +1,60 @@
+package task
+
+import (
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jmatsu/splitter/internal/net"
+	"github.com/jmatsu/splitter/service"
+	"testing"
+)
+
+func Test_dryRunTableBuilder(t *testing.T) {
+	cases := map[string]struct {
+		result service.DryRunResult
+	}{
+		"zero": {
+			result: service.DryRunResult{},
+		},
+		"profile": {
+			result: service.DryRunResult{
+				Profile:    "staging",
+				Operations: []string{"copied without overwriting: src -> dest"},
+			},
+		},
+		"requests and operations": {
+			result: service.DryRunResult{
+				Requests: []net.RequestRecord{
+					{
+						Method: "POST",
+						URL:    "https://example.com/path",
+						Headers: map[string][]string{
+							"Authorization": {"Bearer ****"},
+						},
+						Fields: []net.RecordedField{
+							{Name: "file", Value: "path/to/file", IsFile: true},
+							{Name: "message", Value: "hello"},
+						},
+					},
+					{
+						Method: "PATCH",
+						URL:    "https://example.com/path",
+						Body:   "{}",
+					},
+				},
+				Operations: []string{
+					"copied without overwriting: src -> dest",
+				},
+			},
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			w := table.NewWriter()
+
+			// no panic is ok
+			dryRunTableBuilder(w, c.result)
+		})
+	}
+}
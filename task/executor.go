@@ -2,11 +2,14 @@ package task
 
 import (
 	"context"
+	"fmt"
 	"github.com/jmatsu/splitter/internal/config"
 	"github.com/jmatsu/splitter/internal/exec"
 	"github.com/jmatsu/splitter/internal/logger"
 	"github.com/pkg/errors"
+	"io"
 	k8sExec "k8s.io/utils/exec"
+	"os"
 	"strings"
 )
 
@@ -14,6 +17,8 @@ import (
 type StepExecutor struct {
 	config      *config.ExecutionConfig
 	commandLine exec.CommandLine
+	dryRun      bool
+	output      io.Writer
 }
 
 func NewExecutor(ctx context.Context, sh k8sExec.Interface, conf *config.ExecutionConfig) *StepExecutor {
@@ -24,6 +29,8 @@ func NewExecutor(ctx context.Context, sh k8sExec.Interface, conf *config.Executi
 	return &StepExecutor{
 		config:      conf,
 		commandLine: exec.NewCommandLine(ctx, sh),
+		dryRun:      config.CurrentConfig().DryRun(),
+		output:      os.Stdout,
 	}
 }
 
@@ -31,7 +38,7 @@ func (e *StepExecutor) Execute(f func() error) error {
 	if e.config != nil && len(e.config.PreSteps) > 0 {
 		logger.Logger.Info().Msgf("Execute pre-steps... 0/%d", len(e.config.PreSteps))
 
-		if err := e.runSteps(e.config.PreSteps); err != nil {
+		if err := e.runSteps("pre-step", e.config.PreSteps); err != nil {
 			return errors.Wrap(err, "failed to execute pre-steps")
 		}
 	} else {
@@ -45,7 +52,7 @@ func (e *StepExecutor) Execute(f func() error) error {
 	if e.config != nil && len(e.config.PostSteps) > 0 {
 		logger.Logger.Info().Msgf("Execute post-steps... 0/%d", len(e.config.PostSteps))
 
-		if err := e.runSteps(e.config.PostSteps); err != nil {
+		if err := e.runSteps("post-step", e.config.PostSteps); err != nil {
 			return errors.Wrap(err, "failed to execute post-steps")
 		}
 	} else {
@@ -55,7 +62,12 @@ func (e *StepExecutor) Execute(f func() error) error {
 	return nil
 }
 
-func (e *StepExecutor) runSteps(steps [][]string) error {
+func (e *StepExecutor) runSteps(kind string, steps [][]string) error {
+	if e.dryRun {
+		e.listSteps(kind, steps)
+		return nil
+	}
+
 	for idx, args := range steps {
 		logger.Logger.Info().Msgf("Start executing steps... %d/%d", idx+1, len(steps))
 
@@ -76,3 +88,13 @@ func (e *StepExecutor) runSteps(steps [][]string) error {
 
 	return nil
 }
+
+// Print steps instead of running them in dry-run mode.
+func (e *StepExecutor) listSteps(kind string, steps [][]string) {
+	outputLock.Lock()
+	defer outputLock.Unlock()
+
+	for idx, args := range steps {
+		_, _ = fmt.Fprintf(e.output, "[dry-run] %s %d/%d: %s\n", kind, idx+1, len(steps), strings.Join(args, " "))
+	}
+}
@@ -0,0 +1,191 @@
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jmatsu/splitter/internal/logger"
+	"github.com/jmatsu/splitter/service"
+	"github.com/pkg/errors"
+	"sync"
+)
+
+type DeploymentStatus = string
+
+const (
+	DeploymentSucceeded DeploymentStatus = "succeeded"
+	DeploymentFailed    DeploymentStatus = "failed"
+	DeploymentSkipped   DeploymentStatus = "skipped"
+)
+
+// SkippedError tells that a deployment has been skipped on purpose. This is not a failure.
+type SkippedError struct {
+	Reason string
+}
+
+func (e *SkippedError) Error() string {
+	return fmt.Sprintf("skipped: %s", e.Reason)
+}
+
+// IsSkipped returns true if the error represents a skipped deployment.
+func IsSkipped(err error) bool {
+	var skipped *SkippedError
+	return errors.As(err, &skipped)
+}
+
+// DeploymentOutcome represents the result of one deployment in a fan-out execution.
+type DeploymentOutcome struct {
+	Name   string           `json:"name"`
+	Status DeploymentStatus `json:"status"`
+	Error  string           `json:"error,omitempty"`
+	Reason string           `json:"reason,omitempty"`
+
+	err error
+}
+
+// Err returns the original error of a failed deployment.
+func (o *DeploymentOutcome) Err() error {
+	return o.err
+}
+
+// FanOutSummary is a combined result of all deployments in a fan-out execution.
+type FanOutSummary struct {
+	Outcomes []DeploymentOutcome `json:"deployments"`
+}
+
+var _ service.DeployResult = &FanOutSummary{}
+
+func (s *FanOutSummary) RawJsonResponse() string {
+	if bytes, err := json.Marshal(s); err != nil {
+		panic(err)
+	} else {
+		return string(bytes)
+	}
+}
+
+func (s *FanOutSummary) ValueResponse() any {
+	return *s
+}
+
+// Failures returns the number of deployments that did not succeed.
+func (s *FanOutSummary) Failures() int {
+	return s.Count(DeploymentFailed)
+}
+
+// Count returns the number of deployments of the status.
+func (s *FanOutSummary) Count(status DeploymentStatus) int {
+	var n int
+
+	for _, o := range s.Outcomes {
+		if o.Status == status {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Err returns an error if at least one of deployments failed.
+func (s *FanOutSummary) Err() error {
+	if n := s.Failures(); n > 0 {
+		return errors.New(fmt.Sprintf("%d of %d deployments failed", n, len(s.Outcomes)))
+	}
+
+	return nil
+}
+
+// FanOut runs f for each name concurrently. concurrency bounds the number of deployments running at the same time.
+// The outcomes are ordered by the given names regardless of the completion order.
+func FanOut(names []string, concurrency int, f func(name string) error) *FanOutSummary {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	outcomes := make([]DeploymentOutcome, len(names))
+	semaphore := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+
+	for idx, name := range names {
+		idx, name := idx, name
+
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func() {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			outcome := DeploymentOutcome{
+				Name:   name,
+				Status: DeploymentSucceeded,
+			}
+
+			if err := f(name); IsSkipped(err) {
+				var skipped *SkippedError
+				errors.As(err, &skipped)
+
+				logger.Logger.Info().Msgf("%s deployment is skipped: %s", name, skipped.Reason)
+
+				outcome.Status = DeploymentSkipped
+				outcome.Reason = skipped.Reason
+			} else if err != nil {
+				logger.Logger.Error().Err(err).Msgf("%s deployment failed", name)
+
+				outcome.Status = DeploymentFailed
+				outcome.Error = err.Error()
+				outcome.err = err
+			} else {
+				logger.Logger.Info().Msgf("%s deployment succeeded", name)
+			}
+
+			outcomes[idx] = outcome
+		}()
+	}
+
+	wg.Wait()
+
+	return &FanOutSummary{
+		Outcomes: outcomes,
+	}
+}
+
+// FormatFanOutSummary renders the combined result in the current format style.
+func FormatFanOutSummary(summary *FanOutSummary) error {
+	formatter := NewFormatter()
+	formatter.TableBuilder = fanOutTableBuilder
+
+	return formatter.Format(summary)
+}
+
+var fanOutTableBuilder = func(w table.Writer, v any) {
+	summary := v.(FanOutSummary)
+
+	w.AppendHeader(table.Row{
+		"Deployment", "Status", "Detail",
+	})
+
+	for _, o := range summary.Outcomes {
+		detail := o.Error
+
+		if o.Status == DeploymentSkipped {
+			detail = o.Reason
+		}
+
+		w.AppendRow(table.Row{
+			o.Name, o.Status, detail,
+		})
+	}
+
+	footer := fmt.Sprintf("%d/%d succeeded", summary.Count(DeploymentSucceeded), len(summary.Outcomes))
+
+	if n := summary.Count(DeploymentSkipped); n > 0 {
+		footer = fmt.Sprintf("%s, %d skipped", footer, n)
+	}
+
+	w.AppendFooter(table.Row{
+		"", footer, "",
+	})
+}
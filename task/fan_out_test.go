@@ -0,0 +1,158 @@
+package task
+
+import (
+	"errors"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_FanOut(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		names       []string
+		failures    []string
+		skips       []string
+		concurrency int
+
+		expectedStatuses []DeploymentStatus
+		expectedErr      bool
+	}{
+		"all succeeded": {
+			names:       []string{"def1", "def2", "def3"},
+			concurrency: 2,
+
+			expectedStatuses: []DeploymentStatus{DeploymentSucceeded, DeploymentSucceeded, DeploymentSucceeded},
+			expectedErr:      false,
+		},
+		"partially failed": {
+			names:       []string{"def1", "def2", "def3"},
+			failures:    []string{"def2"},
+			concurrency: 3,
+
+			expectedStatuses: []DeploymentStatus{DeploymentSucceeded, DeploymentFailed, DeploymentSucceeded},
+			expectedErr:      true,
+		},
+		"partially skipped": {
+			names:       []string{"def1", "def2", "def3"},
+			skips:       []string{"def1"},
+			concurrency: 2,
+
+			expectedStatuses: []DeploymentStatus{DeploymentSkipped, DeploymentSucceeded, DeploymentSucceeded},
+			expectedErr:      false,
+		},
+		"all failed": {
+			names:       []string{"def1", "def2"},
+			failures:    []string{"def1", "def2"},
+			concurrency: 1,
+
+			expectedStatuses: []DeploymentStatus{DeploymentFailed, DeploymentFailed},
+			expectedErr:      true,
+		},
+		"non-positive concurrency": {
+			names:       []string{"def1"},
+			concurrency: 0,
+
+			expectedStatuses: []DeploymentStatus{DeploymentSucceeded},
+			expectedErr:      false,
+		},
+		"zero": {
+			expectedStatuses: []DeploymentStatus{},
+			expectedErr:      false,
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var running, peak int32
+
+			summary := FanOut(c.names, c.concurrency, func(name string) error {
+				n := atomic.AddInt32(&running, 1)
+				defer atomic.AddInt32(&running, -1)
+
+				for {
+					p := atomic.LoadInt32(&peak)
+
+					if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+						break
+					}
+				}
+
+				time.Sleep(10 * time.Millisecond)
+
+				for _, s := range c.skips {
+					if s == name {
+						return &SkippedError{Reason: "condition"}
+					}
+				}
+
+				for _, f := range c.failures {
+					if f == name {
+						return errors.New("failure")
+					}
+				}
+
+				return nil
+			})
+
+			statuses := []DeploymentStatus{}
+
+			for idx, o := range summary.Outcomes {
+				if o.Name != c.names[idx] {
+					t.Errorf("%s is expected at %d but %s", c.names[idx], idx, o.Name)
+				}
+
+				statuses = append(statuses, o.Status)
+			}
+
+			if !reflect.DeepEqual(c.expectedStatuses, statuses) {
+				t.Errorf("%v is expected but %v", c.expectedStatuses, statuses)
+			}
+
+			if (summary.Err() != nil) != c.expectedErr {
+				t.Errorf("err is expected to be %t but %v", c.expectedErr, summary.Err())
+			}
+
+			if limit := c.concurrency; limit > 0 && int(peak) > limit {
+				t.Errorf("%d deployments ran in parallel over the limit %d", peak, limit)
+			}
+		})
+	}
+}
+
+func Test_fanOutTableBuilder(t *testing.T) {
+	cases := map[string]struct {
+		summary FanOutSummary
+	}{
+		"zero": {
+			summary: FanOutSummary{},
+		},
+		"regular": {
+			summary: FanOutSummary{
+				Outcomes: []DeploymentOutcome{
+					{Name: "def1", Status: DeploymentSucceeded},
+					{Name: "def2", Status: DeploymentFailed, Error: "failure"},
+					{Name: "def3", Status: DeploymentSkipped, Reason: "condition"},
+				},
+			},
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			w := table.NewWriter()
+
+			// no panic is ok
+			fanOutTableBuilder(w, c.summary)
+		})
+	}
+}
@@ -9,23 +9,44 @@ import (
 	"github.com/pkg/errors"
 )
 
-func DeployToFirebaseAppDistribution(ctx context.Context, conf config.FirebaseAppDistributionConfig, filePath string, builder func(req *service.FirebaseAppDistributionDeployRequest) error) error {
+// LatestFirebaseAppDistributionSHA256 returns the SHA-256 of the binary of the latest release. An empty string is returned if it cannot be compared with a file of the size.
+func LatestFirebaseAppDistributionSHA256(ctx context.Context, conf config.FirebaseAppDistributionConfig, size int64) (string, error) {
 	if err := conf.Validate(); err != nil {
-		return errors.Wrap(err, "the built config is invalid")
+		return "", errors.Wrap(err, "the built config is invalid")
+	}
+
+	return service.NewFirebaseAppDistributionProvider(ctx, &conf).LatestReleaseSHA256(size)
+}
+
+func DeployToFirebaseAppDistribution(ctx context.Context, conf config.FirebaseAppDistributionConfig, filePath string, builder func(req *service.FirebaseAppDistributionDeployRequest) error) (*service.DeploySummary, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, errors.Wrap(err, "the built config is invalid")
 	}
 
 	provider := service.NewFirebaseAppDistributionProvider(ctx, &conf)
 
+	if config.CurrentConfig().DryRun() {
+		if result, err := provider.DryRun(filePath, builder); err != nil {
+			return nil, errors.Wrap(err, "cannot build requests for this app")
+		} else {
+			return nil, formatDryRun(result)
+		}
+	}
+
 	formatter := NewFormatter()
 	formatter.TableBuilder = firebaseAppDistributionTableBuilder
 
-	if response, err := provider.Deploy(filePath, builder); err != nil {
-		return errors.Wrap(err, "cannot deploy this app")
+	response, err := provider.Deploy(filePath, builder)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot deploy this app")
 	} else if err := formatter.Format(response); err != nil {
-		return errors.Wrap(err, "cannot format the response")
+		return nil, errors.Wrap(err, "cannot format the response")
 	}
 
-	return nil
+	summary := response.Summary()
+
+	return &summary, nil
 }
 
 var firebaseAppDistributionTableBuilder = func(w table.Writer, v any) {
@@ -89,4 +110,6 @@ var firebaseAppDistributionTableBuilder = func(w table.Writer, v any) {
 		{"App Version Code", release.BuildVersion},
 		{"App Version Name", release.DisplayVersion},
 	})
+
+	appendArtifactRows(w, resp.Artifact)
 }
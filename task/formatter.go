@@ -7,8 +7,12 @@ import (
 	"github.com/jmatsu/splitter/internal/logger"
 	"github.com/jmatsu/splitter/service"
 	"os"
+	"sync"
 )
 
+// outputLock prevents results of concurrent deployments from being interleaved.
+var outputLock sync.Mutex
+
 type TableBuilder = func(writer table.Writer, r any)
 
 type Formatter struct {
@@ -23,6 +27,9 @@ func NewFormatter() *Formatter {
 }
 
 func (f *Formatter) Format(r service.DeployResult) error {
+	outputLock.Lock()
+	defer outputLock.Unlock()
+
 	w := table.NewWriter()
 	w.SetOutputMirror(os.Stdout)
 	w.SetStyle(table.StyleDefault)
@@ -0,0 +1,81 @@
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jmatsu/splitter/internal/history"
+	"github.com/jmatsu/splitter/service"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"strings"
+	"time"
+)
+
+// HistoryResult is a list of deployment records to be rendered.
+type HistoryResult struct {
+	Records []history.Record `json:"records"`
+}
+
+var _ service.DeployResult = &HistoryResult{}
+
+func (r *HistoryResult) RawJsonResponse() string {
+	if bytes, err := json.Marshal(r); err != nil {
+		panic(err)
+	} else {
+		return string(bytes)
+	}
+}
+
+func (r *HistoryResult) ValueResponse() any {
+	return *r
+}
+
+// FormatHistory renders the records in the current format style.
+func FormatHistory(records []history.Record) error {
+	formatter := NewFormatter()
+	formatter.TableBuilder = historyTableBuilder
+
+	return formatter.Format(&HistoryResult{
+		Records: records,
+	})
+}
+
+var historyTableBuilder = func(w table.Writer, v any) {
+	result := v.(HistoryResult)
+
+	w.AppendHeader(table.Row{
+		"Started At", "Deployment", "Service", "Status", "File", "Version", "SHA-256", "URLs",
+	})
+
+	for _, r := range result.Records {
+		version := r.VersionName
+
+		if r.VersionCode != "" {
+			version = strings.TrimSpace(fmt.Sprintf("%s (%s)", version, r.VersionCode))
+		}
+
+		sha256 := r.SHA256
+
+		if len(sha256) > 12 {
+			sha256 = sha256[:12]
+		}
+
+		keys := maps.Keys(r.URLs)
+		slices.Sort(keys)
+
+		var urls []string
+
+		for _, key := range keys {
+			urls = append(urls, fmt.Sprintf("%s: %s", key, r.URLs[key]))
+		}
+
+		w.AppendRow(table.Row{
+			r.StartedAt.Local().Format(time.RFC3339), r.Deployment, r.Service, r.Status, r.FileName, version, sha256, strings.Join(urls, "\n"),
+		})
+	}
+
+	w.AppendFooter(table.Row{
+		"", "", "", "", "", "", "", fmt.Sprintf("%d records", len(result.Records)),
+	})
+}
@@ -0,0 +1,55 @@
+package task
+
+import (
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jmatsu/splitter/internal/history"
+	"testing"
+	"time"
+)
+
+func Test_historyTableBuilder(t *testing.T) {
+	cases := map[string]struct {
+		result HistoryResult
+	}{
+		"zero": {
+			result: HistoryResult{},
+		},
+		"regular": {
+			result: HistoryResult{
+				Records: []history.Record{
+					{
+						Deployment:  "dogfooding",
+						Service:     "deploygate",
+						Status:      history.Succeeded,
+						FileName:    "app.apk",
+						SHA256:      "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+						VersionName: "1.0.0",
+						VersionCode: "1",
+						URLs: map[string]string{
+							"download":     "https://example.com/app.apk",
+							"distribution": "https://example.com/distribution",
+						},
+						StartedAt: time.Now(),
+					},
+					{
+						Deployment: "local",
+						Service:    "local",
+						Status:     history.Failed,
+						Error:      "failure",
+					},
+				},
+			},
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			w := table.NewWriter()
+
+			// no panic is ok
+			historyTableBuilder(w, c.result)
+		})
+	}
+}
@@ -2,29 +2,43 @@ package task
 
 import (
 	"context"
+	"fmt"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/jmatsu/splitter/internal/config"
 	"github.com/jmatsu/splitter/service"
 	"github.com/pkg/errors"
+	"strings"
 )
 
-func DeployToLocal(ctx context.Context, conf config.LocalConfig, filePath string) error {
+func DeployToLocal(ctx context.Context, conf config.LocalConfig, filePath string, builder func(req *service.LocalDeployRequest) error) (*service.DeploySummary, error) {
 	if err := conf.Validate(); err != nil {
-		return errors.Wrap(err, "the built config is invalid")
+		return nil, errors.Wrap(err, "the built config is invalid")
 	}
 
 	provider := service.NewLocalProvider(ctx, &conf)
 
+	if config.CurrentConfig().DryRun() {
+		if result, err := provider.DryRun(filePath, builder); err != nil {
+			return nil, errors.Wrap(err, "cannot plan this deployment")
+		} else {
+			return nil, formatDryRun(result)
+		}
+	}
+
 	formatter := NewFormatter()
 	formatter.TableBuilder = localTableBuilder
 
-	if response, err := provider.Deploy(filePath); err != nil {
-		return errors.Wrap(err, "cannot deploy this app")
+	response, err := provider.Deploy(filePath, builder)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot deploy this app")
 	} else if err := formatter.Format(response); err != nil {
-		return errors.Wrap(err, "cannot format the response")
+		return nil, errors.Wrap(err, "cannot format the response")
 	}
 
-	return nil
+	summary := response.Summary()
+
+	return &summary, nil
 }
 
 var localTableBuilder = func(w table.Writer, v any) {
@@ -42,4 +56,139 @@ var localTableBuilder = func(w table.Writer, v any) {
 	w.AppendRows([]table.Row{
 		{"SideEffect", resp.SideEffect},
 	})
+
+	if len(resp.SidecarFilePaths) > 0 {
+		w.AppendRows([]table.Row{
+			{"Sidecar Paths", strings.Join(resp.SidecarFilePaths, "\n")},
+		})
+	}
+
+	if resp.Version != "" {
+		w.AppendSeparator()
+		w.AppendRows([]table.Row{
+			{"Versioning Property", ""},
+		})
+		w.AppendSeparator()
+		w.AppendRows([]table.Row{
+			{"Version", resp.Version},
+			{"Latest Path", resp.LatestFilePath},
+			{"Pruned Versions", strings.Join(resp.PrunedVersions, "\n")},
+		})
+	}
+
+	appendInstallPageRows(w, resp.InstallPage)
+	appendArtifactRows(w, resp.Artifact)
+}
+
+func appendInstallPageRows(w table.Writer, page *service.LocalInstallPage) {
+	if page == nil {
+		return
+	}
+
+	w.AppendSeparator()
+	w.AppendRows([]table.Row{
+		{"Install Page Property", ""},
+	})
+	w.AppendSeparator()
+	w.AppendRows([]table.Row{
+		{"Page URL", page.URL},
+		{"Index Path", page.IndexFilePath},
+		{"Manifest Path", page.ManifestFilePath},
+	})
+
+	for _, app := range page.Apps {
+		w.AppendRow(table.Row{fmt.Sprintf("Install URL (%s)", app.FileName), app.InstallURL})
+
+		if app.PlistFilePath != "" {
+			w.AppendRow(table.Row{fmt.Sprintf("Plist Path (%s)", app.FileName), app.PlistFilePath})
+		}
+	}
+}
+
+// RollbackLocal points latest of the versioned destination to the previous version.
+func RollbackLocal(ctx context.Context, conf config.LocalConfig) error {
+	if err := conf.Validate(); err != nil {
+		return errors.Wrap(err, "the built config is invalid")
+	}
+
+	provider := service.NewLocalProvider(ctx, &conf)
+
+	if config.CurrentConfig().DryRun() {
+		if result, err := provider.DryRunRollback(); err != nil {
+			return errors.Wrap(err, "cannot plan this rollback")
+		} else {
+			return formatDryRun(result)
+		}
+	}
+
+	formatter := NewFormatter()
+	formatter.TableBuilder = localRollbackTableBuilder
+
+	if response, err := provider.Rollback(); err != nil {
+		return errors.Wrap(err, "cannot roll back")
+	} else if err := formatter.Format(response); err != nil {
+		return errors.Wrap(err, "cannot format the response")
+	}
+
+	return nil
+}
+
+var localRollbackTableBuilder = func(w table.Writer, v any) {
+	resp := v.(service.LocalRollbackResult)
+
+	w.AppendHeader(table.Row{
+		"Key", "Value",
+	})
+
+	w.AppendRows([]table.Row{
+		{"Version", resp.Version},
+		{"Previous Version", resp.PreviousVersion},
+		{"Latest Paths", strings.Join(resp.LatestFilePaths, "\n")},
+	})
+
+	appendInstallPageRows(w, resp.InstallPage)
+}
+
+// PublishLocal points latest of the versioned destination to the version that the files have been stored in.
+func PublishLocal(ctx context.Context, conf config.LocalConfig, version string, fileNames []string) error {
+	if err := conf.Validate(); err != nil {
+		return errors.Wrap(err, "the built config is invalid")
+	}
+
+	provider := service.NewLocalProvider(ctx, &conf)
+
+	if config.CurrentConfig().DryRun() {
+		if result, err := provider.DryRunPublish(version, fileNames); err != nil {
+			return errors.Wrap(err, "cannot plan this publication")
+		} else {
+			return formatDryRun(result)
+		}
+	}
+
+	formatter := NewFormatter()
+	formatter.TableBuilder = localPublishTableBuilder
+
+	if response, err := provider.Publish(version); err != nil {
+		return errors.Wrap(err, "cannot publish the version")
+	} else if err := formatter.Format(response); err != nil {
+		return errors.Wrap(err, "cannot format the response")
+	}
+
+	return nil
+}
+
+var localPublishTableBuilder = func(w table.Writer, v any) {
+	resp := v.(service.LocalPublishResult)
+
+	w.AppendHeader(table.Row{
+		"Key", "Value",
+	})
+
+	w.AppendRows([]table.Row{
+		{"Version", resp.Version},
+		{"Latest Paths", strings.Join(resp.LatestFilePaths, "\n")},
+		{"Pruned Versions", strings.Join(resp.PrunedVersions, "\n")},
+	})
+
+	appendInstallPageRows(w, resp.InstallPage)
 }
@@ -19,6 +19,49 @@ func Test_localTableBuilder(t *testing.T) {
 					SourceFilePath:      "path/to/src",
 					DestinationFilePath: "path/to/dest",
 					SideEffect:          "side effect",
+					SidecarFilePaths:    []string{"path/to/dest.sha256", "path/to/dest.json"},
+				},
+				Artifact: service.Artifact{
+					FileName: "src",
+					Size:     1024,
+					SHA256:   "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+				},
+			},
+		},
+		"versioning": {
+			result: service.LocalDeployResult{
+				LocalMoveResponse: service.LocalMoveResponse{
+					SourceFilePath:      "path/to/src",
+					DestinationFilePath: "path/to/dest/versions/20221001-120000.000000000/src",
+					SideEffect:          "side effect",
+					Version:             "20221001-120000.000000000",
+					LatestFilePath:      "path/to/dest/latest",
+					PrunedVersions:      []string{"20220901-120000.000000000"},
+				},
+			},
+		},
+		"install page": {
+			result: service.LocalDeployResult{
+				LocalMoveResponse: service.LocalMoveResponse{
+					SourceFilePath:      "path/to/src.ipa",
+					DestinationFilePath: "path/to/dest/src.ipa",
+					SideEffect:          "side effect",
+					InstallPage: &service.LocalInstallPage{
+						URL:              "https://example.com/index.html",
+						IndexFilePath:    "path/to/dest/index.html",
+						ManifestFilePath: "path/to/dest/manifest.json",
+						Apps: []service.LocalInstallApp{
+							{
+								FileName:      "src.ipa",
+								InstallURL:    "itms-services://?action=download-manifest&url=https%3A%2F%2Fexample.com%2Fsrc.ipa.plist",
+								PlistFilePath: "path/to/dest/src.ipa.plist",
+							},
+							{
+								FileName:   "src.apk",
+								InstallURL: "https://example.com/src.apk",
+							},
+						},
+					},
 				},
 			},
 		},
@@ -35,3 +78,59 @@ func Test_localTableBuilder(t *testing.T) {
 		})
 	}
 }
+
+func Test_localRollbackTableBuilder(t *testing.T) {
+	cases := map[string]struct {
+		result service.LocalRollbackResult
+	}{
+		"zero": {
+			result: service.LocalRollbackResult{},
+		},
+		"regular": {
+			result: service.LocalRollbackResult{
+				Version:         "20220901-120000.000000000",
+				PreviousVersion: "20221001-120000.000000000",
+				LatestFilePaths: []string{"path/to/dest/latest.apk"},
+			},
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			w := table.NewWriter()
+
+			// no panic is ok
+			localRollbackTableBuilder(w, c.result)
+		})
+	}
+}
+
+func Test_localPublishTableBuilder(t *testing.T) {
+	cases := map[string]struct {
+		result service.LocalPublishResult
+	}{
+		"zero": {
+			result: service.LocalPublishResult{},
+		},
+		"regular": {
+			result: service.LocalPublishResult{
+				Version:         "20221001-120000.000000000",
+				LatestFilePaths: []string{"path/to/dest/latest-app-arm64.apk", "path/to/dest/latest-app-x86.apk"},
+				PrunedVersions:  []string{"20220901-120000.000000000"},
+			},
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			w := table.NewWriter()
+
+			// no panic is ok
+			localPublishTableBuilder(w, c.result)
+		})
+	}
+}
@@ -8,25 +8,43 @@ import (
 	"github.com/pkg/errors"
 )
 
-func DeployToTestFlight(ctx context.Context, conf config.TestFlightConfig, filePath string, builder func(req *service.TestFlightDeployRequest) error) error {
+func DeployToTestFlight(ctx context.Context, conf config.TestFlightConfig, filePath string, builder func(req *service.TestFlightDeployRequest) error) (*service.DeploySummary, error) {
 	if err := conf.Validate(); err != nil {
-		return errors.Wrap(err, "the built config is invalid")
+		return nil, errors.Wrap(err, "the built config is invalid")
 	}
 
 	provider := service.NewTestFlightProvider(ctx, &conf)
 
+	if config.CurrentConfig().DryRun() {
+		if result, err := provider.DryRun(filePath, builder); err != nil {
+			return nil, errors.Wrap(err, "cannot build the command for this app")
+		} else {
+			return nil, formatDryRun(result)
+		}
+	}
+
 	formatter := NewFormatter()
 	formatter.TableBuilder = testFlightTableBuilder
 
-	if response, err := provider.Deploy(filePath, builder); err != nil {
-		return errors.Wrap(err, "cannot deploy this app")
+	response, err := provider.Deploy(filePath, builder)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot deploy this app")
 	} else if err := formatter.Format(response); err != nil {
-		return errors.Wrap(err, "cannot format the response")
+		return nil, errors.Wrap(err, "cannot format the response")
 	}
 
-	return nil
+	summary := response.Summary()
+
+	return &summary, nil
 }
 
 var testFlightTableBuilder = func(w table.Writer, v any) {
-	_ = v.(service.TestFlightDeployResult)
+	resp := v.(service.TestFlightDeployResult)
+
+	w.AppendHeader(table.Row{
+		"Key", "Value",
+	})
+
+	appendArtifactRows(w, resp.Artifact)
 }
@@ -16,6 +16,11 @@ func Test_testFlightTableBuilder(t *testing.T) {
 		"regular": {
 			result: service.TestFlightDeployResult{
 				TestFlightUploadAppResponse: service.TestFlightUploadAppResponse{},
+				Artifact: service.Artifact{
+					FileName: "app.ipa",
+					Size:     1024,
+					SHA256:   "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+				},
 			},
 		},
 	}
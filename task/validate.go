@@ -0,0 +1,80 @@
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jmatsu/splitter/internal/config"
+	"github.com/jmatsu/splitter/service"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+// ValidationResult is a list of problems of the config file to be rendered.
+type ValidationResult struct {
+	Valid    bool             `json:"valid"`
+	Profile  string           `json:"profile,omitempty"`
+	Problems []config.Problem `json:"problems"`
+}
+
+var _ service.DeployResult = &ValidationResult{}
+
+func (r *ValidationResult) RawJsonResponse() string {
+	if bytes, err := json.Marshal(r); err != nil {
+		panic(err)
+	} else {
+		return string(bytes)
+	}
+}
+
+func (r *ValidationResult) ValueResponse() any {
+	return *r
+}
+
+// FormatValidation renders the problems in the current format style. This returns an error if any problem is found.
+func FormatValidation(problems config.Problems) error {
+	formatter := NewFormatter()
+	formatter.TableBuilder = validationTableBuilder
+
+	if err := formatter.Format(&ValidationResult{
+		Valid:    len(problems) == 0,
+		Profile:  config.CurrentConfig().Profile(),
+		Problems: problems,
+	}); err != nil {
+		return err
+	}
+
+	if num := len(problems); num > 0 {
+		return errors.New(fmt.Sprintf("%d problems are found in the config file", num))
+	}
+
+	return nil
+}
+
+var validationTableBuilder = func(w table.Writer, v any) {
+	result := v.(ValidationResult)
+
+	if result.Profile != "" {
+		w.SetTitle(fmt.Sprintf("Profile: %s", result.Profile))
+	}
+
+	w.AppendHeader(table.Row{
+		"Section", "Name", "Problem", "Unset Environment Variables",
+	})
+
+	for _, p := range result.Problems {
+		w.AppendRow(table.Row{
+			p.Section, p.Name, p.Message, strings.Join(p.UnsetEnvVars, "\n"),
+		})
+	}
+
+	status := "valid"
+
+	if !result.Valid {
+		status = fmt.Sprintf("%d problems", len(result.Problems))
+	}
+
+	w.AppendFooter(table.Row{
+		"", "", "", status,
+	})
+}
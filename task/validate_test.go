@@ -0,0 +1,49 @@
+package task
+
+import (
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jmatsu/splitter/internal/config"
+	"testing"
+)
+
+func Test_validationTableBuilder(t *testing.T) {
+	cases := map[string]struct {
+		result ValidationResult
+	}{
+		"zero": {
+			result: ValidationResult{},
+		},
+		"valid": {
+			result: ValidationResult{
+				Valid: true,
+			},
+		},
+		"regular": {
+			result: ValidationResult{
+				Profile: "staging",
+				Problems: []config.Problem{
+					{
+						Section:      "deployments",
+						Name:         "dogfooding",
+						Message:      "1 keys lacked or their values are empty: api-token",
+						UnsetEnvVars: []string{"DEPLOYGATE_API_TOKEN"},
+					},
+					{
+						Message: "unknown is unknown format style",
+					},
+				},
+			},
+		},
+	}
+
+	for name, c := range cases {
+		name, c := name, c
+
+		t.Run(name, func(t *testing.T) {
+			w := table.NewWriter()
+
+			// no panic is ok
+			validationTableBuilder(w, c.result)
+		})
+	}
+}